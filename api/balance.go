@@ -0,0 +1,211 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/gin-gonic/gin"
+)
+
+type balanceResponse struct {
+	Currency string     `json:"currency"`
+	Balance  util.Money `json:"balance"`
+	// LedgerBalance is the sum of the entries of the wallet, it must always be equal to the balance
+	LedgerBalance util.Money `json:"ledger_balance"`
+	Reconciled    bool       `json:"reconciled"`
+}
+
+func newBalanceResponse(row db.ReconcileWalletsRow) balanceResponse {
+	return balanceResponse{
+		Currency:      row.Currency,
+		Balance:       row.Balance,
+		LedgerBalance: util.Money(row.LedgerBalance),
+		Reconciled:    int64(row.Balance) == row.LedgerBalance,
+	}
+}
+
+type entryResponse struct {
+	Uuid         int64      `json:"uuid"`
+	Currency     string     `json:"currency"`
+	Amount       util.Money `json:"amount"`
+	Kind         string     `json:"kind"`
+	OrderUuid    int64      `json:"order_uuid,omitempty"`
+	TransferUuid int64      `json:"transfer_uuid,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func newEntryResponse(entry db.Entry) entryResponse {
+	return entryResponse{
+		Uuid:         entry.Uuid,
+		Currency:     entry.Currency,
+		Amount:       entry.Amount,
+		Kind:         entry.Kind,
+		OrderUuid:    entry.OrderUuid.Int64,
+		TransferUuid: entry.TransferUuid.Int64,
+		CreatedAt:    entry.CreatedAt,
+	}
+}
+
+type walletEntryResponse struct {
+	Wallet walletResponse `json:"wallet"`
+	Entry  entryResponse  `json:"entry"`
+}
+
+type getBalanceRequest struct {
+	UserUuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getBalance returns the balance of every wallet of the user reconciled against the ledger
+func (server *Server) getBalance(ctx *gin.Context) {
+	var req getBalanceRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, valid := server.validUser(ctx, req.UserUuid); !valid {
+		return
+	}
+
+	rows, err := server.store.ReconcileWallets(ctx, req.UserUuid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := []balanceResponse{}
+	for _, row := range rows {
+		rsp = append(rsp, newBalanceResponse(row))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type listEntryRequestUri struct {
+	UserUuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listEntryRequestQuery struct {
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5"`
+	Currency string `form:"currency" binding:"omitempty,len=3"`
+}
+
+// listEntry returns the ledger of the user, the newest entries come first
+func (server *Server) listEntry(ctx *gin.Context) {
+	var reqUri listEntryRequestUri
+	var reqQuery listEntryRequestQuery
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindQuery(&reqQuery); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, valid := server.validUser(ctx, reqUri.UserUuid); !valid {
+		return
+	}
+
+	entries, err := server.store.ListEntries(ctx, db.ListEntriesParams{
+		UserUuid: reqUri.UserUuid,
+		Currency: sql.NullString{String: reqQuery.Currency, Valid: reqQuery.Currency != ""},
+		Limit:    reqQuery.PageSize,
+		Offset:   (reqQuery.PageID - 1) * reqQuery.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := []entryResponse{}
+	for _, entry := range entries {
+		rsp = append(rsp, newEntryResponse(entry))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type changeBalanceRequestUri struct {
+	UserUuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+type changeBalanceRequestJson struct {
+	Currency string     `json:"currency" binding:"required,len=3"`
+	Amount   util.Money `json:"amount" binding:"required,gt=0"`
+}
+
+// topUpBalance adds money to the wallet of the currency. There is no payment step,
+// so only the admins can top up a balance, e.g. after the money was received some other way
+func (server *Server) topUpBalance(ctx *gin.Context) {
+	var reqUri changeBalanceRequestUri
+	var reqJson changeBalanceRequestJson
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.TopUpTx(ctx, db.TopUpTxParams{
+		UserUuid: reqUri.UserUuid,
+		Currency: reqJson.Currency,
+		Amount:   reqJson.Amount,
+	})
+	if err != nil {
+		ledgerError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, walletEntryResponse{
+		Wallet: newWalletResponse(result.Wallet),
+		Entry:  newEntryResponse(result.Entry),
+	})
+}
+
+// withdrawBalance takes money from the wallet of the currency
+func (server *Server) withdrawBalance(ctx *gin.Context) {
+	var reqUri changeBalanceRequestUri
+	var reqJson changeBalanceRequestJson
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, valid := server.validUser(ctx, reqUri.UserUuid); !valid {
+		return
+	}
+
+	result, err := server.store.WithdrawTx(ctx, db.WithdrawTxParams{
+		UserUuid: reqUri.UserUuid,
+		Currency: reqJson.Currency,
+		Amount:   reqJson.Amount,
+	})
+	if err != nil {
+		ledgerError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, walletEntryResponse{
+		Wallet: newWalletResponse(result.Wallet),
+		Entry:  newEntryResponse(result.Entry),
+	})
+}
+
+// ledgerError responds with the status code matching an error of a change of a balance
+func ledgerError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, db.ErrInvalidAmount), errors.Is(err, db.ErrNotEnoughMoney),
+		errors.Is(err, db.ErrTransferToSelf), errors.Is(err, util.ErrUnsupportedCurrency):
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
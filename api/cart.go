@@ -0,0 +1,197 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+type cartItemResponse struct {
+	ProductUuid int64 `json:"product_uuid"`
+	Quantity    int32 `json:"quantity"`
+}
+
+func newCartItemResponse(item db.CartItem) cartItemResponse {
+	return cartItemResponse{
+		ProductUuid: item.ProductUuid,
+		Quantity:    item.Quantity,
+	}
+}
+
+type cartResponse struct {
+	Uuid  int64              `json:"cart_uuid"`
+	Items []cartItemResponse `json:"items"`
+}
+
+func (server *Server) getCart(ctx *gin.Context) {
+	cart, valid := server.authCart(ctx)
+	if !valid {
+		return
+	}
+	items, err := server.store.ListCartItems(ctx, cart.Uuid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := cartResponse{
+		Uuid:  cart.Uuid,
+		Items: []cartItemResponse{},
+	}
+	for _, item := range items {
+		rsp.Items = append(rsp.Items, newCartItemResponse(item))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type addCartItemRequest struct {
+	ProductUuid int64 `json:"product_uuid" binding:"required,min=1"`
+	Quantity    int32 `json:"quantity" binding:"required,min=1"`
+}
+
+func (server *Server) addCartItem(ctx *gin.Context) {
+	var req addCartItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if !server.validProduct(ctx, req.ProductUuid) {
+		return
+	}
+	cart, valid := server.authCart(ctx)
+	if !valid {
+		return
+	}
+	item, err := server.store.AddCartItem(ctx, db.AddCartItemParams{
+		CartUuid:    cart.Uuid,
+		ProductUuid: req.ProductUuid,
+		Quantity:    req.Quantity,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, newCartItemResponse(item))
+}
+
+type cartItemRequestUri struct {
+	ProductUuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+type updateCartItemRequestJson struct {
+	Quantity int32 `json:"quantity" binding:"required,min=1"`
+}
+
+func (server *Server) updateCartItem(ctx *gin.Context) {
+	var reqUri cartItemRequestUri
+	var reqJson updateCartItemRequestJson
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	cart, valid := server.authCart(ctx)
+	if !valid {
+		return
+	}
+	item, err := server.store.UpdateCartItem(ctx, db.UpdateCartItemParams{
+		CartUuid:    cart.Uuid,
+		ProductUuid: reqUri.ProductUuid,
+		Quantity:    reqJson.Quantity,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newCartItemResponse(item))
+}
+
+func (server *Server) deleteCartItem(ctx *gin.Context) {
+	var req cartItemRequestUri
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	cart, valid := server.authCart(ctx)
+	if !valid {
+		return
+	}
+	r, err := server.store.DeleteCartItem(ctx, db.DeleteCartItemParams{
+		CartUuid:    cart.Uuid,
+		ProductUuid: req.ProductUuid,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if r == 0 {
+		ctx.JSON(http.StatusNotFound, notFoundResponse("cart item"))
+		return
+	}
+	ctx.JSON(http.StatusOK, successDeleteResponse())
+}
+
+type checkoutCartRequest struct {
+	// Currency of the wallet to pay with, a wallet with enough money is chosen when it is empty
+	Currency string `form:"currency" binding:"omitempty,len=3"`
+}
+
+// newCheckoutResponse returns the order with the products bought and the prices paid for them
+func newCheckoutResponse(result db.CheckoutTxResult) orderResponse {
+	rsp := newOrderResponse(result.Order)
+	rsp.Products = make([]orderLineResponse, len(result.OrderProducts))
+	for i, line := range result.OrderProducts {
+		rsp.Products[i] = orderLineResponse{
+			ProductUuid: line.ProductUuid,
+			Description: result.Products[i].Description,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			Currency:    line.Currency,
+		}
+	}
+	return rsp
+}
+
+// checkoutCart buys everything in the cart of the authenticated user as a single order
+func (server *Server) checkoutCart(ctx *gin.Context) {
+	var req checkoutCartRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	user, valid := server.authUser(ctx)
+	if !valid {
+		return
+	}
+	result, err := server.store.CheckoutTx(ctx, db.CheckoutTxParams{
+		UserUuid: user.Uuid,
+		Currency: req.Currency,
+	})
+	if err != nil {
+		purchaseError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, newCheckoutResponse(result))
+}
+
+// authCart returns the cart of the authenticated user, creating it if the user doesn't have one yet
+func (server *Server) authCart(ctx *gin.Context) (db.Cart, bool) {
+	user, valid := server.authUser(ctx)
+	if !valid {
+		return db.Cart{}, false
+	}
+	cart, err := server.store.GetOrCreateCart(ctx, user.Uuid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return cart, false
+	}
+	return cart, true
+}
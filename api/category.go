@@ -0,0 +1,155 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+type categoryResponse struct {
+	Uuid      int64     `json:"uuid"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newCategoryResponse(category db.Category) categoryResponse {
+	return categoryResponse{
+		Uuid:      category.Uuid,
+		Name:      category.Name,
+		CreatedAt: category.CreatedAt,
+	}
+}
+
+// categoryError tells the client the name is taken, the category names are unique
+func categoryError(ctx *gin.Context, err error) {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			err := errors.New("category with this name already exists")
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+	}
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
+
+type createCategoryRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func (server *Server) createCategory(ctx *gin.Context) {
+	var req createCategoryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	category, err := server.store.CreateCategory(ctx, req.Name)
+	if err != nil {
+		categoryError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, newCategoryResponse(category))
+}
+
+type getCategoryRequest struct {
+	Uuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getCategory(ctx *gin.Context) {
+	var req getCategoryRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	category, err := server.store.GetCategory(ctx, req.Uuid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, notFoundResponse("Category"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newCategoryResponse(category))
+}
+
+// listCategory returns all the categories by name, there are few of them so they aren't paged
+func (server *Server) listCategory(ctx *gin.Context) {
+	categories, err := server.store.ListCategories(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := []categoryResponse{}
+	for _, category := range categories {
+		rsp = append(rsp, newCategoryResponse(category))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type updateCategoryRequestUri struct {
+	Uuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+type updateCategoryRequestJson struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func (server *Server) updateCategory(ctx *gin.Context) {
+	var reqUri updateCategoryRequestUri
+	var reqJson updateCategoryRequestJson
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	category, err := server.store.UpdateCategory(ctx, db.UpdateCategoryParams{
+		Uuid: reqUri.Uuid,
+		Name: reqJson.Name,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, notFoundResponse("Category"))
+			return
+		}
+		categoryError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, newCategoryResponse(category))
+}
+
+type deleteCategoryRequest struct {
+	Uuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+// deleteCategory deletes the category, its products are left without a category
+func (server *Server) deleteCategory(ctx *gin.Context) {
+	var req deleteCategoryRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	r, err := server.store.DeleteCategory(ctx, req.Uuid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if r == 0 {
+		ctx.JSON(http.StatusNotFound, notFoundResponse("Category"))
+		return
+	}
+	ctx.JSON(http.StatusOK, successDeleteResponse())
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/alekseiapa/apple_store/util"
+)
+
+// errInvalidCursor is returned when a cursor wasn't issued by the server
+var errInvalidCursor = errors.New("invalid cursor")
+
+// errCursorWithPageID is returned when a list is asked for both the offset and the keyset page
+var errCursorWithPageID = errors.New("page_id can't be used together with cursor")
+
+// pageCursor points to the last item of a page, the next page starts right after it.
+// A backward cursor points to the first item of a page, the previous page ends right before it.
+// The clients get it as an opaque string they send back to get the next page
+type pageCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	Uuid      int64     `json:"uuid"`
+	Backward  bool      `json:"backward,omitempty"`
+	// the sort of a sorted list and the sort key of the item, so a page can't continue another sort
+	Sort  string     `json:"sort,omitempty"`
+	Price util.Money `json:"price,omitempty"`
+	Name  string     `json:"name,omitempty"`
+}
+
+func (cursor pageCursor) encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	var cursor pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Uuid < 1 {
+		return cursor, errInvalidCursor
+	}
+	return cursor, nil
+}
+
+// listPage holds the cursors of a keyset paginated list, the total is only counted when asked for
+type listPage struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// newListPage builds the cursors around the uuids of a page given in the list order.
+// The more flag tells if the page was full and another one follows in the direction of the cursor
+func newListPage(cursor pageCursor, uuids []int64, more bool) listPage {
+	var page listPage
+	if len(uuids) == 0 {
+		return page
+	}
+	first, last := uuids[0], uuids[len(uuids)-1]
+	if cursor.Backward {
+		page.NextCursor = pageCursor{Uuid: last}.encode()
+		if more {
+			page.PrevCursor = pageCursor{Uuid: first, Backward: true}.encode()
+		}
+		return page
+	}
+	if more {
+		page.NextCursor = pageCursor{Uuid: last}.encode()
+	}
+	if cursor.Uuid != 0 {
+		page.PrevCursor = pageCursor{Uuid: first, Backward: true}.encode()
+	}
+	return page
+}
+
+// listCursor decodes the cursor of a keyset paginated list, the empty cursor starts from the first item
+func listCursor(s string) (pageCursor, error) {
+	if s == "" {
+		return pageCursor{}, nil
+	}
+	return decodeCursor(s)
+}
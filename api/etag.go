@@ -0,0 +1,60 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	etagHeaderKey    = "ETag"
+	ifMatchHeaderKey = "If-Match"
+)
+
+var (
+	errVersionMismatch = errors.New("the resource was changed since it was read")
+	errIfMatchRequired = errors.New("the If-Match header is required, send the ETag of the resource or * to change it whatever its version is")
+)
+
+// setETag tells the client the version of the resource, the client sends it back in If-Match to change the resource
+func setETag(ctx *gin.Context, version int32) {
+	ctx.Header(etagHeaderKey, fmt.Sprintf(`"%d"`, version))
+}
+
+// ifMatchVersion reads the version from the If-Match header, which is required so a change can't overwrite
+// another one by mistake. With * there is no version, so the resource is changed whatever its version is.
+// A tag that isn't one of our versions can't match
+func ifMatchVersion(ctx *gin.Context) (sql.NullInt32, bool) {
+	tag := strings.TrimSpace(ctx.GetHeader(ifMatchHeaderKey))
+	if tag == "" {
+		ctx.JSON(http.StatusPreconditionRequired, errorResponse(errIfMatchRequired))
+		return sql.NullInt32{}, false
+	}
+	if tag == "*" {
+		return sql.NullInt32{}, true
+	}
+	if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		ctx.JSON(http.StatusPreconditionFailed, errorResponse(errVersionMismatch))
+		return sql.NullInt32{}, false
+	}
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusPreconditionFailed, errorResponse(errVersionMismatch))
+		return sql.NullInt32{}, false
+	}
+	return sql.NullInt32{Int32: int32(version), Valid: true}, true
+}
+
+// matchVersion checks the version of the resource that was read against the If-Match version
+func matchVersion(ctx *gin.Context, expected sql.NullInt32, version int32) bool {
+	if expected.Valid && expected.Int32 != version {
+		ctx.JSON(http.StatusPreconditionFailed, errorResponse(errVersionMismatch))
+		return false
+	}
+	return true
+}
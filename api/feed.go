@@ -0,0 +1,118 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+type feedProductResponse struct {
+	ProductUuid int64  `json:"product_uuid"`
+	Description string `json:"description"`
+	Quantity    int32  `json:"quantity"`
+}
+
+type feedItemResponse struct {
+	OrderUuid int64                 `json:"order_uuid"`
+	UserUuid  int64                 `json:"user_uuid"`
+	Username  string                `json:"username"`
+	FullName  string                `json:"full_name"`
+	CreatedAt time.Time             `json:"created_at"`
+	Products  []feedProductResponse `json:"products"`
+}
+
+type feedResponse struct {
+	Items []feedItemResponse `json:"items"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type getFeedRequest struct {
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=50"`
+	Cursor   string `form:"cursor"`
+}
+
+// getFeed returns the purchases of the users the authenticated user follows, newest first.
+// The purchases of users who made them private, or only visible to the users they follow back, are left out
+func (server *Server) getFeed(ctx *gin.Context) {
+	var req getFeedRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	user, valid := server.authUser(ctx)
+	if !valid {
+		return
+	}
+
+	// one more order tells if there is another page
+	arg := db.ListFeedParams{
+		FollowerUuid: user.Uuid,
+		Limit:        req.PageSize + 1,
+	}
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		arg.BeforeCreatedAt = sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+		arg.BeforeUuid = sql.NullInt64{Int64: cursor.Uuid, Valid: true}
+	}
+	orders, err := server.store.ListFeed(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	more := len(orders) > int(req.PageSize)
+	if more {
+		orders = orders[:req.PageSize]
+	}
+
+	rsp := feedResponse{Items: []feedItemResponse{}}
+	if len(orders) == 0 {
+		ctx.JSON(http.StatusOK, rsp)
+		return
+	}
+	orderUuids := make([]int64, len(orders))
+	for i, order := range orders {
+		orderUuids[i] = order.Uuid
+	}
+	// the products of the whole page are read at once
+	lines, err := server.store.ListFeedLines(ctx, orderUuids)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	products := make(map[int64][]feedProductResponse)
+	for _, line := range lines {
+		products[line.OrderUuid] = append(products[line.OrderUuid], feedProductResponse{
+			ProductUuid: line.ProductUuid,
+			Description: line.ProductDescription,
+			Quantity:    line.Quantity,
+		})
+	}
+
+	for _, order := range orders {
+		item := feedItemResponse{
+			OrderUuid: order.Uuid,
+			UserUuid:  order.UserUuid,
+			Username:  order.Username,
+			FullName:  order.FullName,
+			CreatedAt: order.CreatedAt,
+			Products:  products[order.Uuid],
+		}
+		if item.Products == nil {
+			item.Products = []feedProductResponse{}
+		}
+		rsp.Items = append(rsp.Items, item)
+	}
+	if more {
+		last := orders[len(orders)-1]
+		rsp.NextCursor = pageCursor{CreatedAt: last.CreatedAt, Uuid: last.Uuid}.encode()
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/alekseiapa/apple_store/db/mock"
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFeedAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	followee, _ := randomUser(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// one more than the page size, so the first page isn't the last one
+	orders := make([]db.ListFeedRow, 6)
+	for i := range orders {
+		orders[i] = db.ListFeedRow{
+			Uuid:      int64(100 - i),
+			UserUuid:  followee.Uuid,
+			CreatedAt: now.Add(-time.Duration(i) * time.Minute),
+			Username:  followee.Username,
+			FullName:  followee.FullName,
+		}
+	}
+	lines := []db.ListFeedLinesRow{
+		{OrderUuid: orders[0].Uuid, ProductUuid: 1, ProductDescription: "iPhone", Quantity: 1},
+		{OrderUuid: orders[0].Uuid, ProductUuid: 2, ProductDescription: "AirPods", Quantity: 2},
+	}
+	cursor := pageCursor{CreatedAt: orders[4].CreatedAt, Uuid: orders[4].Uuid}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "FirstPage",
+			query: "page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListFeedParams{
+					FollowerUuid: user.Uuid,
+					Limit:        6,
+				}
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListFeed(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(orders, nil)
+				store.EXPECT().
+					ListFeedLines(gomock.Any(), gomock.Eq([]int64{100, 99, 98, 97, 96})).
+					Times(1).
+					Return(lines, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got feedResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got.Items, 5)
+				require.Equal(t, orders[0].Uuid, got.Items[0].OrderUuid)
+				require.Equal(t, followee.Username, got.Items[0].Username)
+				require.Len(t, got.Items[0].Products, 2)
+				require.Equal(t, "AirPods", got.Items[0].Products[1].Description)
+				require.Empty(t, got.Items[1].Products)
+
+				// there is one more order, so there is another page
+				next, err := decodeCursor(got.NextCursor)
+				require.NoError(t, err)
+				require.Equal(t, cursor.Uuid, next.Uuid)
+				require.True(t, cursor.CreatedAt.Equal(next.CreatedAt))
+			},
+		},
+		{
+			name:  "LastPage",
+			query: "page_size=5&cursor=" + url.QueryEscape(cursor.encode()),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListFeedParams{
+					FollowerUuid:    user.Uuid,
+					BeforeCreatedAt: sql.NullTime{Time: cursor.CreatedAt, Valid: true},
+					BeforeUuid:      sql.NullInt64{Int64: cursor.Uuid, Valid: true},
+					Limit:           6,
+				}
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListFeed(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(orders[:1], nil)
+				store.EXPECT().
+					ListFeedLines(gomock.Any(), gomock.Eq([]int64{100})).
+					Times(1).
+					Return(lines, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got feedResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got.Items, 1)
+				require.Empty(t, got.NextCursor)
+			},
+		},
+		{
+			name:  "ExactlyFullLastPage",
+			query: "page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListFeed(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(orders[:5], nil)
+				store.EXPECT().
+					ListFeedLines(gomock.Any(), gomock.Eq([]int64{100, 99, 98, 97, 96})).
+					Times(1).
+					Return(lines, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got feedResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got.Items, 5)
+				require.Empty(t, got.NextCursor)
+			},
+		},
+		{
+			name:  "Empty",
+			query: "page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListFeed(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListFeedRow{}, nil)
+				store.EXPECT().
+					ListFeedLines(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"items": []}`, recorder.Body.String())
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: "page_size=5&cursor=garbage",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListFeed(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidPageSize",
+			query: "page_size=100",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListFeed(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListFeed(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListFeedRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/feed?%s", tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+type followResponse struct {
+	FollowerUuid int64     `json:"follower_uuid"`
+	FolloweeUuid int64     `json:"followee_uuid"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// followUserResponse is the public part of a user shown in the lists of followers
+type followUserResponse struct {
+	Uuid       int64     `json:"uuid"`
+	Username   string    `json:"username"`
+	FullName   string    `json:"full_name"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type followRequest struct {
+	UserUuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+// followUser makes the authenticated user follow the user
+func (server *Server) followUser(ctx *gin.Context) {
+	var req followRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	user, valid := server.authUser(ctx)
+	if !valid {
+		return
+	}
+	if user.Uuid == req.UserUuid {
+		err := errors.New("can't follow yourself")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	follow, err := server.store.CreateUserToUser(ctx, db.CreateUserToUserParams{
+		FirstUserUuid:  user.Uuid,
+		SecondUserUuid: req.UserUuid,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				err := errors.New("you already follow this user")
+				ctx.JSON(http.StatusConflict, errorResponse(err))
+				return
+			case "foreign_key_violation":
+				ctx.JSON(http.StatusNotFound, notFoundResponse("User"))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, followResponse{
+		FollowerUuid: follow.FirstUserUuid,
+		FolloweeUuid: follow.SecondUserUuid,
+		CreatedAt:    follow.CreatedAt,
+	})
+}
+
+// unfollowUser makes the authenticated user stop following the user
+func (server *Server) unfollowUser(ctx *gin.Context) {
+	var req followRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	user, valid := server.authUser(ctx)
+	if !valid {
+		return
+	}
+
+	rows, err := server.store.DeleteUserToUser(ctx, db.DeleteUserToUserParams{
+		FirstUserUuid:  user.Uuid,
+		SecondUserUuid: req.UserUuid,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if rows == 0 {
+		ctx.JSON(http.StatusNotFound, notFoundResponse("Follow"))
+		return
+	}
+	ctx.JSON(http.StatusOK, successDeleteResponse())
+}
+
+type listFollowRequestUri struct {
+	UserUuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listFollowRequestQuery struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5"`
+}
+
+// bindListFollow binds the request of a list of follows and checks that the user exists
+func (server *Server) bindListFollow(ctx *gin.Context) (listFollowRequestUri, listFollowRequestQuery, bool) {
+	var reqUri listFollowRequestUri
+	var reqQuery listFollowRequestQuery
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return reqUri, reqQuery, false
+	}
+	if err := ctx.ShouldBindQuery(&reqQuery); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return reqUri, reqQuery, false
+	}
+	if _, err := server.store.GetUser(ctx, reqUri.UserUuid); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, notFoundResponse("User"))
+			return reqUri, reqQuery, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return reqUri, reqQuery, false
+	}
+	return reqUri, reqQuery, true
+}
+
+// listFollowers returns the users following the user
+func (server *Server) listFollowers(ctx *gin.Context) {
+	reqUri, reqQuery, valid := server.bindListFollow(ctx)
+	if !valid {
+		return
+	}
+
+	rows, err := server.store.ListFollowers(ctx, db.ListFollowersParams{
+		UserUuid: reqUri.UserUuid,
+		Limit:    reqQuery.PageSize,
+		Offset:   (reqQuery.PageID - 1) * reqQuery.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := []followUserResponse{}
+	for _, row := range rows {
+		rsp = append(rsp, followUserResponse(row))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// listFollowing returns the users the user follows
+func (server *Server) listFollowing(ctx *gin.Context) {
+	reqUri, reqQuery, valid := server.bindListFollow(ctx)
+	if !valid {
+		return
+	}
+
+	rows, err := server.store.ListFollowing(ctx, db.ListFollowingParams{
+		UserUuid: reqUri.UserUuid,
+		Limit:    reqQuery.PageSize,
+		Offset:   (reqQuery.PageID - 1) * reqQuery.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := []followUserResponse{}
+	for _, row := range rows {
+		rsp = append(rsp, followUserResponse(row))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// listMutualFollows returns the users who follow the user and are followed back
+func (server *Server) listMutualFollows(ctx *gin.Context) {
+	reqUri, reqQuery, valid := server.bindListFollow(ctx)
+	if !valid {
+		return
+	}
+
+	rows, err := server.store.ListMutualFollows(ctx, db.ListMutualFollowsParams{
+		UserUuid: reqUri.UserUuid,
+		Limit:    reqQuery.PageSize,
+		Offset:   (reqQuery.PageID - 1) * reqQuery.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := []followUserResponse{}
+	for _, row := range rows {
+		rsp = append(rsp, followUserResponse(row))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
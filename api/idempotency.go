@@ -0,0 +1,215 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	idempotencyKeyHeaderKey = "Idempotency-Key"
+	idempotentReplayedKey   = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+	// the body is kept in memory to hash it, the requests that can be retried are small
+	maxIdempotentBodySize = 1 << 20
+)
+
+var (
+	errIdempotencyKeyTooLong    = errors.New("idempotency key is too long")
+	errIdempotencyKeyReused     = errors.New("idempotency key was already used for a different request")
+	errIdempotencyKeyInProgress = errors.New("a request with the same idempotency key is still in progress")
+)
+
+// bodyRecorder keeps a copy of the response written by the handler, so it can be replayed
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyMiddleware must be used after authMiddleware. When the request has the Idempotency-Key header,
+// the response of the first request with the key is stored for the user and sent back to the retries
+// until the key expires, so a retried request doesn't run twice.
+// The key can't be used for a different request, the server errors aren't stored, so the request can be retried.
+// A key still in progress after the timeout, like when the server crashed, can be claimed again.
+// The handler is cancelled when the timeout is over, so it can't run on while a retry runs the request again
+func idempotencyMiddleware(store db.Store, duration time.Duration, timeout time.Duration) gin.HandlerFunc {
+
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotencyKeyHeaderKey)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(errIdempotencyKeyTooLong))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxIdempotentBodySize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, errorResponse(err))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		// the handler reads the body again
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		requestHash := hashRequest(ctx.Request.Method, ctx.FullPath(), body)
+
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		user, err := store.GetUserByUserName(ctx, authPayload.Username)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		claimed, err := store.CreateIdempotencyKey(ctx, db.CreateIdempotencyKeyParams{
+			UserUuid:    user.Uuid,
+			Key:         key,
+			RequestHash: requestHash,
+			ExpiresAt:   time.Now().Add(duration),
+			ClaimToken:  uuid.New(),
+			StaleBefore: time.Now().Add(-timeout),
+		})
+		if err == sql.ErrNoRows {
+			replayResponse(ctx, store, user.Uuid, key, requestHash)
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		release := db.DeleteIdempotencyKeyParams{
+			Uuid:       claimed.Uuid,
+			ClaimToken: claimed.ClaimToken,
+		}
+
+		// a panic of the handler is a server error too, the key is released before the recovery responds
+		defer func() {
+			if r := recover(); r != nil {
+				// the request may be cancelled already, the key is released anyway
+				if err := store.DeleteIdempotencyKey(context.Background(), release); err != nil {
+					ctx.Error(err)
+				}
+				panic(r)
+			}
+		}()
+
+		// the key can be claimed again once it is stale, the handler is cancelled by then
+		handlerCtx, cancel := context.WithDeadline(ctx.Request.Context(), claimed.CreatedAt.Add(timeout))
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(handlerCtx)
+
+		recorder := &bodyRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Next()
+
+		// the response is stored even when the client has gone or the handler timed out,
+		// otherwise a retry would run the request again once the key is stale
+		storeCtx := context.Background()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			// the request failed on our side, the key is released so the client can try again
+			if err := store.DeleteIdempotencyKey(storeCtx, release); err != nil {
+				ctx.Error(err)
+			}
+			return
+		}
+		err = store.UpdateIdempotencyKeyResponse(storeCtx, db.UpdateIdempotencyKeyResponseParams{
+			Uuid:         claimed.Uuid,
+			ClaimToken:   claimed.ClaimToken,
+			StatusCode:   int32(status),
+			ResponseBody: recorder.body.Bytes(),
+		})
+		if err != nil {
+			ctx.Error(err)
+		}
+	}
+}
+
+// hashRequest tells the requests apart, a retry has the same hash as the first request
+func hashRequest(method string, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replayResponse sends back the stored response of the request that used the key first
+func replayResponse(ctx *gin.Context, store db.Store, userUuid int64, key string, requestHash string) {
+	stored, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		UserUuid: userUuid,
+		Key:      key,
+	})
+	if err != nil {
+		// the key was released by a failed request in the meantime
+		if err == sql.ErrNoRows {
+			ctx.AbortWithStatusJSON(http.StatusConflict, errorResponse(errIdempotencyKeyInProgress))
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if stored.RequestHash != requestHash {
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, errorResponse(errIdempotencyKeyReused))
+		return
+	}
+	if stored.StatusCode == 0 {
+		ctx.AbortWithStatusJSON(http.StatusConflict, errorResponse(errIdempotencyKeyInProgress))
+		return
+	}
+	ctx.Header(idempotentReplayedKey, "true")
+	ctx.Data(int(stored.StatusCode), "application/json; charset=utf-8", stored.ResponseBody)
+	ctx.Abort()
+}
+
+// sweepIdempotencyKeys deletes the expired idempotency keys every interval until the context is done.
+// The expired keys are already claimed again by the next request with the key, the sweeper only keeps the table small
+func (server *Server) sweepIdempotencyKeys(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := server.store.DeleteExpiredIdempotencyKeys(ctx)
+			if err != nil {
+				log.Printf("cannot delete the expired idempotency keys: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("deleted %d expired idempotency keys", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mockdb "github.com/alekseiapa/apple_store/db/mock"
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	user, _ := randomUser(t)
+	product := randomProduct()
+	order := randomOrder(user)
+	key := "4f1c2b0e-retry"
+	body := fmt.Sprintf(`{"user_uuid": %d, "product_uuid": %d, "quantity": 1}`, user.Uuid, product.Uuid)
+	requestHash := hashRequest(http.MethodPost, "/api/orders", []byte(body))
+	claimed := db.IdempotencyKey{
+		Uuid:        1,
+		UserUuid:    user.Uuid,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(time.Hour),
+		CreatedAt:   time.Now(),
+		ClaimToken:  uuid.New(),
+	}
+	release := db.DeleteIdempotencyKeyParams{Uuid: claimed.Uuid, ClaimToken: claimed.ClaimToken}
+
+	// buildOrderStubs stubs the purchase made by createOrder itself
+	buildOrderStubs := func(store *mockdb.MockStore, err error) {
+		store.EXPECT().
+			GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+			Times(1).
+			Return(user, nil)
+		store.EXPECT().
+			GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
+			Times(1).
+			Return(product, nil)
+		store.EXPECT().
+			BuyProductTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.BuyProductTxResult{Order: order}, err)
+	}
+
+	testCases := []struct {
+		name          string
+		key           string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "FirstRequest",
+			key:  key,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				arg := db.CreateIdempotencyKeyParams{
+					UserUuid:    user.Uuid,
+					Key:         key,
+					RequestHash: requestHash,
+				}
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), EqCreateIdempotencyKeyParams(arg)).
+					Times(1).
+					Return(claimed, nil)
+				buildOrderStubs(store, nil)
+				store.EXPECT().
+					UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpdateIdempotencyKeyResponseParams) error {
+						require.Equal(t, claimed.Uuid, arg.Uuid)
+						require.Equal(t, claimed.ClaimToken, arg.ClaimToken)
+						require.Equal(t, int32(http.StatusCreated), arg.StatusCode)
+						require.Contains(t, string(arg.ResponseBody), fmt.Sprintf(`"Uuid":%d`, order.Uuid))
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Empty(t, recorder.Header().Get(idempotentReplayedKey))
+			},
+		},
+		{
+			name: "Replay",
+			key:  key,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				stored := claimed
+				stored.StatusCode = http.StatusCreated
+				stored.ResponseBody = []byte(`{"stored":true}`)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{UserUuid: user.Uuid, Key: key})).
+					Times(1).
+					Return(stored, nil)
+				store.EXPECT().
+					BuyProductTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedKey))
+				require.Equal(t, `{"stored":true}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "DifferentPayload",
+			key:  key,
+			body: strings.Replace(body, `"quantity": 1`, `"quantity": 2`, 1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				stored := claimed
+				stored.StatusCode = http.StatusCreated
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(stored, nil)
+				store.EXPECT().
+					BuyProductTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "InProgress",
+			key:  key,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(claimed, nil)
+				store.EXPECT().
+					BuyProductTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "ServerErrorReleasesKey",
+			key:  key,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(claimed, nil)
+				buildOrderStubs(store, sql.ErrConnDone)
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(release)).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "PanicReleasesKey",
+			key:  key,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(claimed, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
+					Times(1).
+					Return(product, nil)
+				store.EXPECT().
+					BuyProductTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, _ db.BuyProductTxParams) (db.BuyProductTxResult, error) {
+						panic("purchase panicked")
+					})
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(release)).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "HandlerTimeout",
+			key:  key,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(claimed, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
+					Times(1).
+					Return(product, nil)
+				// the purchase has to be over before the key can be claimed again by a retry
+				store.EXPECT().
+					BuyProductTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, _ db.BuyProductTxParams) (db.BuyProductTxResult, error) {
+						deadline, ok := ctx.Deadline()
+						require.True(t, ok)
+						require.WithinDuration(t, claimed.CreatedAt.Add(time.Minute), deadline, time.Millisecond)
+						return db.BuyProductTxResult{}, context.DeadlineExceeded
+					})
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(release)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "BodyTooLarge",
+			key:  key,
+			body: fmt.Sprintf(`{"user_uuid": %d, "product_uuid": %d, "quantity": 1, "padding": %q}`,
+				user.Uuid, product.Uuid, strings.Repeat("x", maxIdempotentBodySize)),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					BuyProductTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			},
+		},
+		{
+			name: "KeyTooLong",
+			key:  strings.Repeat("k", maxIdempotencyKeyLength+1),
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					BuyProductTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoKey",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+				buildOrderStubs(store, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/api/orders", bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+			if tc.key != "" {
+				request.Header.Set(idempotencyKeyHeaderKey, tc.key)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// eqCreateIdempotencyKeyParamsMatcher ignores the expiry of the key and the time a claim becomes stale,
+// which depend on the time of the request, and the random token of the claim
+type eqCreateIdempotencyKeyParamsMatcher struct {
+	arg db.CreateIdempotencyKeyParams
+}
+
+func (e eqCreateIdempotencyKeyParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.CreateIdempotencyKeyParams)
+	if !ok {
+		return false
+	}
+	if !arg.ExpiresAt.After(time.Now()) || !arg.StaleBefore.Before(time.Now()) || arg.ClaimToken == uuid.Nil {
+		return false
+	}
+	arg.ExpiresAt = e.arg.ExpiresAt
+	arg.StaleBefore = e.arg.StaleBefore
+	arg.ClaimToken = e.arg.ClaimToken
+	return arg == e.arg
+}
+
+func (e eqCreateIdempotencyKeyParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v without the expiry, the stale time and the claim token", e.arg)
+}
+
+func EqCreateIdempotencyKeyParams(arg db.CreateIdempotencyKeyParams) gomock.Matcher {
+	return eqCreateIdempotencyKeyParamsMatcher{arg}
+}
+
+func TestSweepIdempotencyKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := NewTestServer(t, store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	swept := make(chan struct{})
+	var once sync.Once
+	// the sweeper keeps going after an error and stops once the context is done
+	gomock.InOrder(
+		store.EXPECT().
+			DeleteExpiredIdempotencyKeys(gomock.Any()).
+			Times(1).
+			Return(int64(0), sql.ErrConnDone),
+		store.EXPECT().
+			DeleteExpiredIdempotencyKeys(gomock.Any()).
+			MinTimes(1).
+			DoAndReturn(func(_ context.Context) (int64, error) {
+				once.Do(func() {
+					cancel()
+					close(swept)
+				})
+				return 2, nil
+			}),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		server.sweepIdempotencyKeys(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-swept:
+	case <-time.After(time.Second):
+		t.Fatal("expired idempotency keys were not deleted")
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper didn't stop")
+	}
+}
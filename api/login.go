@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/gin-gonic/gin"
+)
+
+// the reasons of the failed logins kept for the audit
+const (
+	failedLoginUnknownUser   = "unknown_user"
+	failedLoginWrongPassword = "wrong_password"
+	failedLoginLockedOut     = "locked_out"
+)
+
+const (
+	usernameThrottlePrefix = "username:"
+	ipThrottlePrefix       = "ip:"
+)
+
+// errInvalidCredentials is the same for an unknown username and a wrong password,
+// so the login can't be used to find out the usernames
+var errInvalidCredentials = errors.New("invalid username or password")
+
+var errTooManyFailedLogins = errors.New("too many failed logins, try again later")
+
+var (
+	dummyPasswordHashOnce sync.Once
+	dummyPasswordHash     string
+)
+
+// checkDummyPassword takes as long as checking the password of a user,
+// so an unknown username can't be told apart by the time of the response either
+func checkDummyPassword(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = util.HashPassword(util.RandomString(16))
+	})
+	_ = util.CheckPassword(password, dummyPasswordHash)
+}
+
+// loginThrottleLimits returns the throttles of the username and of the client ip, the username is always first
+// so concurrent logins lock the throttles in the same order
+func (server *Server) loginThrottleLimits(username string, clientIp string) []db.LoginThrottleLimit {
+	return []db.LoginThrottleLimit{
+		{Key: usernameThrottlePrefix + username, MaxFailures: server.config.LoginMaxFailures},
+		{Key: ipThrottlePrefix + clientIp, MaxFailures: server.config.LoginMaxIPFailures},
+	}
+}
+
+// reserveLoginAttempt counts the attempt as a failure before the password is checked, so concurrent guesses
+// can't get past the lockout. It responds with 429 when the username or the client ip is locked out
+func (server *Server) reserveLoginAttempt(ctx *gin.Context, username string) (db.ReserveLoginAttemptTxResult, bool) {
+	result, err := server.store.ReserveLoginAttemptTx(ctx, db.ReserveLoginAttemptTxParams{
+		Limits:      server.loginThrottleLimits(username, ctx.ClientIP()),
+		ResetBefore: time.Now().Add(-server.config.LoginFailureWindow),
+		Lockout:     server.config.LoginLockoutDuration,
+		MaxLockout:  server.config.LoginMaxLockoutDuration,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrLoginLocked) {
+			server.lockedOutLogin(ctx, username, result.LockedUntil)
+			return result, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return result, false
+	}
+	return result, true
+}
+
+// loginSucceeded forgets the failures of the username. The ones of the client ip aren't forgotten,
+// so a login to an account of their own doesn't let anyone try more passwords of the others,
+// only the attempt reserved for the client ip is given back
+func (server *Server) loginSucceeded(ctx *gin.Context, username string, reserved db.ReserveLoginAttemptTxResult) bool {
+	if err := server.store.DeleteLoginThrottle(ctx, usernameThrottlePrefix+username); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	for _, attempt := range reserved.Attempts {
+		if !strings.HasPrefix(attempt.Throttle.Key, ipThrottlePrefix) {
+			continue
+		}
+		err := server.store.RefundLoginAttempt(ctx, db.RefundLoginAttemptParams{
+			Key:               attempt.Throttle.Key,
+			FailureAt:         attempt.Throttle.LastFailureAt,
+			PreviousFailureAt: attempt.PreviousFailureAt,
+			LockedUntil:       attempt.Throttle.LockedUntil,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+	}
+	return true
+}
+
+// sweepLoginThrottles deletes the throttles without a failure in the failure window every interval
+// until the context is done. Their failures would be counted from one again anyway, the sweeper only keeps the table small
+func (server *Server) sweepLoginThrottles(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := server.store.DeleteStaleLoginThrottles(ctx, time.Now().Add(-server.config.LoginFailureWindow))
+			if err != nil {
+				log.Printf("cannot delete the stale login throttles: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("deleted %d stale login throttles", n)
+			}
+		}
+	}
+}
+
+// lockedOutLogin records the login refused because of a lockout, it doesn't count as another failure
+// so the lockout doesn't grow while the user waits
+func (server *Server) lockedOutLogin(ctx *gin.Context, username string, lockedUntil time.Time) {
+	_, err := server.store.CreateFailedLogin(ctx, db.CreateFailedLoginParams{
+		Username:  username,
+		ClientIp:  ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+		Reason:    failedLoginLockedOut,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	tooManyRequests(ctx, lockedUntil, errTooManyFailedLogins)
+}
+
+// tooManyRequests responds with 429 and tells the client when the lockout ends
+func tooManyRequests(ctx *gin.Context, lockedUntil time.Time, err error) {
+	retryAfter := math.Ceil(time.Until(lockedUntil).Seconds())
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Max(retryAfter, 1))))
+	ctx.JSON(http.StatusTooManyRequests, errorResponse(err))
+}
+
+// failedLogin records the failed login for the audit and responds with the same error whatever the reason was.
+// The failure was already counted by reserveLoginAttempt
+func (server *Server) failedLogin(ctx *gin.Context, username string, reason string) {
+	_, err := server.store.CreateFailedLogin(ctx, db.CreateFailedLoginParams{
+		Username:  username,
+		ClientIp:  ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+		Reason:    reason,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
+}
+
+type failedLoginResponse struct {
+	Uuid      int64     `json:"uuid"`
+	Username  string    `json:"username"`
+	ClientIp  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newFailedLoginResponse(failedLogin db.FailedLogin) failedLoginResponse {
+	return failedLoginResponse{
+		Uuid:      failedLogin.Uuid,
+		Username:  failedLogin.Username,
+		ClientIp:  failedLogin.ClientIp,
+		UserAgent: failedLogin.UserAgent,
+		Reason:    failedLogin.Reason,
+		CreatedAt: failedLogin.CreatedAt,
+	}
+}
+
+type listFailedLoginRequest struct {
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5"`
+	Username string `form:"username"`
+	ClientIp string `form:"client_ip" binding:"omitempty,ip"`
+}
+
+// listFailedLogin returns the audit of the failed logins for the admins, the newest come first
+func (server *Server) listFailedLogin(ctx *gin.Context) {
+	var req listFailedLoginRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	failedLogins, err := server.store.ListFailedLogins(ctx, db.ListFailedLoginsParams{
+		Username: sql.NullString{String: req.Username, Valid: req.Username != ""},
+		ClientIp: sql.NullString{String: req.ClientIp, Valid: req.ClientIp != ""},
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := []failedLoginResponse{}
+	for _, failedLogin := range failedLogins {
+		rsp = append(rsp, newFailedLoginResponse(failedLogin))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	mockdb "github.com/alekseiapa/apple_store/db/mock"
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFailedLoginAPI(t *testing.T) {
+	failedLogins := []db.FailedLogin{
+		{Uuid: 2, Username: "alice", ClientIp: "192.0.2.1", Reason: failedLoginWrongPassword, CreatedAt: time.Now()},
+		{Uuid: 1, Username: "alice", ClientIp: "192.0.2.1", Reason: failedLoginUnknownUser, CreatedAt: time.Now()},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?page_id=2&page_size=5&username=alice&client_ip=192.0.2.1",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListFailedLoginsParams{
+					Username: sql.NullString{String: "alice", Valid: true},
+					ClientIp: sql.NullString{String: "192.0.2.1", Valid: true},
+					Limit:    5,
+					Offset:   5,
+				}
+				store.EXPECT().
+					ListFailedLogins(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(failedLogins, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchFailedLogins(t, recorder.Body, failedLogins)
+			},
+		},
+		{
+			name:  "NoFilters",
+			query: "?page_id=1&page_size=5",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListFailedLoginsParams{
+					Limit:  5,
+					Offset: 0,
+				}
+				store.EXPECT().
+					ListFailedLogins(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.FailedLogin{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidClientIp",
+			query: "?page_id=1&page_size=5&client_ip=nowhere",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListFailedLogins(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			query: "?page_id=1&page_size=5",
+			role:  util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListFailedLogins(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "?page_id=1&page_size=5",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListFailedLogins(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/api/failed_logins"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requireBodyMatchFailedLogins(t *testing.T, body *bytes.Buffer, failedLogins []db.FailedLogin) {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotFailedLogins []failedLoginResponse
+	err = json.Unmarshal(data, &gotFailedLogins)
+	require.NoError(t, err)
+	require.Len(t, gotFailedLogins, len(failedLogins))
+	for i, failedLogin := range failedLogins {
+		require.Equal(t, failedLogin.Uuid, gotFailedLogins[i].Uuid)
+		require.Equal(t, failedLogin.Username, gotFailedLogins[i].Username)
+		require.Equal(t, failedLogin.Reason, gotFailedLogins[i].Reason)
+	}
+}
+
+func TestSweepLoginThrottles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := NewTestServer(t, store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	swept := make(chan struct{})
+	var once sync.Once
+	// the sweeper keeps going after an error and stops once the context is done
+	gomock.InOrder(
+		store.EXPECT().
+			DeleteStaleLoginThrottles(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(int64(0), sql.ErrConnDone),
+		store.EXPECT().
+			DeleteStaleLoginThrottles(gomock.Any(), gomock.Any()).
+			MinTimes(1).
+			DoAndReturn(func(_ context.Context, resetBefore time.Time) (int64, error) {
+				require.WithinDuration(t, time.Now().Add(-24*time.Hour), resetBefore, time.Minute)
+				once.Do(func() {
+					cancel()
+					close(swept)
+				})
+				return 2, nil
+			}),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		server.sweepLoginThrottles(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-swept:
+	case <-time.After(time.Second):
+		t.Fatal("stale login throttles were not deleted")
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper didn't stop")
+	}
+}
+
+// without the trusted proxies all the clients behind a proxy would share its ip and lock each other out
+func TestNewServerWithoutTrustedProxies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewTestServer(t, mockdb.NewMockStore(ctrl))
+	config := server.config
+	config.TrustedProxies = nil
+	_, err := NewServer(config, server.store, server.rates, server.storage, server.notifier)
+	require.ErrorIs(t, err, errTrustedProxiesNotSet)
+}
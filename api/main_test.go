@@ -1,11 +1,15 @@
 package api
 
 import (
+	"context"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/notify"
+	"github.com/alekseiapa/apple_store/storage"
 	"github.com/stretchr/testify/require"
 
 	"github.com/alekseiapa/apple_store/util"
@@ -17,14 +21,53 @@ import (
 
 func NewTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:          util.RandomString(32),
+		AccessTokenDuration:        time.Minute,
+		RefreshTokenDuration:       time.Hour,
+		ReservationDuration:        time.Minute,
+		IdempotencyKeyDuration:     time.Hour,
+		IdempotencyKeyTimeout:      time.Minute,
+		PasswordResetTokenDuration: time.Hour,
+		PasswordResetMaxRequests:   3,
+		PasswordResetMaxIPRequests: 10,
+		LoginMaxFailures:           3,
+		LoginMaxIPFailures:         10,
+		LoginLockoutDuration:       time.Minute,
+		LoginMaxLockoutDuration:    time.Hour,
+		LoginFailureWindow:         24 * time.Hour,
+		TrustedProxies:             []string{"127.0.0.1"},
 	}
-	server, err := NewServer(config, store)
+	// the rates are read from a file, so the tests don't depend on the rates stored in the db
+	rates, err := util.NewFileRateProvider("testdata/rates.json")
+	require.NoError(t, err)
+
+	files, err := storage.NewLocalStorage(t.TempDir(), "/media")
+	require.NoError(t, err)
+
+	server, err := NewServer(config, store, rates, files, &recordingNotifier{})
 	require.NoError(t, err)
 	return server
 }
 
+// recordingNotifier keeps the sent messages, so the tests can read the tokens sent to the users
+type recordingNotifier struct {
+	mu       sync.Mutex
+	messages []notify.Message
+}
+
+func (notifier *recordingNotifier) Send(ctx context.Context, msg notify.Message) error {
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	notifier.messages = append(notifier.messages, msg)
+	return nil
+}
+
+func (notifier *recordingNotifier) Messages() []notify.Message {
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	return append([]notify.Message(nil), notifier.messages...)
+}
+
 // config the way how the tests will run. In this case I want to make the output less verbose
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	db "github.com/alekseiapa/apple_store/db/sqlc"
 	"github.com/alekseiapa/apple_store/token"
 	"github.com/gin-gonic/gin"
 )
@@ -19,7 +20,7 @@ const (
 // the gin.HandlerFunction type is a  function that takes a context as input.
 // So we return an anonymous function with the same required signature here.
 // This anonymous function is in fact  the authentication middleware function
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+func authMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
 
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
@@ -45,13 +46,45 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		}
 
 		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
+		payload, err := tokenMaker.VerifyToken(accessToken, token.TokenTypeAccessToken)
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
+		// the token is still valid, but it could have been revoked by logging out or with all the tokens of the user
+		revoked, err := store.IsTokenRevoked(ctx, db.IsTokenRevokedParams{
+			Uuid:     payload.ID,
+			Username: payload.Username,
+			IssuedAt: payload.IssuedAt,
+		})
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if revoked {
+			err := errors.New("token has been revoked")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
 		// store the payload in the context  before passing it to the next handler
 		ctx.Set(authorizationPayloadKey, payload)
 		ctx.Next()
 	}
 }
+
+// roleMiddleware must be used after authMiddleware, since it relies on the payload stored in the context.
+// It only lets the request through if the role of the authenticated user is one of the allowed roles
+func roleMiddleware(allowedRoles ...string) gin.HandlerFunc {
+
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		for _, role := range allowedRoles {
+			if authPayload.Role == role {
+				ctx.Next()
+				return
+			}
+		}
+		err := fmt.Errorf("role %s is not allowed to access this resource", authPayload.Role)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+	}
+}
@@ -2,33 +2,76 @@ package api
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/token"
+	"github.com/alekseiapa/apple_store/util"
 	"github.com/gin-gonic/gin"
 )
 
 // these api endpoints are inspired by https://developers.shopware.com/developers-guide/rest-api/examples/order/
 type createOrderRequest struct {
 	UserUuid    int64 `json:"user_uuid" binding:"required"`
-	Quantity    int32 `json:"quantity" binding:"required"`
+	Quantity    int32 `json:"quantity" binding:"required,min=1"`
 	ProductUuid int64 `json:"product_uuid" binding:"required"`
+	// Currency of the wallet to pay with, a wallet with enough money is chosen when it is empty
+	Currency string `json:"currency" binding:"omitempty,len=3"`
+}
+
+type orderLineResponse struct {
+	ProductUuid int64      `json:"product_uuid"`
+	Description string     `json:"description"`
+	Quantity    int32      `json:"quantity"`
+	UnitPrice   util.Money `json:"unit_price"`
+	Currency    string     `json:"currency"`
 }
 
 type orderResponse struct {
-	Uuid     int64 `json:"order_uuid"`
-	UserUuid int64 `json:"user_uuid"`
-	Quantity int64 `json:"quantity"`
+	Uuid         int64               `json:"order_uuid"`
+	UserUuid     int64               `json:"user_uuid"`
+	UserFullName string              `json:"user_full_name,omitempty"`
+	Quantity     int64               `json:"quantity"`
+	Status       string              `json:"status"`
+	CreatedAt    time.Time           `json:"created_at"`
+	Products     []orderLineResponse `json:"products,omitempty"`
 }
 
 func newOrderResponse(order db.Order) orderResponse {
 	return orderResponse{
-		Uuid:     order.Uuid,
-		UserUuid: order.UserUuid,
-		Quantity: order.Quantity,
+		Uuid:      order.Uuid,
+		UserUuid:  order.UserUuid,
+		Quantity:  order.Quantity,
+		Status:    order.Status,
+		CreatedAt: order.CreatedAt,
 	}
 }
 
+func newOrderDetailsResponse(order db.GetOrderDetailsRow, lines []db.ListOrderLinesRow) orderResponse {
+	rsp := orderResponse{
+		Uuid:         order.Uuid,
+		UserUuid:     order.UserUuid,
+		UserFullName: order.UserFullName,
+		Quantity:     order.Quantity,
+		Status:       order.Status,
+		CreatedAt:    order.CreatedAt,
+		Products:     []orderLineResponse{},
+	}
+	for _, line := range lines {
+		rsp.Products = append(rsp.Products, orderLineResponse{
+			ProductUuid: line.ProductUuid,
+			Description: line.ProductDescription,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			Currency:    line.Currency,
+		})
+	}
+	return rsp
+}
+
 func (server *Server) createOrder(ctx *gin.Context) {
 	var req createOrderRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -36,7 +79,7 @@ func (server *Server) createOrder(ctx *gin.Context) {
 		return
 	}
 
-	if !server.validUser(ctx, req.UserUuid) {
+	if _, valid := server.validUser(ctx, req.UserUuid); !valid {
 		return
 	}
 
@@ -48,11 +91,12 @@ func (server *Server) createOrder(ctx *gin.Context) {
 		UserUuid:    req.UserUuid,
 		Quantity:    req.Quantity,
 		ProductUuid: req.ProductUuid,
+		Currency:    req.Currency,
 	}
 	order, err := server.store.BuyProductTx(ctx, arg)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		purchaseError(ctx, err)
 		return
 	}
 
@@ -63,14 +107,24 @@ type getOrderRequest struct {
 	Uuid int64 `uri:"id" binding:"required,min=1"`
 }
 
+// getOrder returns the order with its lines. Customers can only read their own orders, while admins can read
+// the orders of any user, the same as listOrder
 func (server *Server) getOrder(ctx *gin.Context) {
 	var req getOrderRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	owner, valid := server.orderOwner(ctx)
+	if !valid {
+		return
+	}
 
-	order, err := server.store.GetOrder(ctx, req.Uuid)
+	order, err := server.store.GetOrderDetails(ctx, req.Uuid)
+	if err == nil && !owner.owns(order.UserUuid) {
+		// the order of another user isn't found either, so nobody can find out which orders exist
+		err = sql.ErrNoRows
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
@@ -79,7 +133,142 @@ func (server *Server) getOrder(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-	rsp := newOrderResponse(order)
+	lines, err := server.store.ListOrderLines(ctx, order.Uuid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := newOrderDetailsResponse(order, lines)
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type listOrderRequest struct {
+	PageID    int32     `form:"page_id" binding:"omitempty,min=1"`
+	PageSize  int32     `form:"page_size" binding:"required,min=5"`
+	UserUuid  int64     `form:"user_uuid" binding:"omitempty,min=1"`
+	From      time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To        time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+	Cursor    string    `form:"cursor"`
+	WithTotal bool      `form:"with_total"`
+}
+
+type listOrderResponse struct {
+	Items []orderResponse `json:"items"`
+	listPage
+}
+
+// listOrder returns the orders created between the from and to days (both inclusive).
+// Customers can only list their own orders, while admins can list the orders of any user.
+// The orders are paged by the cursor, the page_id keeps the old offset pages working
+func (server *Server) listOrder(ctx *gin.Context) {
+	var req listOrderRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.PageID != 0 && req.Cursor != "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errCursorWithPageID))
+		return
+	}
+	owner, valid := server.orderOwner(ctx)
+	if !valid {
+		return
+	}
+	if !owner.all {
+		if req.UserUuid != 0 && req.UserUuid != owner.userUuid {
+			err := errors.New("can't list the orders of another user")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		req.UserUuid = owner.userUuid
+	}
+
+	filter := db.CountOrdersByFilterParams{
+		UserUuid:    sql.NullInt64{Int64: req.UserUuid, Valid: req.UserUuid != 0},
+		CreatedFrom: sql.NullTime{Time: req.From, Valid: !req.From.IsZero()},
+	}
+	if !req.To.IsZero() {
+		// the whole "to" day is included
+		filter.CreatedTo = sql.NullTime{Time: req.To.AddDate(0, 0, 1), Valid: true}
+	}
+	if req.PageID == 0 {
+		server.listOrderByCursor(ctx, req, filter)
+		return
+	}
+
+	arg := db.ListOrdersByFilterParams{
+		UserUuid:    filter.UserUuid,
+		CreatedFrom: filter.CreatedFrom,
+		CreatedTo:   filter.CreatedTo,
+		Limit:       req.PageSize,
+		Offset:      (req.PageID - 1) * req.PageSize,
+	}
+	orders, err := server.store.ListOrdersByFilter(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := []orderResponse{}
+	for _, order := range orders {
+		rsp = append(rsp, newOrderResponse(order))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) listOrderByCursor(ctx *gin.Context, req listOrderRequest, filter db.CountOrdersByFilterParams) {
+	cursor, err := listCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	// one more order tells if there is another page
+	var orders []db.Order
+	if cursor.Backward {
+		orders, err = server.store.ListOrdersBefore(ctx, db.ListOrdersBeforeParams{
+			UserUuid:    filter.UserUuid,
+			CreatedFrom: filter.CreatedFrom,
+			CreatedTo:   filter.CreatedTo,
+			BeforeUuid:  cursor.Uuid,
+			Limit:       req.PageSize + 1,
+		})
+	} else {
+		orders, err = server.store.ListOrdersAfter(ctx, db.ListOrdersAfterParams{
+			UserUuid:    filter.UserUuid,
+			CreatedFrom: filter.CreatedFrom,
+			CreatedTo:   filter.CreatedTo,
+			AfterUuid:   cursor.Uuid,
+			Limit:       req.PageSize + 1,
+		})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	more := len(orders) > int(req.PageSize)
+	if more {
+		orders = orders[:req.PageSize]
+	}
+
+	rsp := listOrderResponse{Items: make([]orderResponse, len(orders))}
+	uuids := make([]int64, len(orders))
+	for i, order := range orders {
+		// the backward pages come in the reverse order
+		j := i
+		if cursor.Backward {
+			j = len(orders) - 1 - i
+		}
+		rsp.Items[j] = newOrderResponse(order)
+		uuids[j] = order.Uuid
+	}
+	rsp.listPage = newListPage(cursor, uuids, more)
+	if req.WithTotal {
+		total, err := server.store.CountOrdersByFilter(ctx, filter)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		rsp.Total = &total
+	}
 	ctx.JSON(http.StatusOK, rsp)
 }
 
@@ -87,42 +276,198 @@ type deleteOrderRequest struct {
 	Uuid int64 `uri:"id" binding:"required,min=1"`
 }
 
+// deleteOrder cancels the order instead of deleting it, so the stock and the balance of the user are restored
 func (server *Server) deleteOrder(ctx *gin.Context) {
 	var req deleteOrderRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	if _, valid := server.validOrder(ctx, req.Uuid); !valid {
+		return
+	}
 
-	r, err := server.store.DeleteOrder(ctx, req.Uuid)
+	result, err := server.store.CancelOrderTx(ctx, db.CancelOrderTxParams{
+		OrderUuid: req.Uuid,
+		Status:    util.OrderCancelled,
+	})
+	if err != nil {
+		orderStatusError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, newOrderResponse(result.Order))
+}
+
+type updateOrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// updateOrderStatus moves the order to the next status of its lifecycle.
+// Cancelling or refunding the order restores the stock and the balance of the user
+func (server *Server) updateOrderStatus(ctx *gin.Context) {
+	var uriReq getOrderRequest
+	if err := ctx.ShouldBindUri(&uriReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req updateOrderStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if !util.IsSupportedOrderStatus(req.Status) {
+		err := fmt.Errorf("unsupported order status: %v", req.Status)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Status == util.OrderCancelled || req.Status == util.OrderRefunded {
+		result, err := server.store.CancelOrderTx(ctx, db.CancelOrderTxParams{
+			OrderUuid: uriReq.Uuid,
+			Status:    req.Status,
+		})
+		if err != nil {
+			orderStatusError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, newOrderResponse(result.Order))
+		return
+	}
+
+	order, err := server.store.GetOrder(ctx, uriReq.Uuid)
+	if err != nil {
+		orderStatusError(ctx, err)
+		return
+	}
+	if !util.CanTransitionOrder(order.Status, req.Status) {
+		err := fmt.Errorf("%w: %v -> %v", db.ErrInvalidOrderTransition, order.Status, req.Status)
+		orderStatusError(ctx, err)
+		return
+	}
+	order, err = server.store.UpdateOrderStatus(ctx, db.UpdateOrderStatusParams{
+		Status:     req.Status,
+		Uuid:       order.Uuid,
+		FromStatus: order.Status,
+	})
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			err := errors.New("the order status has been changed concurrently")
+			ctx.JSON(http.StatusConflict, errorResponse(err))
 			return
 		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-	if r == 0 {
+	ctx.JSON(http.StatusOK, newOrderResponse(order))
+}
+
+// purchaseError responds with the status code matching an error of a purchase
+func purchaseError(ctx *gin.Context, err error) {
+	if errors.Is(err, db.ErrEmptyCart) || errors.Is(err, db.ErrNotEnoughMoney) || errors.Is(err, db.ErrNotEnoughStock) ||
+		errors.Is(err, db.ErrInvalidAmount) || errors.Is(err, util.ErrUnsupportedCurrency) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
+
+// orderStatusError responds with the status code matching an error of a status change
+func orderStatusError(ctx *gin.Context, err error) {
+	if err == sql.ErrNoRows {
 		ctx.JSON(http.StatusNotFound, notFoundResponse("order"))
 		return
 	}
-	ctx.JSON(http.StatusOK, successDeleteResponse())
+	if errors.Is(err, db.ErrInvalidOrderTransition) {
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 }
 
-func (server *Server) validUser(ctx *gin.Context, UserUuid int64) bool {
-	_, err := server.store.GetUser(ctx, UserUuid)
+// validUser checks that the user exists and belongs to the authenticated user
+func (server *Server) validUser(ctx *gin.Context, UserUuid int64) (db.User, bool) {
+	user, err := server.store.GetUser(ctx, UserUuid)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return false
+			return user, false
 		}
 
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return false
+		return user, false
 	}
 
-	return true
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if user.Username != authPayload.Username {
+		err := errors.New("user doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return user, false
+	}
+
+	return user, true
+}
+
+// authUser returns the user the access token was issued for
+func (server *Server) authUser(ctx *gin.Context) (db.User, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.store.GetUserByUserName(ctx, authPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return user, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return user, false
+	}
+
+	return user, true
+}
+
+// orderOwner is whose orders the authenticated user can see
+type orderOwner struct {
+	// all is set for the admins, who can see the orders of all the users
+	all      bool
+	userUuid int64
+}
+
+func (owner orderOwner) owns(userUuid int64) bool {
+	return owner.all || owner.userUuid == userUuid
+}
+
+// orderOwner returns whose orders the authenticated user can see, the handlers of the orders check it the same way
+func (server *Server) orderOwner(ctx *gin.Context) (orderOwner, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Role == util.AdminRole {
+		return orderOwner{all: true}, true
+	}
+	user, valid := server.authUser(ctx)
+	if !valid {
+		return orderOwner{}, false
+	}
+	return orderOwner{userUuid: user.Uuid}, true
+}
+
+// validOrder checks that the order exists and can be seen by the authenticated user.
+// The order of another user isn't found, the same as in getOrder
+func (server *Server) validOrder(ctx *gin.Context, OrderUuid int64) (db.Order, bool) {
+	owner, valid := server.orderOwner(ctx)
+	if !valid {
+		return db.Order{}, false
+	}
+	order, err := server.store.GetOrder(ctx, OrderUuid)
+	if err == nil && !owner.owns(order.UserUuid) {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Order{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Order{}, false
+	}
+	return order, true
 }
 
 func (server *Server) validProduct(ctx *gin.Context, ProductUuid int64) bool {
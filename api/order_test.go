@@ -0,0 +1,815 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/alekseiapa/apple_store/db/mock"
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/token"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrderAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	order := randomOrderDetails(user)
+	product := randomProduct()
+	lines := []db.ListOrderLinesRow{
+		{
+			ProductUuid:        product.Uuid,
+			ProductDescription: product.Description,
+			Quantity:           int32(order.Quantity),
+			UnitPrice:          product.Price,
+			Currency:           util.BaseCurrency,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		OrderUuid     int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			OrderUuid: order.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetOrderDetails(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(order, nil)
+				store.EXPECT().
+					ListOrderLines(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(lines, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchOrder(t, recorder.Body, order, lines)
+			},
+		},
+		{
+			name:      "OrderOfAnotherUser",
+			OrderUuid: order.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, other.Username, other.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(other.Username)).
+					Times(1).
+					Return(other, nil)
+				store.EXPECT().
+					GetOrderDetails(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(order, nil)
+				store.EXPECT().
+					ListOrderLines(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				// the same as for an order that doesn't exist
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "Admin",
+			OrderUuid: order.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					GetOrderDetails(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(order, nil)
+				store.EXPECT().
+					ListOrderLines(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(lines, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchOrder(t, recorder.Body, order, lines)
+			},
+		},
+		{
+			name:      "NotFound",
+			OrderUuid: order.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetOrderDetails(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(db.GetOrderDetailsRow{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			OrderUuid: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOrderDetails(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/orders/%d", tc.OrderUuid)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListOrderAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	orders := make([]db.Order, 6)
+	for i := range orders {
+		orders[i] = randomOrder(user)
+		orders[i].Uuid = int64(i + 1)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OwnOrders",
+			query: "page_id=1&page_size=5&from=2023-01-01&to=2023-01-31",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				arg := db.ListOrdersByFilterParams{
+					UserUuid:    sql.NullInt64{Int64: user.Uuid, Valid: true},
+					CreatedFrom: sql.NullTime{Time: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+					CreatedTo:   sql.NullTime{Time: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+					Limit:       5,
+					Offset:      0,
+				}
+				store.EXPECT().
+					ListOrdersByFilter(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Order{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "OrdersOfAnotherUser",
+			query: fmt.Sprintf("page_id=1&page_size=5&user_uuid=%d", user.Uuid+1),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListOrdersByFilter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "AdminListsAllOrders",
+			query: "page_id=2&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListOrdersByFilterParams{
+					Limit:  5,
+					Offset: 5,
+				}
+				store.EXPECT().
+					ListOrdersByFilter(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Order{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "FirstPageByCursor",
+			query: "page_size=5&with_total=true",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				arg := db.ListOrdersAfterParams{
+					UserUuid:  sql.NullInt64{Int64: user.Uuid, Valid: true},
+					AfterUuid: 0,
+					Limit:     6,
+				}
+				store.EXPECT().
+					ListOrdersAfter(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(orders, nil)
+				store.EXPECT().
+					CountOrdersByFilter(gomock.Any(), gomock.Eq(db.CountOrdersByFilterParams{
+						UserUuid: sql.NullInt64{Int64: user.Uuid, Valid: true},
+					})).
+					Times(1).
+					Return(int64(len(orders)), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				rsp := requireBodyMatchOrderPage(t, recorder.Body, orders[:5])
+				require.Equal(t, pageCursor{Uuid: orders[4].Uuid}.encode(), rsp.NextCursor)
+				require.Empty(t, rsp.PrevCursor)
+				require.NotNil(t, rsp.Total)
+				require.Equal(t, int64(len(orders)), *rsp.Total)
+			},
+		},
+		{
+			name:  "LastPageByCursor",
+			query: "page_size=5&cursor=" + pageCursor{Uuid: orders[0].Uuid}.encode(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListOrdersAfterParams{
+					AfterUuid: orders[0].Uuid,
+					Limit:     6,
+				}
+				store.EXPECT().
+					ListOrdersAfter(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(orders[1:], nil)
+				store.EXPECT().
+					CountOrdersByFilter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				rsp := requireBodyMatchOrderPage(t, recorder.Body, orders[1:])
+				require.Empty(t, rsp.NextCursor)
+				require.Equal(t, pageCursor{Uuid: orders[1].Uuid, Backward: true}.encode(), rsp.PrevCursor)
+				require.Nil(t, rsp.Total)
+			},
+		},
+		{
+			name:  "PrevPageByCursor",
+			query: "page_size=5&cursor=" + pageCursor{Uuid: orders[5].Uuid, Backward: true}.encode(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListOrdersBeforeParams{
+					BeforeUuid: orders[5].Uuid,
+					Limit:      6,
+				}
+				// the orders before the cursor come in the reverse order
+				reversed := []db.Order{orders[4], orders[3], orders[2], orders[1], orders[0]}
+				store.EXPECT().
+					ListOrdersBefore(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(reversed, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				rsp := requireBodyMatchOrderPage(t, recorder.Body, orders[:5])
+				require.Equal(t, pageCursor{Uuid: orders[4].Uuid}.encode(), rsp.NextCursor)
+				require.Empty(t, rsp.PrevCursor)
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: "page_size=5&cursor=invalid",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOrdersAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "CursorWithPageID",
+			query: "page_id=1&page_size=5&cursor=" + pageCursor{Uuid: orders[0].Uuid}.encode(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOrdersByFilter(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ListOrdersAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidPageSize",
+			query: "page_id=1&page_size=1",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOrdersByFilter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/api/orders?" + tc.query
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateOrderInvalidQuantityAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	product := randomProduct()
+
+	for _, quantity := range []int32{0, -1} {
+		t.Run(fmt.Sprint(quantity), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				BuyProductTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body := fmt.Sprintf(`{"user_uuid": %d, "product_uuid": %d, "quantity": %d}`, user.Uuid, product.Uuid, quantity)
+			request, err := http.NewRequest(http.MethodPost, "/api/orders", bytes.NewReader([]byte(body)))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+		})
+	}
+}
+
+func TestDeleteOrderAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	order := randomOrder(user)
+	cancelled := order
+	cancelled.Status = util.OrderCancelled
+
+	testCases := []struct {
+		name          string
+		OrderUuid     int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			OrderUuid: order.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetOrder(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(order, nil)
+				arg := db.CancelOrderTxParams{
+					OrderUuid: order.Uuid,
+					Status:    util.OrderCancelled,
+				}
+				store.EXPECT().
+					CancelOrderTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CancelOrderTxResult{Order: cancelled}, nil)
+				store.EXPECT().
+					DeleteOrder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got orderResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, util.OrderCancelled, got.Status)
+			},
+		},
+		{
+			name:      "AlreadyShipped",
+			OrderUuid: order.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetOrder(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(order, nil)
+				store.EXPECT().
+					CancelOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CancelOrderTxResult{}, db.ErrInvalidOrderTransition)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "OrderOfAnotherUser",
+			OrderUuid: order.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, other.Username, other.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(other.Username)).
+					Times(1).
+					Return(other, nil)
+				store.EXPECT().
+					GetOrder(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(order, nil)
+				store.EXPECT().
+					CancelOrderTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			OrderUuid: order.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetOrder(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(order, nil)
+				store.EXPECT().
+					CancelOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CancelOrderTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/orders/%d", tc.OrderUuid)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateOrderStatusAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	order := randomOrder(user)
+	shipped := order
+	shipped.Status = util.OrderShipped
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Shipped",
+			body: gin.H{"status": util.OrderShipped},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOrder(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(order, nil)
+				arg := db.UpdateOrderStatusParams{
+					Status:     util.OrderShipped,
+					Uuid:       order.Uuid,
+					FromStatus: util.OrderPaid,
+				}
+				store.EXPECT().
+					UpdateOrderStatus(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(shipped, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Refunded",
+			body: gin.H{"status": util.OrderRefunded},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CancelOrderTxParams{
+					OrderUuid: order.Uuid,
+					Status:    util.OrderRefunded,
+				}
+				store.EXPECT().
+					CancelOrderTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CancelOrderTxResult{}, nil)
+				store.EXPECT().
+					UpdateOrderStatus(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidTransition",
+			body: gin.H{"status": util.OrderDelivered},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOrder(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(order, nil)
+				store.EXPECT().
+					UpdateOrderStatus(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "ChangedConcurrently",
+			body: gin.H{"status": util.OrderShipped},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOrder(gomock.Any(), gomock.Eq(order.Uuid)).
+					Times(1).
+					Return(order, nil)
+				store.EXPECT().
+					UpdateOrderStatus(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Order{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "UnsupportedStatus",
+			body: gin.H{"status": "lost"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOrder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Customer",
+			body: gin.H{"status": util.OrderShipped},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOrder(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/api/orders/%d/status", order.Uuid)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomOrder(user db.User) db.Order {
+	return db.Order{
+		Uuid:      int64(util.RandomInt(1, 1000)),
+		UserUuid:  user.Uuid,
+		Quantity:  util.RandomOrderQuantity(),
+		CreatedAt: time.Now(),
+		Status:    util.OrderPaid,
+	}
+}
+
+func randomOrderDetails(user db.User) db.GetOrderDetailsRow {
+	return db.GetOrderDetailsRow{
+		Uuid:         int64(util.RandomInt(1, 1000)),
+		UserUuid:     user.Uuid,
+		Quantity:     util.RandomOrderQuantity(),
+		CreatedAt:    time.Now(),
+		Status:       util.OrderPaid,
+		UserFullName: user.FullName,
+	}
+}
+
+func requireBodyMatchOrder(t *testing.T, body *bytes.Buffer, order db.GetOrderDetailsRow, lines []db.ListOrderLinesRow) {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotOrder orderResponse
+	err = json.Unmarshal(data, &gotOrder)
+	require.NoError(t, err)
+
+	require.Equal(t, order.Uuid, gotOrder.Uuid)
+	require.Equal(t, order.UserUuid, gotOrder.UserUuid)
+	require.Equal(t, order.UserFullName, gotOrder.UserFullName)
+	require.Equal(t, order.Quantity, gotOrder.Quantity)
+	require.Len(t, gotOrder.Products, len(lines))
+	for i, line := range lines {
+		require.Equal(t, line.ProductUuid, gotOrder.Products[i].ProductUuid)
+		require.Equal(t, line.ProductDescription, gotOrder.Products[i].Description)
+		require.Equal(t, line.Quantity, gotOrder.Products[i].Quantity)
+		require.Equal(t, line.UnitPrice, gotOrder.Products[i].UnitPrice)
+	}
+}
+
+func requireBodyMatchOrderPage(t *testing.T, body *bytes.Buffer, orders []db.Order) listOrderResponse {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotPage listOrderResponse
+	err = json.Unmarshal(data, &gotPage)
+	require.NoError(t, err)
+	require.Len(t, gotPage.Items, len(orders))
+	for i, order := range orders {
+		require.Equal(t, order.Uuid, gotPage.Items[i].Uuid)
+	}
+	return gotPage
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/notify"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/gin-gonic/gin"
+)
+
+type changePasswordRequestUri struct {
+	Uuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+type changePasswordRequestJson struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// changePassword sets the new password of the authenticated user, the current password has to be given again
+// so a stolen access token alone isn't enough to take over the account. The wrong current passwords count
+// towards the lockout like failed logins. All the sessions and tokens of the user stop working,
+// a new session is returned instead so the user stays logged in here
+func (server *Server) changePassword(ctx *gin.Context) {
+	var reqUri changePasswordRequestUri
+	var reqJson changePasswordRequestJson
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	user, valid := server.validUser(ctx, reqUri.Uuid)
+	if !valid {
+		return
+	}
+	reserved, valid := server.reserveLoginAttempt(ctx, user.Username)
+	if !valid {
+		return
+	}
+	if err := util.CheckPassword(reqJson.CurrentPassword, user.HashedPassword); err != nil {
+		server.failedLogin(ctx, user.Username, failedLoginWrongPassword)
+		return
+	}
+	if !server.loginSucceeded(ctx, user.Username, reserved) {
+		return
+	}
+	hashedPassword, err := util.HashPassword(reqJson.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	user, err = server.store.ChangePasswordTx(ctx, db.ChangePasswordTxParams{
+		Uuid:           user.Uuid,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, notFoundResponse("user"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp, err := server.newSession(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	setETag(ctx, user.Version)
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type forgotPasswordRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+}
+
+const (
+	resetUsernameThrottlePrefix = "reset_username:"
+	resetIpThrottlePrefix       = "reset_ip:"
+)
+
+var errTooManyResetRequests = errors.New("too many password reset requests, try again later")
+
+// forgotPassword sends a reset token to the user. The response is the same whether the user exists or not,
+// so the endpoint can't be used to find out the usernames. Every request counts towards the throttles
+// of the username and of the client ip like a failed login, so nobody can flood a user with reset tokens
+func (server *Server) forgotPassword(ctx *gin.Context) {
+	var req forgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	result, err := server.store.ReserveLoginAttemptTx(ctx, db.ReserveLoginAttemptTxParams{
+		Limits: []db.LoginThrottleLimit{
+			{Key: resetUsernameThrottlePrefix + req.Username, MaxFailures: server.config.PasswordResetMaxRequests},
+			{Key: resetIpThrottlePrefix + ctx.ClientIP(), MaxFailures: server.config.PasswordResetMaxIPRequests},
+		},
+		ResetBefore: time.Now().Add(-server.config.LoginFailureWindow),
+		Lockout:     server.config.LoginLockoutDuration,
+		MaxLockout:  server.config.LoginMaxLockoutDuration,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrLoginLocked) {
+			tooManyRequests(ctx, result.LockedUntil, errTooManyResetRequests)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	user, err := server.store.GetUserByUserName(ctx, req.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusAccepted, forgotPasswordResponse())
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	// the token is created and sent in the background, so the response takes as long as for an unknown user
+	server.background.Add(1)
+	go func() {
+		defer server.background.Done()
+		if err := server.sendResetToken(context.Background(), user); err != nil {
+			log.Printf("cannot send the password reset token to %s: %v", user.Username, err)
+		}
+	}()
+	ctx.JSON(http.StatusAccepted, forgotPasswordResponse())
+}
+
+// sendResetToken creates a new reset token of the user and sends it to the user
+func (server *Server) sendResetToken(ctx context.Context, user db.User) error {
+	resetToken, tokenHash, err := newResetToken()
+	if err != nil {
+		return err
+	}
+	// only the hash is stored, so the tokens can't be used by someone who reads the db
+	token, err := server.store.CreatePasswordResetToken(ctx, db.CreatePasswordResetTokenParams{
+		UserUuid:  user.Uuid,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(server.config.PasswordResetTokenDuration),
+	})
+	if err != nil {
+		return err
+	}
+	return server.notifier.Send(ctx, notify.Message{
+		To:      user.Username,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Use the token to set a new password: %s\nThe token can be used once and expires at %s",
+			resetToken, token.ExpiresAt.Format(time.RFC3339)),
+	})
+}
+
+type resetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// resetPassword sets the new password with the token sent by forgotPassword. The sessions of the user are blocked,
+// so the user has to login again everywhere
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	hashedPassword, err := util.HashPassword(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	_, err = server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		TokenHash:      hashResetToken(req.Token),
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		if err == db.ErrInvalidResetToken {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"success": "Password was reset"})
+}
+
+func forgotPasswordResponse() gin.H {
+	return gin.H{"success": "If the user exists, a password reset token was sent"}
+}
+
+// newResetToken returns a random token for the user and the hash of it for the db
+func newResetToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashResetToken(token), nil
+}
+
+// hashResetToken doesn't need a salt like the passwords, since the tokens are random and long
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
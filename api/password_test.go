@@ -0,0 +1,486 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/alekseiapa/apple_store/db/mock"
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangePasswordAPI(t *testing.T) {
+	user, password := randomUser(t)
+	otherUser, _ := randomUser(t)
+	changed := user
+	changed.Version = user.Version + 1
+
+	// buildLoginSucceededStubs stubs the reservation of the attempt and the refund after the current password matched
+	buildLoginSucceededStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.ReserveLoginAttemptTxResult{}, nil)
+		store.EXPECT().
+			DeleteLoginThrottle(gomock.Any(), gomock.Eq("username:"+user.Username)).
+			Times(1).
+			Return(nil)
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			body:     fmt.Sprintf(`{"current_password": %q, "new_password": "secret123"}`, password),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				buildLoginSucceededStubs(store)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ChangePasswordTxParams) (db.User, error) {
+						require.Equal(t, user.Uuid, arg.Uuid)
+						require.NoError(t, util.CheckPassword("secret123", arg.HashedPassword))
+						return changed, nil
+					})
+				// the other sessions are blocked, so a new one is created for the user
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						require.Equal(t, user.Username, arg.Username)
+						return db.Session{Uuid: arg.Uuid, Username: arg.Username}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"2"`, recorder.Header().Get(etagHeaderKey))
+
+				var rsp loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotEmpty(t, rsp.AccessToken)
+				require.NotEmpty(t, rsp.RefreshToken)
+				require.NotEqual(t, uuid.Nil, rsp.SessionID)
+				require.Equal(t, changed.Username, rsp.User.Username)
+			},
+		},
+		{
+			name:     "WrongCurrentPassword",
+			username: user.Username,
+			body:     `{"current_password": "wrong-password", "new_password": "secret123"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReserveLoginAttemptTxResult{}, nil)
+				// the wrong password counts towards the lockout like a failed login
+				store.EXPECT().
+					CreateFailedLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateFailedLoginParams) (db.FailedLogin, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, failedLoginWrongPassword, arg.Reason)
+						return db.FailedLogin{}, nil
+					})
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "LockedOut",
+			username: user.Username,
+			body:     fmt.Sprintf(`{"current_password": %q, "new_password": "secret123"}`, password),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReserveLoginAttemptTxResult{LockedUntil: time.Now().Add(time.Minute)}, db.ErrLoginLocked)
+				store.EXPECT().
+					CreateFailedLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FailedLogin{}, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "60", recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name:     "OtherUser",
+			username: otherUser.Username,
+			body:     fmt.Sprintf(`{"current_password": %q, "new_password": "secret123"}`, password),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "ShortNewPassword",
+			username: user.Username,
+			body:     fmt.Sprintf(`{"current_password": %q, "new_password": "abc"}`, password),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: user.Username,
+			body:     fmt.Sprintf(`{"current_password": %q, "new_password": "secret123"}`, password),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				buildLoginSucceededStubs(store)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/users/%d/password", user.Uuid)
+			request, err := http.NewRequest(http.MethodPost, url, strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.CustomerRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestForgotPasswordAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	var storedHash string
+
+	testCases := []struct {
+		name          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder, notifier *recordingNotifier)
+	}{
+		{
+			name: "OK",
+			body: fmt.Sprintf(`{"username": %q}`, user.Username),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ReserveLoginAttemptTxParams) (db.ReserveLoginAttemptTxResult, error) {
+						// the requests are throttled apart from the logins
+						require.Equal(t, []db.LoginThrottleLimit{
+							{Key: "reset_username:" + user.Username, MaxFailures: 3},
+							{Key: "reset_ip:192.0.2.1", MaxFailures: 10},
+						}, arg.Limits)
+						return db.ReserveLoginAttemptTxResult{}, nil
+					})
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreatePasswordResetToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreatePasswordResetTokenParams) (db.PasswordResetToken, error) {
+						require.Equal(t, user.Uuid, arg.UserUuid)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiresAt, time.Minute)
+						storedHash = arg.TokenHash
+						return db.PasswordResetToken{
+							Uuid:      1,
+							UserUuid:  arg.UserUuid,
+							TokenHash: arg.TokenHash,
+							ExpiresAt: arg.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				messages := notifier.Messages()
+				require.Len(t, messages, 1)
+				require.Equal(t, user.Username, messages[0].To)
+
+				// the user gets the token itself, the db only has its hash
+				_, rest, found := strings.Cut(messages[0].Body, "new password: ")
+				require.True(t, found)
+				require.Equal(t, storedHash, hashResetToken(strings.Fields(rest)[0]))
+			},
+		},
+		{
+			name: "UnknownUser",
+			body: `{"username": "nobody"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubResetRequestAllowed(store)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq("nobody")).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreatePasswordResetToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				// the same response as for an existing user
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.JSONEq(t, `{"success": "If the user exists, a password reset token was sent"}`, recorder.Body.String())
+				require.Empty(t, notifier.Messages())
+			},
+		},
+		{
+			name: "InvalidUsername",
+			body: `{"username": "no body"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TooManyRequests",
+			body: fmt.Sprintf(`{"username": %q}`, user.Username),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReserveLoginAttemptTxResult{LockedUntil: time.Now().Add(time.Minute)}, db.ErrLoginLocked)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+				require.Empty(t, notifier.Messages())
+			},
+		},
+		{
+			name: "SendError",
+			body: fmt.Sprintf(`{"username": %q}`, user.Username),
+			buildStubs: func(store *mockdb.MockStore) {
+				stubResetRequestAllowed(store)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreatePasswordResetToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PasswordResetToken{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				// the token is sent in the background, so the response can't tell the user exists
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Empty(t, notifier.Messages())
+			},
+		},
+		{
+			name: "InternalError",
+			body: fmt.Sprintf(`{"username": %q}`, user.Username),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReserveLoginAttemptTxResult{}, sql.ErrConnDone)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *recordingNotifier) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/api/users/password/forgot", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			request.RemoteAddr = "192.0.2.1:1234"
+
+			server.router.ServeHTTP(recorder, request)
+			server.background.Wait()
+			tc.checkResponse(t, recorder, server.notifier.(*recordingNotifier))
+		})
+	}
+}
+
+// stubResetRequestAllowed lets the password reset request through the throttles
+func stubResetRequestAllowed(store *mockdb.MockStore) {
+	store.EXPECT().
+		ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.ReserveLoginAttemptTxResult{}, nil)
+}
+
+func TestResetPasswordAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	resetToken, tokenHash, err := newResetToken()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: fmt.Sprintf(`{"token": %q, "new_password": "secret123"}`, resetToken),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ResetPasswordTxParams) (db.User, error) {
+						// only the hash of the token reaches the db
+						require.Equal(t, tokenHash, arg.TokenHash)
+						require.NoError(t, util.CheckPassword("secret123", arg.HashedPassword))
+						return user, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidToken",
+			body: `{"token": "used-or-expired", "new_password": "secret123"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, db.ErrInvalidResetToken)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ShortNewPassword",
+			body: fmt.Sprintf(`{"token": %q, "new_password": "abc"}`, resetToken),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: fmt.Sprintf(`{"token": %q, "new_password": "secret123"}`, resetToken),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/api/users/password/reset", strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+)
+
+// nullableInt64 tells a JSON null apart from a missing field. In a merge patch the missing field stays the same
+// and null removes the field
+type nullableInt64 struct {
+	Set   bool
+	Null  bool
+	Value int64
+}
+
+func (n *nullableInt64) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &n.Value)
+}
+
+// nullString turns a field of a patch into a query argument. A missing field is a nil pointer,
+// which keeps the column as it is
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func nullInt16(n *int16) sql.NullInt16 {
+	if n == nil {
+		return sql.NullInt16{}
+	}
+	return sql.NullInt16{Int16: *n, Valid: true}
+}
+
+func nullInt32(n *int32) sql.NullInt32 {
+	if n == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *n, Valid: true}
+}
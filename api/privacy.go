@@ -0,0 +1,94 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/gin-gonic/gin"
+)
+
+type privacyResponse struct {
+	PurchaseVisibility string `json:"purchase_visibility"`
+	// UpdatedAt is missing until the user changes the defaults
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+func newPrivacyResponse(setting db.PrivacySetting) privacyResponse {
+	return privacyResponse{
+		PurchaseVisibility: setting.PurchaseVisibility,
+		UpdatedAt:          &setting.UpdatedAt,
+	}
+}
+
+type getPrivacyRequest struct {
+	UserUuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getPrivacy returns the privacy settings of the user, users who never changed them get the defaults,
+// which keep their purchases private
+func (server *Server) getPrivacy(ctx *gin.Context) {
+	var req getPrivacyRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, valid := server.validUser(ctx, req.UserUuid); !valid {
+		return
+	}
+
+	setting, err := server.store.GetPrivacySetting(ctx, req.UserUuid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusOK, privacyResponse{PurchaseVisibility: util.VisibilityPrivate})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newPrivacyResponse(setting))
+}
+
+type updatePrivacyRequestUri struct {
+	UserUuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+type updatePrivacyRequestJson struct {
+	PurchaseVisibility string `json:"purchase_visibility" binding:"required"`
+}
+
+// updatePrivacy changes who can see the purchases of the user in their feed
+func (server *Server) updatePrivacy(ctx *gin.Context) {
+	var reqUri updatePrivacyRequestUri
+	var reqJson updatePrivacyRequestJson
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if !util.IsSupportedVisibility(reqJson.PurchaseVisibility) {
+		err := errors.New("unsupported purchase visibility")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, valid := server.validUser(ctx, reqUri.UserUuid); !valid {
+		return
+	}
+
+	setting, err := server.store.UpsertPrivacySetting(ctx, db.UpsertPrivacySettingParams{
+		UserUuid:           reqUri.UserUuid,
+		PurchaseVisibility: reqJson.PurchaseVisibility,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newPrivacyResponse(setting))
+}
@@ -2,37 +2,67 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"log"
 	"net/http"
 
 	db "github.com/alekseiapa/apple_store/db/sqlc"
 	"github.com/alekseiapa/apple_store/util"
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
 type productResponse struct {
-	Uuid        int64   `json:"uuid"`
-	Price       float32 `json:"price"`
-	Currency    string  `json:"currency"`
-	InStock     int32   `json:"in_stock"`
-	Description string  `json:"description"`
+	Uuid         int64      `json:"uuid"`
+	Price        util.Money `json:"price"`
+	Currency     string     `json:"currency"`
+	InStock      int32      `json:"in_stock"`
+	Available    int32      `json:"available"`
+	Description  string     `json:"description"`
+	CategoryUuid int64      `json:"category_uuid,omitempty"`
+	// Images are only listed when the product is read
+	Images []productImageResponse `json:"images,omitempty"`
 }
 
-func newProductResponse(product db.Product, currency string, price float32) productResponse {
+func newProductResponse(product db.Product, currency string, price util.Money) productResponse {
 	return productResponse{
-		Uuid:        product.Uuid,
-		Price:       price,
-		InStock:     product.InStock,
-		Description: product.Description,
-		Currency:    currency,
+		Uuid:         product.Uuid,
+		Price:        price,
+		InStock:      product.InStock,
+		Available:    product.InStock,
+		Description:  product.Description,
+		Currency:     currency,
+		CategoryUuid: product.CategoryUuid.Int64,
 	}
 }
 
+// setAvailable takes the reserved pcs out of the pcs in stock. The stock can be reduced below the reservations
+// by an admin, then nothing is available
+func (rsp *productResponse) setAvailable(reserved int32) {
+	rsp.Available = rsp.InStock - reserved
+	if rsp.Available < 0 {
+		rsp.Available = 0
+	}
+}
+
+// productError tells the client the category of the product doesn't exist
+func productError(ctx *gin.Context, err error) {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code.Name() {
+		case "foreign_key_violation":
+			ctx.JSON(http.StatusNotFound, notFoundResponse("Category"))
+			return
+		}
+	}
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
+
 type createProductRequest struct {
-	Description string  `json:"description" binding:"required"`
-	Price       float32 `json:"price" binding:"required"`
-	InStock     int32   `json:"in_stock" binding:"required"`
-	Currency    string  `json:"currency" binding:"required,oneof=USD EUR RUB"`
+	Description  string     `json:"description" binding:"required"`
+	Price        util.Money `json:"price" binding:"required,gt=0"`
+	InStock      int32      `json:"in_stock" binding:"required"`
+	Currency     string     `json:"currency" binding:"required,len=3"`
+	CategoryUuid int64      `json:"category_uuid" binding:"omitempty,min=1"`
 }
 
 func (server *Server) createProduct(ctx *gin.Context) {
@@ -41,16 +71,20 @@ func (server *Server) createProduct(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-	convPrice := util.ConvertCur(req.Currency, "USD", req.Price)
+	convPrice, valid := server.convertCur(ctx, req.Currency, util.BaseCurrency, req.Price)
+	if !valid {
+		return
+	}
 	arg := db.CreateProductParams{
-		Description: req.Description,
-		Price:       convPrice,
-		InStock:     req.InStock,
+		Description:  req.Description,
+		Price:        convPrice,
+		InStock:      req.InStock,
+		CategoryUuid: sql.NullInt64{Int64: req.CategoryUuid, Valid: req.CategoryUuid != 0},
 	}
 	product, err := server.store.CreateProduct(ctx, arg)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		productError(ctx, err)
 		return
 	}
 	prodRespJson := newProductResponse(product, req.Currency, req.Price)
@@ -61,7 +95,7 @@ type getProductRequestUri struct {
 	Uuid int64 `uri:"id" binding:"required,min=1"`
 }
 type getProductRequestQuery struct {
-	Currency string `form:"currency" binding:"required,oneof=USD EUR RUB"`
+	Currency string `form:"currency" binding:"required,len=3"`
 }
 
 func (server *Server) getProduct(ctx *gin.Context) {
@@ -87,16 +121,51 @@ func (server *Server) getProduct(ctx *gin.Context) {
 		return
 	}
 
-	prodRespJson := newProductResponse(product, reqQuery.Currency, util.ConvertCur("USD", reqQuery.Currency, product.Price))
+	price, valid := server.convertCur(ctx, util.BaseCurrency, reqQuery.Currency, product.Price)
+	if !valid {
+		return
+	}
+	images, valid := server.listProductImages(ctx, []db.Product{product})
+	if !valid {
+		return
+	}
+	reserved, valid := server.listReservedQuantities(ctx, []db.Product{product})
+	if !valid {
+		return
+	}
+	prodRespJson := newProductResponse(product, reqQuery.Currency, price)
+	prodRespJson.Images = images[product.Uuid]
+	prodRespJson.setAvailable(reserved[product.Uuid])
+	setETag(ctx, product.Version)
 	ctx.JSON(http.StatusOK, prodRespJson)
 }
 
 type listProductRequest struct {
-	PageID   int32  `form:"page_id" binding:"required,min=1"`
-	PageSize int32  `form:"page_size" binding:"required,min=5"`
-	Currency string `form:"currency" binding:"required,oneof=USD EUR RUB"`
+	PageID       int32  `form:"page_id" binding:"omitempty,min=1"`
+	PageSize     int32  `form:"page_size" binding:"required,min=5,max=50"`
+	Currency     string `form:"currency" binding:"required,len=3"`
+	Cursor       string `form:"cursor"`
+	WithTotal    bool   `form:"with_total"`
+	Search       string `form:"search"`
+	MinPrice     string `form:"min_price"`
+	MaxPrice     string `form:"max_price"`
+	InStock      bool   `form:"in_stock"`
+	Sort         string `form:"sort" binding:"omitempty,oneof=price_asc price_desc name newest"`
+	CategoryUuid int64  `form:"category_uuid" binding:"omitempty,min=1"`
+}
+
+// filtered tells if the products are searched, filtered or sorted instead of just paged by the uuid
+func (req listProductRequest) filtered() bool {
+	return req.Search != "" || req.MinPrice != "" || req.MaxPrice != "" || req.InStock || req.Sort != "" ||
+		req.CategoryUuid != 0
+}
+
+type listProductResponse struct {
+	Items []productResponse `json:"items"`
+	listPage
 }
 
+// listProduct pages through the products by the cursor, the page_id keeps the old offset pages working
 func (server *Server) listProduct(ctx *gin.Context) {
 	var req listProductRequest
 	var respProducts []productResponse
@@ -104,6 +173,18 @@ func (server *Server) listProduct(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	if req.PageID != 0 && req.Cursor != "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errCursorWithPageID))
+		return
+	}
+	if req.filtered() {
+		server.searchProduct(ctx, req)
+		return
+	}
+	if req.PageID == 0 {
+		server.listProductByCursor(ctx, req)
+		return
+	}
 	arg := db.ListProductsParams{
 		Limit:  req.PageSize,
 		Offset: (req.PageID - 1) * req.PageSize,
@@ -113,22 +194,104 @@ func (server *Server) listProduct(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	images, valid := server.listProductImages(ctx, products)
+	if !valid {
+		return
+	}
+	reserved, valid := server.listReservedQuantities(ctx, products)
+	if !valid {
+		return
+	}
+	rate, valid := server.crossRate(ctx, util.BaseCurrency, req.Currency)
+	if !valid {
+		return
+	}
 	for _, product := range products {
-		respProduct := newProductResponse(product, req.Currency, util.ConvertCur("USD", req.Currency, product.Price))
+		price := product.Price.MulRat(rate)
+		respProduct := newProductResponse(product, req.Currency, price)
+		respProduct.Images = images[product.Uuid]
+		respProduct.setAvailable(reserved[product.Uuid])
 		respProducts = append(respProducts, respProduct)
 	}
 
 	ctx.JSON(http.StatusOK, respProducts)
 }
 
+func (server *Server) listProductByCursor(ctx *gin.Context, req listProductRequest) {
+	cursor, err := listCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	// one more product tells if there is another page
+	var products []db.Product
+	if cursor.Backward {
+		products, err = server.store.ListProductsBefore(ctx, db.ListProductsBeforeParams{
+			BeforeUuid: cursor.Uuid,
+			Limit:      req.PageSize + 1,
+		})
+	} else {
+		products, err = server.store.ListProductsAfter(ctx, db.ListProductsAfterParams{
+			AfterUuid: cursor.Uuid,
+			Limit:     req.PageSize + 1,
+		})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	more := len(products) > int(req.PageSize)
+	if more {
+		products = products[:req.PageSize]
+	}
+
+	images, valid := server.listProductImages(ctx, products)
+	if !valid {
+		return
+	}
+	reserved, valid := server.listReservedQuantities(ctx, products)
+	if !valid {
+		return
+	}
+	rate, valid := server.crossRate(ctx, util.BaseCurrency, req.Currency)
+	if !valid {
+		return
+	}
+	rsp := listProductResponse{Items: make([]productResponse, len(products))}
+	uuids := make([]int64, len(products))
+	for i, product := range products {
+		price := product.Price.MulRat(rate)
+		// the backward pages come in the reverse order
+		j := i
+		if cursor.Backward {
+			j = len(products) - 1 - i
+		}
+		rsp.Items[j] = newProductResponse(product, req.Currency, price)
+		rsp.Items[j].Images = images[product.Uuid]
+		rsp.Items[j].setAvailable(reserved[product.Uuid])
+		uuids[j] = product.Uuid
+	}
+	rsp.listPage = newListPage(cursor, uuids, more)
+	if req.WithTotal {
+		total, err := server.store.CountProducts(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		rsp.Total = &total
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
 type updateProductRequestUri struct {
 	Uuid int64 `uri:"id" binding:"required,min=1"`
 }
 type updateProductRequestJson struct {
-	Description string  `json:"description" binding:"required"`
-	Price       float32 `json:"price" binding:"required"`
-	InStock     int32   `json:"in_stock" binding:"required"`
-	Currency    string  `json:"currency" binding:"required,oneof=USD EUR RUB"`
+	Description  string     `json:"description" binding:"required"`
+	Price        util.Money `json:"price" binding:"required,gt=0"`
+	InStock      int32      `json:"in_stock" binding:"required"`
+	Currency     string     `json:"currency" binding:"required,len=3"`
+	CategoryUuid int64      `json:"category_uuid" binding:"omitempty,min=1"`
 }
 
 func (server *Server) updateProduct(ctx *gin.Context) {
@@ -144,25 +307,116 @@ func (server *Server) updateProduct(ctx *gin.Context) {
 		return
 	}
 
+	version, valid := ifMatchVersion(ctx)
+	if !valid {
+		return
+	}
+	convPrice, valid := server.convertCur(ctx, reqJson.Currency, util.BaseCurrency, reqJson.Price)
+	if !valid {
+		return
+	}
 	arg := db.UpdateProductParams{
-		Uuid:        reqUri.Uuid,
-		Description: reqJson.Description,
-		Price:       util.ConvertCur(reqJson.Currency, "USD", reqJson.Price),
-		InStock:     reqJson.InStock,
+		Uuid:         reqUri.Uuid,
+		Description:  reqJson.Description,
+		Price:        convPrice,
+		InStock:      reqJson.InStock,
+		CategoryUuid: sql.NullInt64{Int64: reqJson.CategoryUuid, Valid: reqJson.CategoryUuid != 0},
+		Version:      version,
 	}
 	product, err := server.store.UpdateProduct(ctx, arg)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			server.productGone(ctx, reqUri.Uuid, version)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		productError(ctx, err)
 		return
 	}
 	log.Println(product.InStock)
-	prodRespJson := newProductResponse(product, reqJson.Currency, util.ConvertCur("USD", reqJson.Currency, product.Price))
+	server.changedProductResponse(ctx, product, reqJson.Currency)
+}
+
+type patchProductRequestJson struct {
+	Description *string     `json:"description" binding:"omitempty,min=1,max=256"`
+	Price       *util.Money `json:"price" binding:"omitempty,gt=0"`
+	InStock     *int32      `json:"in_stock" binding:"omitempty,min=0"`
+	// Currency of the price, the product is sent back in the base currency when it is empty
+	Currency string `json:"currency" binding:"required_with=Price,omitempty,len=3"`
+	// CategoryUuid removes the product from its category when it is null
+	CategoryUuid nullableInt64 `json:"category_uuid"`
+}
+
+var errInvalidCategory = errors.New("category_uuid must be positive or null")
+
+// patchProduct only changes the fields of the product that are in the request (JSON Merge Patch)
+func (server *Server) patchProduct(ctx *gin.Context) {
+	var reqUri updateProductRequestUri
+	var reqJson patchProductRequestJson
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	category := reqJson.CategoryUuid
+	if category.Set && !category.Null && category.Value < 1 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errInvalidCategory))
+		return
+	}
+	currency := reqJson.Currency
+	if currency == "" {
+		currency = util.BaseCurrency
+	}
+
+	version, valid := ifMatchVersion(ctx)
+	if !valid {
+		return
+	}
+	arg := db.PatchProductParams{
+		Uuid:          reqUri.Uuid,
+		Description:   nullString(reqJson.Description),
+		InStock:       nullInt32(reqJson.InStock),
+		ClearCategory: category.Null,
+		CategoryUuid:  sql.NullInt64{Int64: category.Value, Valid: category.Set && !category.Null},
+		Version:       version,
+	}
+	if reqJson.Price != nil {
+		convPrice, valid := server.convertCur(ctx, currency, util.BaseCurrency, *reqJson.Price)
+		if !valid {
+			return
+		}
+		arg.Price = sql.NullInt64{Int64: int64(convPrice), Valid: true}
+	}
+	product, err := server.store.PatchProduct(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			server.productGone(ctx, reqUri.Uuid, version)
+			return
+		}
+		productError(ctx, err)
+		return
+	}
+	server.changedProductResponse(ctx, product, currency)
+}
 
+// changedProductResponse sends back the product after a change with its new version
+func (server *Server) changedProductResponse(ctx *gin.Context, product db.Product, currency string) {
+	price, valid := server.convertCur(ctx, util.BaseCurrency, currency, product.Price)
+	if !valid {
+		return
+	}
+	reserved, valid := server.listReservedQuantities(ctx, []db.Product{product})
+	if !valid {
+		return
+	}
+	prodRespJson := newProductResponse(product, currency, price)
+	prodRespJson.setAvailable(reserved[product.Uuid])
+
+	setETag(ctx, product.Version)
 	ctx.JSON(http.StatusOK, prodRespJson)
 }
 
@@ -177,7 +431,14 @@ func (server *Server) deleteProduct(ctx *gin.Context) {
 		return
 	}
 
-	r, err := server.store.DeleteProduct(ctx, req.Uuid)
+	version, valid := ifMatchVersion(ctx)
+	if !valid {
+		return
+	}
+	r, err := server.store.DeleteProduct(ctx, db.DeleteProductParams{
+		Uuid:    req.Uuid,
+		Version: version,
+	})
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
@@ -187,9 +448,26 @@ func (server *Server) deleteProduct(ctx *gin.Context) {
 		return
 	}
 	if r == 0 {
-		ctx.JSON(http.StatusNotFound, notFoundResponse("product"))
+		server.productGone(ctx, req.Uuid, version)
 		return
 	}
 	ctx.JSON(http.StatusOK, successDeleteResponse())
 
 }
+
+// productGone responds to an update or a delete that found no product with the version,
+// the product either doesn't exist or was changed by someone else
+func (server *Server) productGone(ctx *gin.Context, uuid int64, version sql.NullInt32) {
+	if version.Valid {
+		_, err := server.store.GetProduct(ctx, uuid)
+		if err == nil {
+			ctx.JSON(http.StatusPreconditionFailed, errorResponse(errVersionMismatch))
+			return
+		}
+		if err != sql.ErrNoRows {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+	ctx.JSON(http.StatusNotFound, notFoundResponse("product"))
+}
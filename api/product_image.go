@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/imaging"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	// maxImageSize is the biggest image the admins can upload
+	maxImageSize = 10 << 20
+	// maxImagePixels is the most pixels an image can have, a small file can still decode into a huge image
+	maxImagePixels = 50_000_000
+	// thumbnailSize is the size of the square the thumbnails fit into
+	thumbnailSize = 256
+)
+
+var errUnsupportedImage = errors.New("unsupported image, only JPEG and PNG images are accepted")
+
+type productImageResponse struct {
+	Uuid         int64  `json:"uuid"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+func (server *Server) newProductImageResponse(image db.ProductImage) productImageResponse {
+	return productImageResponse{
+		Uuid:         image.Uuid,
+		URL:          server.storage.URL(image.Key),
+		ThumbnailURL: server.storage.URL(image.ThumbnailKey),
+	}
+}
+
+// listProductImages reads the images of all the products at once, so a page doesn't query them product by product
+func (server *Server) listProductImages(ctx *gin.Context, products []db.Product) (map[int64][]productImageResponse, bool) {
+	images := map[int64][]productImageResponse{}
+	if len(products) == 0 {
+		return images, true
+	}
+	uuids := make([]int64, len(products))
+	for i, product := range products {
+		uuids[i] = product.Uuid
+	}
+	rows, err := server.store.ListProductImages(ctx, uuids)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+	for _, row := range rows {
+		images[row.ProductUuid] = append(images[row.ProductUuid], server.newProductImageResponse(row))
+	}
+	return images, true
+}
+
+type uploadProductImageRequest struct {
+	ProductUuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+// uploadProductImage stores the image sent in the "image" field of the multipart form together with its thumbnail.
+// Only JPEG and PNG images are accepted, the thumbnail keeps the format of the image
+func (server *Server) uploadProductImage(ctx *gin.Context) {
+	var req uploadProductImageRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	// the rest of the form is small next to the image
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImageSize+1<<20)
+	file, err := ctx.FormFile("image")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if file.Size > maxImageSize {
+		err := fmt.Errorf("the image is bigger than %d MB", maxImageSize>>20)
+		ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(err))
+		return
+	}
+	if _, err := server.store.GetProduct(ctx, req.ProductUuid); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, notFoundResponse("Product"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	// the size is read from the header first, so an image too big to decode isn't decoded at all
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errUnsupportedImage))
+		return
+	}
+	if int64(config.Width)*int64(config.Height) > maxImagePixels {
+		err := fmt.Errorf("the image has more than %d megapixels", maxImagePixels/1_000_000)
+		ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(err))
+		return
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errUnsupportedImage))
+		return
+	}
+	var thumbnail bytes.Buffer
+	var ext string
+	switch format {
+	case "jpeg":
+		ext = "jpg"
+		err = jpeg.Encode(&thumbnail, imaging.Thumbnail(img, thumbnailSize), nil)
+	case "png":
+		ext = "png"
+		err = png.Encode(&thumbnail, imaging.Thumbnail(img, thumbnailSize))
+	default:
+		err = errUnsupportedImage
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	name := uuid.New().String()
+	key := fmt.Sprintf("products/%d/%s.%s", req.ProductUuid, name, ext)
+	thumbnailKey := fmt.Sprintf("products/%d/%s_thumb.%s", req.ProductUuid, name, ext)
+	if err := server.storage.Put(ctx, key, bytes.NewReader(data)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if err := server.storage.Put(ctx, thumbnailKey, &thumbnail); err != nil {
+		server.storage.Delete(ctx, key)
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	productImage, err := server.store.CreateProductImage(ctx, db.CreateProductImageParams{
+		ProductUuid:  req.ProductUuid,
+		Key:          key,
+		ThumbnailKey: thumbnailKey,
+	})
+	if err != nil {
+		// the files aren't left behind without an image pointing to them
+		server.storage.Delete(ctx, key)
+		server.storage.Delete(ctx, thumbnailKey)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			ctx.JSON(http.StatusNotFound, notFoundResponse("Product"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, server.newProductImageResponse(productImage))
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/alekseiapa/apple_store/db/mock"
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadProductImageAPI(t *testing.T) {
+	product := randomProduct()
+
+	var pngImage bytes.Buffer
+	err := png.Encode(&pngImage, image.NewRGBA(image.Rect(0, 0, 600, 300)))
+	require.NoError(t, err)
+	hugeImage := pngWithSize(t, pngImage.Bytes(), 100_000, 100_000)
+
+	testCases := []struct {
+		name          string
+		field         string
+		content       []byte
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			field:   "image",
+			content: pngImage.Bytes(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
+					Times(1).
+					Return(product, nil)
+				store.EXPECT().
+					CreateProductImage(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateProductImageParams) (db.ProductImage, error) {
+						require.Equal(t, product.Uuid, arg.ProductUuid)
+						require.Regexp(t, fmt.Sprintf(`^products/%d/[0-9a-f-]+\.png$`, product.Uuid), arg.Key)
+						require.Regexp(t, fmt.Sprintf(`^products/%d/[0-9a-f-]+_thumb\.png$`, product.Uuid), arg.ThumbnailKey)
+						return db.ProductImage{
+							Uuid:         1,
+							ProductUuid:  arg.ProductUuid,
+							Key:          arg.Key,
+							ThumbnailKey: arg.ThumbnailKey,
+							CreatedAt:    time.Now(),
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Contains(t, recorder.Body.String(), fmt.Sprintf(`"url":"/media/products/%d/`, product.Uuid))
+			},
+		},
+		{
+			name:    "UnsupportedImage",
+			field:   "image",
+			content: []byte("not an image"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
+					Times(1).
+					Return(product, nil)
+				store.EXPECT().
+					CreateProductImage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "TooManyPixels",
+			field:   "image",
+			content: hugeImage,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
+					Times(1).
+					Return(product, nil)
+				store.EXPECT().
+					CreateProductImage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			},
+		},
+		{
+			name:    "ProductNotFound",
+			field:   "image",
+			content: pngImage.Bytes(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
+					Times(1).
+					Return(db.Product{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateProductImage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:    "MissingImage",
+			field:   "file",
+			content: pngImage.Bytes(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			part, err := writer.CreateFormFile(tc.field, "image.png")
+			require.NoError(t, err)
+			_, err = part.Write(tc.content)
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			url := fmt.Sprintf("/api/products/%d/images", product.Uuid)
+			request, err := http.NewRequest(http.MethodPost, url, &body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// pngWithSize changes the size in the header of the png, so a small file claims to hold a huge image
+func pngWithSize(t *testing.T, data []byte, width uint32, height uint32) []byte {
+	// the signature is followed by the IHDR chunk: its length, type, width, height, the rest of the header and a crc
+	resized := append([]byte{}, data...)
+	require.Equal(t, "IHDR", string(resized[12:16]))
+	binary.BigEndian.PutUint32(resized[16:20], width)
+	binary.BigEndian.PutUint32(resized[20:24], height)
+	binary.BigEndian.PutUint32(resized[29:33], crc32.ChecksumIEEE(resized[12:29]))
+
+	config, err := png.DecodeConfig(bytes.NewReader(resized))
+	require.NoError(t, err)
+	require.Equal(t, int(width), config.Width)
+	return resized
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/gin-gonic/gin"
+)
+
+// searchProduct lists the products matching the full-text search and the filters in the requested sort.
+// The price range is given in the requested currency, the newest products are the ones created last.
+// The sorted pages can only be followed forward, so they come with the next cursor only
+func (server *Server) searchProduct(ctx *gin.Context, req listProductRequest) {
+	filter := db.CountSearchProductsParams{
+		Search:       sql.NullString{String: req.Search, Valid: req.Search != ""},
+		InStock:      req.InStock,
+		CategoryUuid: sql.NullInt64{Int64: req.CategoryUuid, Valid: req.CategoryUuid != 0},
+	}
+	var valid bool
+	if filter.MinPrice, valid = server.priceBound(ctx, req.MinPrice, req.Currency); !valid {
+		return
+	}
+	if filter.MaxPrice, valid = server.priceBound(ctx, req.MaxPrice, req.Currency); !valid {
+		return
+	}
+	arg := db.SearchProductsParams{
+		Search:       filter.Search,
+		MinPrice:     filter.MinPrice,
+		MaxPrice:     filter.MaxPrice,
+		InStock:      filter.InStock,
+		CategoryUuid: filter.CategoryUuid,
+		Sort:         req.Sort,
+		Limit:        req.PageSize,
+	}
+
+	if req.PageID != 0 {
+		arg.Offset = (req.PageID - 1) * req.PageSize
+		products, err := server.store.SearchProducts(ctx, arg)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		images, valid := server.listProductImages(ctx, products)
+		if !valid {
+			return
+		}
+		reserved, valid := server.listReservedQuantities(ctx, products)
+		if !valid {
+			return
+		}
+		rate, valid := server.crossRate(ctx, util.BaseCurrency, req.Currency)
+		if !valid {
+			return
+		}
+		rsp := []productResponse{}
+		for _, product := range products {
+			price := product.Price.MulRat(rate)
+			respProduct := newProductResponse(product, req.Currency, price)
+			respProduct.Images = images[product.Uuid]
+			respProduct.setAvailable(reserved[product.Uuid])
+			rsp = append(rsp, respProduct)
+		}
+		ctx.JSON(http.StatusOK, rsp)
+		return
+	}
+
+	cursor, err := listCursor(req.Cursor)
+	if err == nil && cursor.Uuid != 0 && (cursor.Backward || cursor.Sort != req.Sort) {
+		err = errInvalidCursor
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if cursor.Uuid != 0 {
+		arg.AfterUuid = sql.NullInt64{Int64: cursor.Uuid, Valid: true}
+		arg.AfterPrice = sql.NullInt64{Int64: int64(cursor.Price), Valid: true}
+		arg.AfterName = sql.NullString{String: cursor.Name, Valid: true}
+	}
+	// one more product tells if there is another page
+	arg.Limit = req.PageSize + 1
+	products, err := server.store.SearchProducts(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	more := len(products) > int(req.PageSize)
+	if more {
+		products = products[:req.PageSize]
+	}
+
+	images, valid := server.listProductImages(ctx, products)
+	if !valid {
+		return
+	}
+	reserved, valid := server.listReservedQuantities(ctx, products)
+	if !valid {
+		return
+	}
+	rate, valid := server.crossRate(ctx, util.BaseCurrency, req.Currency)
+	if !valid {
+		return
+	}
+	rsp := listProductResponse{Items: []productResponse{}}
+	for _, product := range products {
+		price := product.Price.MulRat(rate)
+		respProduct := newProductResponse(product, req.Currency, price)
+		respProduct.Images = images[product.Uuid]
+		respProduct.setAvailable(reserved[product.Uuid])
+		rsp.Items = append(rsp.Items, respProduct)
+	}
+	if more {
+		rsp.NextCursor = newSearchCursor(products[len(products)-1], req.Sort).encode()
+	}
+	if req.WithTotal {
+		total, err := server.store.CountSearchProducts(ctx, filter)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		rsp.Total = &total
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// newSearchCursor points to the product with the key it is sorted by
+func newSearchCursor(product db.Product, sort string) pageCursor {
+	cursor := pageCursor{Uuid: product.Uuid, Sort: sort}
+	switch sort {
+	case "price_asc", "price_desc":
+		cursor.Price = product.Price
+	case "name":
+		cursor.Name = product.Description
+	}
+	return cursor
+}
+
+// priceBound parses a bound of the price range and converts it from the currency to the base one
+func (server *Server) priceBound(ctx *gin.Context, bound string, currency string) (sql.NullInt64, bool) {
+	if bound == "" {
+		return sql.NullInt64{}, true
+	}
+	price, err := util.ParseMoney(bound)
+	if err == nil && price < 0 {
+		err = fmt.Errorf("invalid price: %q", bound)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return sql.NullInt64{}, false
+	}
+	converted, valid := server.convertCur(ctx, currency, util.BaseCurrency, price)
+	if !valid {
+		return sql.NullInt64{}, false
+	}
+	return sql.NullInt64{Int64: int64(converted), Valid: true}, true
+}
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/alekseiapa/apple_store/db/mock"
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchProductAPI(t *testing.T) {
+	products := make([]db.Product, 6)
+	for i := range products {
+		products[i] = randomProduct()
+	}
+	cursor := newSearchCursor(products[0], "price_asc")
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "FirstPage",
+			query: "page_size=5&currency=EUR&search=iphone&min_price=9.50&max_price=950&in_stock=true&sort=price_asc&with_total=true",
+			buildStubs: func(store *mockdb.MockStore) {
+				// the price range is converted from EUR to USD
+				arg := db.SearchProductsParams{
+					Search:   sql.NullString{String: "iphone", Valid: true},
+					MinPrice: sql.NullInt64{Int64: 1000, Valid: true},
+					MaxPrice: sql.NullInt64{Int64: 100000, Valid: true},
+					InStock:  true,
+					Sort:     "price_asc",
+					Limit:    6,
+				}
+				store.EXPECT().
+					SearchProducts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(products, nil)
+				store.EXPECT().
+					ListProductImages(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ProductImage{}, nil)
+				store.EXPECT().
+					ListReservedQuantities(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListReservedQuantitiesRow{}, nil)
+				filter := db.CountSearchProductsParams{
+					Search:   arg.Search,
+					MinPrice: arg.MinPrice,
+					MaxPrice: arg.MaxPrice,
+					InStock:  true,
+				}
+				store.EXPECT().
+					CountSearchProducts(gomock.Any(), gomock.Eq(filter)).
+					Times(1).
+					Return(int64(len(products)), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				rsp := requireBodyMatchProductPage(t, recorder.Body, products[:5])
+				for i, item := range rsp.Items {
+					require.Equal(t, products[i].Price.MulRatio(95, 100), item.Price)
+					require.Equal(t, "EUR", item.Currency)
+				}
+				require.Equal(t, newSearchCursor(products[4], "price_asc").encode(), rsp.NextCursor)
+				require.Empty(t, rsp.PrevCursor)
+				require.NotNil(t, rsp.Total)
+				require.Equal(t, int64(len(products)), *rsp.Total)
+			},
+		},
+		{
+			name:  "NextPage",
+			query: "page_size=5&currency=USD&sort=price_asc&cursor=" + cursor.encode(),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchProductsParams{
+					Sort:       "price_asc",
+					AfterUuid:  sql.NullInt64{Int64: products[0].Uuid, Valid: true},
+					AfterPrice: sql.NullInt64{Int64: int64(products[0].Price), Valid: true},
+					AfterName:  sql.NullString{Valid: true},
+					Limit:      6,
+				}
+				store.EXPECT().
+					SearchProducts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(products[1:], nil)
+				store.EXPECT().
+					ListProductImages(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ProductImage{}, nil)
+				store.EXPECT().
+					ListReservedQuantities(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListReservedQuantitiesRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				rsp := requireBodyMatchProductPage(t, recorder.Body, products[1:])
+				require.Empty(t, rsp.NextCursor)
+				require.Nil(t, rsp.Total)
+			},
+		},
+		{
+			name:  "PageID",
+			query: "page_id=2&page_size=5&currency=USD&sort=newest",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchProductsParams{
+					Sort:   "newest",
+					Limit:  5,
+					Offset: 5,
+				}
+				store.EXPECT().
+					SearchProducts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(products[5:], nil)
+				store.EXPECT().
+					ListProductImages(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ProductImage{}, nil)
+				store.EXPECT().
+					ListReservedQuantities(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListReservedQuantitiesRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "Category",
+			query: "page_size=5&currency=USD&category_uuid=3",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchProductsParams{
+					CategoryUuid: sql.NullInt64{Int64: 3, Valid: true},
+					Limit:        6,
+				}
+				store.EXPECT().
+					SearchProducts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(products[:2], nil)
+				store.EXPECT().
+					ListProductImages(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ProductImage{}, nil)
+				store.EXPECT().
+					ListReservedQuantities(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListReservedQuantitiesRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchProductPage(t, recorder.Body, products[:2])
+			},
+		},
+		{
+			name:  "CursorOfAnotherSort",
+			query: "page_size=5&currency=USD&sort=name&cursor=" + cursor.encode(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchProducts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidSort",
+			query: "page_size=5&currency=USD&sort=rating",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchProducts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidPrice",
+			query: "page_size=5&currency=USD&min_price=-1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchProducts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "UnsupportedCurrency",
+			query: "page_size=5&currency=XXX&max_price=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchProducts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "PageSizeTooLarge",
+			query: "page_size=51&currency=USD&in_stock=true",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchProducts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "page_size=5&currency=USD&in_stock=true",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchProducts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Product{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/api/products?" + tc.query
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// countingRateProvider counts how many times the rates are read
+type countingRateProvider struct {
+	util.RateProvider
+	reads int
+}
+
+func (provider *countingRateProvider) Rate(ctx context.Context, currency string) (*big.Rat, error) {
+	provider.reads++
+	return provider.RateProvider.Rate(ctx, currency)
+}
+
+func TestListProductReadsRatesOnce(t *testing.T) {
+	products := make([]db.Product, 5)
+	for i := range products {
+		products[i] = randomProduct()
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListProductsAfter(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(products, nil)
+	store.EXPECT().
+		ListProductImages(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.ProductImage{}, nil)
+	store.EXPECT().
+		ListReservedQuantities(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.ListReservedQuantitiesRow{}, nil)
+
+	server := NewTestServer(t, store)
+	rates := &countingRateProvider{RateProvider: server.rates}
+	server.rates = rates
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/products?page_size=5&currency=EUR", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	requireBodyMatchProductPage(t, recorder.Body, products)
+	// the rates of USD and EUR for the whole page
+	require.Equal(t, 2, rates.reads)
+}
+
+func requireBodyMatchProductPage(t *testing.T, body *bytes.Buffer, products []db.Product) listProductResponse {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotPage listProductResponse
+	err = json.Unmarshal(data, &gotPage)
+	require.NoError(t, err)
+	require.Len(t, gotPage.Items, len(products))
+	for i, product := range products {
+		require.Equal(t, product.Uuid, gotPage.Items[i].Uuid)
+	}
+	return gotPage
+}
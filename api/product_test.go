@@ -10,11 +10,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	mockdb "github.com/alekseiapa/apple_store/db/mock"
 	db "github.com/alekseiapa/apple_store/db/sqlc"
 	"github.com/alekseiapa/apple_store/util"
 	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
@@ -35,9 +37,27 @@ func TestGetProductAPI(t *testing.T) {
 					GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
 					Times(1).
 					Return(product, nil)
+				images := []db.ProductImage{{
+					Uuid:         1,
+					ProductUuid:  product.Uuid,
+					Key:          "products/1/image.jpg",
+					ThumbnailKey: "products/1/image_thumb.jpg",
+				}}
+				store.EXPECT().
+					ListProductImages(gomock.Any(), gomock.Eq([]int64{product.Uuid})).
+					Times(1).
+					Return(images, nil)
+				reserved := []db.ListReservedQuantitiesRow{{ProductUuid: product.Uuid, Reserved: 1}}
+				store.EXPECT().
+					ListReservedQuantities(gomock.Any(), gomock.Eq([]int64{product.Uuid})).
+					Times(1).
+					Return(reserved, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"thumbnail_url":"/media/products/1/image_thumb.jpg"`)
+				require.Contains(t, recorder.Body.String(), fmt.Sprintf(`"available":%d`, product.InStock-1))
+				require.Equal(t, `"1"`, recorder.Header().Get(etagHeaderKey))
 				requireBodyMatchProduct(t, recorder.Body, product)
 			},
 		},
@@ -108,12 +128,482 @@ func TestGetProductAPI(t *testing.T) {
 
 }
 
+func TestCreateProductAPI(t *testing.T) {
+	product := randomProduct()
+
+	testCases := []struct {
+		name          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ExactPrice",
+			// 10.99 EUR are 11.5684... USD at the rate of 0.95 EUR per USD, which is rounded once to the nearest cent
+			body: fmt.Sprintf(`{"description": %q, "price": 10.99, "in_stock": %d, "currency": "EUR"}`, product.Description, product.InStock),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateProductParams{
+					Description: product.Description,
+					Price:       1157,
+					InStock:     product.InStock,
+				}
+				store.EXPECT().
+					CreateProduct(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(product, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"price":10.99`)
+			},
+		},
+		{
+			name: "CategoryNotFound",
+			body: fmt.Sprintf(`{"description": %q, "price": 10, "in_stock": %d, "currency": "USD", "category_uuid": 1}`, product.Description, product.InStock),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateProductParams{
+					Description:  product.Description,
+					Price:        1000,
+					InStock:      product.InStock,
+					CategoryUuid: sql.NullInt64{Int64: 1, Valid: true},
+				}
+				store.EXPECT().
+					CreateProduct(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.Product{}, &pq.Error{Code: "23503"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "FractionOfCent",
+			body: fmt.Sprintf(`{"description": %q, "price": 10.999, "in_stock": %d, "currency": "USD"}`, product.Description, product.InStock),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateProduct(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NegativePrice",
+			body: fmt.Sprintf(`{"description": %q, "price": -10, "in_stock": %d, "currency": "USD"}`, product.Description, product.InStock),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateProduct(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/api/products", bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateProductAPI(t *testing.T) {
+	product := randomProduct()
+	updated := product
+	updated.Version = product.Version + 1
+	body := fmt.Sprintf(`{"description": %q, "price": 10, "in_stock": %d, "currency": "USD"}`, product.Description, product.InStock)
+
+	testCases := []struct {
+		name          string
+		ifMatch       string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			ifMatch: `"1"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateProductParams{
+					Uuid:        product.Uuid,
+					Description: product.Description,
+					Price:       1000,
+					InStock:     product.InStock,
+					Version:     sql.NullInt32{Int32: 1, Valid: true},
+				}
+				store.EXPECT().
+					UpdateProduct(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(updated, nil)
+				store.EXPECT().
+					ListReservedQuantities(gomock.Any(), gomock.Eq([]int64{product.Uuid})).
+					Times(1).
+					Return([]db.ListReservedQuantitiesRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"2"`, recorder.Header().Get(etagHeaderKey))
+			},
+		},
+		{
+			name:    "VersionMismatch",
+			ifMatch: `"1"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateProduct(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Product{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "NotFound",
+			ifMatch: `"1"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateProduct(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Product{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
+					Times(1).
+					Return(db.Product{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:    "NoIfMatch",
+			ifMatch: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateProduct(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+		{
+			name:    "InvalidIfMatch",
+			ifMatch: `"one"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateProduct(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/products/%d", product.Uuid)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader([]byte(body)))
+			require.NoError(t, err)
+			request.Header.Set(ifMatchHeaderKey, tc.ifMatch)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPatchProductAPI(t *testing.T) {
+	product := randomProduct()
+	patched := product
+	patched.Version = product.Version + 1
+
+	testCases := []struct {
+		name          string
+		ifMatch       string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "Price",
+			ifMatch: `"1"`,
+			// 9.5 EUR are 10 USD at the rate of 0.95 EUR per USD
+			body: `{"price": 9.5, "currency": "EUR"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.PatchProductParams{
+					Uuid:    product.Uuid,
+					Price:   sql.NullInt64{Int64: 1000, Valid: true},
+					Version: sql.NullInt32{Int32: 1, Valid: true},
+				}
+				store.EXPECT().
+					PatchProduct(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(patched, nil)
+				store.EXPECT().
+					ListReservedQuantities(gomock.Any(), gomock.Eq([]int64{product.Uuid})).
+					Times(1).
+					Return([]db.ListReservedQuantitiesRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"2"`, recorder.Header().Get(etagHeaderKey))
+				require.Contains(t, recorder.Body.String(), `"currency":"EUR"`)
+			},
+		},
+		{
+			name:    "ClearCategory",
+			ifMatch: "*",
+			body:    `{"in_stock": 0, "category_uuid": null}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.PatchProductParams{
+					Uuid:          product.Uuid,
+					InStock:       sql.NullInt32{Int32: 0, Valid: true},
+					ClearCategory: true,
+				}
+				store.EXPECT().
+					PatchProduct(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(patched, nil)
+				store.EXPECT().
+					ListReservedQuantities(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListReservedQuantitiesRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"currency":"USD"`)
+			},
+		},
+		{
+			name:    "CategoryNotFound",
+			ifMatch: "*",
+			body:    `{"category_uuid": 7}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.PatchProductParams{
+					Uuid:         product.Uuid,
+					CategoryUuid: sql.NullInt64{Int64: 7, Valid: true},
+				}
+				store.EXPECT().
+					PatchProduct(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.Product{}, &pq.Error{Code: "23503"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:    "PriceWithoutCurrency",
+			ifMatch: "*",
+			body:    `{"price": 10}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PatchProduct(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "InvalidCategory",
+			ifMatch: "*",
+			body:    `{"category_uuid": 0}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PatchProduct(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoIfMatch",
+			body: `{"in_stock": 0}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PatchProduct(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/products/%d", product.Uuid)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+			if tc.ifMatch != "" {
+				request.Header.Set(ifMatchHeaderKey, tc.ifMatch)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteProductAPI(t *testing.T) {
+	product := randomProduct()
+
+	testCases := []struct {
+		name          string
+		ifMatch       string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			ifMatch: "*",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteProduct(gomock.Any(), gomock.Eq(db.DeleteProductParams{Uuid: product.Uuid})).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "VersionMismatch",
+			ifMatch: `"3"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DeleteProductParams{
+					Uuid:    product.Uuid,
+					Version: sql.NullInt32{Int32: 3, Valid: true},
+				}
+				store.EXPECT().
+					DeleteProduct(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
+					Times(1).
+					Return(product, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "NotFound",
+			ifMatch: "*",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteProduct(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NoIfMatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteProduct(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/products/%d", product.Uuid)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+			if tc.ifMatch != "" {
+				request.Header.Set(ifMatchHeaderKey, tc.ifMatch)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func randomProduct() db.Product {
 	return db.Product{
 		Uuid:        int64(util.RandomInt(1, 1000)),
 		Description: util.RandomProductDescription(),
 		Price:       util.RandomProductPrice(),
 		InStock:     util.RandomProductInStock(),
+		Version:     1,
 	}
 }
 
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+type variantResponse struct {
+	Uuid        int64     `json:"uuid"`
+	ProductUuid int64     `json:"product_uuid"`
+	Model       string    `json:"model"`
+	Color       string    `json:"color,omitempty"`
+	StorageGb   int32     `json:"storage_gb,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func newVariantResponse(variant db.ProductVariant) variantResponse {
+	return variantResponse{
+		Uuid:        variant.Uuid,
+		ProductUuid: variant.ProductUuid,
+		Model:       variant.Model,
+		Color:       variant.Color,
+		StorageGb:   variant.StorageGb,
+		CreatedAt:   variant.CreatedAt,
+	}
+}
+
+// variantError maps the constraints of the variants, a product can't have the same variant twice
+func variantError(ctx *gin.Context, err error) {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			err := errors.New("the product already has this variant")
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		case "foreign_key_violation":
+			ctx.JSON(http.StatusNotFound, notFoundResponse("Product"))
+			return
+		}
+	}
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
+
+type listVariantRequest struct {
+	ProductUuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) listVariant(ctx *gin.Context) {
+	var req listVariantRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, err := server.store.GetProduct(ctx, req.ProductUuid); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, notFoundResponse("Product"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	variants, err := server.store.ListProductVariants(ctx, req.ProductUuid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := []variantResponse{}
+	for _, variant := range variants {
+		rsp = append(rsp, newVariantResponse(variant))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type createVariantRequestUri struct {
+	ProductUuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+type variantRequestJson struct {
+	Model     string `json:"model" binding:"required"`
+	Color     string `json:"color"`
+	StorageGb int32  `json:"storage_gb" binding:"min=0"`
+}
+
+func (server *Server) createVariant(ctx *gin.Context) {
+	var reqUri createVariantRequestUri
+	var reqJson variantRequestJson
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	variant, err := server.store.CreateProductVariant(ctx, db.CreateProductVariantParams{
+		ProductUuid: reqUri.ProductUuid,
+		Model:       reqJson.Model,
+		Color:       reqJson.Color,
+		StorageGb:   reqJson.StorageGb,
+	})
+	if err != nil {
+		variantError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, newVariantResponse(variant))
+}
+
+type variantRequestUri struct {
+	ProductUuid int64 `uri:"id" binding:"required,min=1"`
+	Uuid        int64 `uri:"variant_id" binding:"required,min=1"`
+}
+
+func (server *Server) updateVariant(ctx *gin.Context) {
+	var reqUri variantRequestUri
+	var reqJson variantRequestJson
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	variant, err := server.store.UpdateProductVariant(ctx, db.UpdateProductVariantParams{
+		Uuid:        reqUri.Uuid,
+		ProductUuid: reqUri.ProductUuid,
+		Model:       reqJson.Model,
+		Color:       reqJson.Color,
+		StorageGb:   reqJson.StorageGb,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, notFoundResponse("Variant"))
+			return
+		}
+		variantError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, newVariantResponse(variant))
+}
+
+func (server *Server) deleteVariant(ctx *gin.Context) {
+	var req variantRequestUri
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	r, err := server.store.DeleteProductVariant(ctx, db.DeleteProductVariantParams{
+		Uuid:        req.Uuid,
+		ProductUuid: req.ProductUuid,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if r == 0 {
+		ctx.JSON(http.StatusNotFound, notFoundResponse("Variant"))
+		return
+	}
+	ctx.JSON(http.StatusOK, successDeleteResponse())
+}
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/alekseiapa/apple_store/db/mock"
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateVariantAPI(t *testing.T) {
+	product := randomProduct()
+	variant := randomVariant(product)
+
+	testCases := []struct {
+		name          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: fmt.Sprintf(`{"model": %q, "color": %q, "storage_gb": %d}`, variant.Model, variant.Color, variant.StorageGb),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateProductVariantParams{
+					ProductUuid: product.Uuid,
+					Model:       variant.Model,
+					Color:       variant.Color,
+					StorageGb:   variant.StorageGb,
+				}
+				store.EXPECT().
+					CreateProductVariant(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(variant, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Contains(t, recorder.Body.String(), fmt.Sprintf(`"model":%q`, variant.Model))
+			},
+		},
+		{
+			name: "ProductNotFound",
+			body: fmt.Sprintf(`{"model": %q}`, variant.Model),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateProductVariant(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ProductVariant{}, &pq.Error{Code: "23503"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "DuplicateVariant",
+			body: fmt.Sprintf(`{"model": %q, "color": %q, "storage_gb": %d}`, variant.Model, variant.Color, variant.StorageGb),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateProductVariant(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ProductVariant{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "NegativeStorage",
+			body: fmt.Sprintf(`{"model": %q, "storage_gb": -1}`, variant.Model),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateProductVariant(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/products/%d/variants", product.Uuid)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListVariantAPI(t *testing.T) {
+	product := randomProduct()
+	variants := []db.ProductVariant{randomVariant(product), randomVariant(product)}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
+					Times(1).
+					Return(product, nil)
+				store.EXPECT().
+					ListProductVariants(gomock.Any(), gomock.Eq(product.Uuid)).
+					Times(1).
+					Return(variants, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), fmt.Sprintf(`"model":%q`, variants[1].Model))
+			},
+		},
+		{
+			name: "ProductNotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProduct(gomock.Any(), gomock.Eq(product.Uuid)).
+					Times(1).
+					Return(db.Product{}, sql.ErrNoRows)
+				store.EXPECT().
+					ListProductVariants(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/products/%d/variants", product.Uuid)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateVariantAPI(t *testing.T) {
+	product := randomProduct()
+	variant := randomVariant(product)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateProductVariantParams{
+					Uuid:        variant.Uuid,
+					ProductUuid: product.Uuid,
+					Model:       variant.Model,
+					Color:       variant.Color,
+					StorageGb:   variant.StorageGb,
+				}
+				store.EXPECT().
+					UpdateProductVariant(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(variant, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			// the variant belongs to another product
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateProductVariant(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ProductVariant{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body := fmt.Sprintf(`{"model": %q, "color": %q, "storage_gb": %d}`, variant.Model, variant.Color, variant.StorageGb)
+			url := fmt.Sprintf("/api/products/%d/variants/%d", product.Uuid, variant.Uuid)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader([]byte(body)))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomVariant(product db.Product) db.ProductVariant {
+	return db.ProductVariant{
+		Uuid:        int64(util.RandomInt(1, 1000)),
+		ProductUuid: product.Uuid,
+		Model:       util.RandomString(6),
+		Color:       util.RandomString(5),
+		StorageGb:   int32(util.RandomInt(64, 1024)),
+		CreatedAt:   time.Now(),
+	}
+}
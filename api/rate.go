@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/gin-gonic/gin"
+)
+
+type rateResponse struct {
+	Currency  string    `json:"currency"`
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newRateResponse(rate db.ExchangeRate) rateResponse {
+	return rateResponse{
+		Currency:  rate.Currency,
+		Rate:      rate.Rate,
+		UpdatedAt: rate.UpdatedAt,
+	}
+}
+
+// listRate returns the rates of all the supported currencies to the base currency
+func (server *Server) listRate(ctx *gin.Context) {
+	rates, err := server.store.ListExchangeRates(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := []rateResponse{}
+	for _, rate := range rates {
+		rsp = append(rsp, newRateResponse(rate))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type updateRateRequestUri struct {
+	Currency string `uri:"currency" binding:"required,len=3,uppercase"`
+}
+
+type updateRateRequestJson struct {
+	// the rate is a decimal string, so it isn't rounded by a float on its way to the db
+	Rate string `json:"rate" binding:"required"`
+}
+
+// updateRate sets how many units of the currency are worth one unit of the base currency.
+// Setting the rate of an unknown currency adds it to the supported currencies
+func (server *Server) updateRate(ctx *gin.Context) {
+	var reqUri updateRateRequestUri
+	var reqJson updateRateRequestJson
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if reqUri.Currency == util.BaseCurrency {
+		err := errors.New("the rate of the base currency is always 1")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, err := util.ParseRate(reqJson.Rate); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rate, err := server.store.UpsertExchangeRate(ctx, db.UpsertExchangeRateParams{
+		Currency: reqUri.Currency,
+		Rate:     reqJson.Rate,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newRateResponse(rate))
+}
+
+// convertCur converts the amount with the rates of the server. A currency without a rate is a bad request
+func (server *Server) convertCur(ctx *gin.Context, fromCur string, toCur string, amount util.Money) (util.Money, bool) {
+	rate, valid := server.crossRate(ctx, fromCur, toCur)
+	if !valid {
+		return 0, false
+	}
+	return amount.MulRat(rate), true
+}
+
+// crossRate reads the rate between the currencies once, so a whole page of prices can be converted with it
+func (server *Server) crossRate(ctx *gin.Context, fromCur string, toCur string) (*big.Rat, bool) {
+	rate, err := util.CrossRate(ctx, server.rates, fromCur, toCur)
+	if err != nil {
+		if errors.Is(err, util.ErrUnsupportedCurrency) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+	return rate, true
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+type reservationResponse struct {
+	Uuid        int64     `json:"uuid"`
+	ProductUuid int64     `json:"product_uuid"`
+	Quantity    int32     `json:"quantity"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func newReservationResponse(reservation db.Reservation) reservationResponse {
+	return reservationResponse{
+		Uuid:        reservation.Uuid,
+		ProductUuid: reservation.ProductUuid,
+		Quantity:    reservation.Quantity,
+		ExpiresAt:   reservation.ExpiresAt,
+	}
+}
+
+type createReservationRequest struct {
+	ProductUuid int64 `json:"product_uuid" binding:"required,min=1"`
+	Quantity    int32 `json:"quantity" binding:"required,min=1"`
+}
+
+// createReservation holds the pcs of the product for the authenticated user for the configured duration.
+// Reserving the product again only changes the quantity, the reservation still expires on time
+func (server *Server) createReservation(ctx *gin.Context) {
+	var req createReservationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	user, valid := server.authUser(ctx)
+	if !valid {
+		return
+	}
+	reservation, err := server.store.ReserveTx(ctx, db.ReserveTxParams{
+		UserUuid:    user.Uuid,
+		ProductUuid: req.ProductUuid,
+		Quantity:    req.Quantity,
+		Duration:    server.config.ReservationDuration,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, notFoundResponse("product"))
+			return
+		}
+		if errors.Is(err, db.ErrNotEnoughStock) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusCreated, newReservationResponse(reservation))
+}
+
+// listReservation lists the reservations of the authenticated user that haven't expired yet
+func (server *Server) listReservation(ctx *gin.Context) {
+	user, valid := server.authUser(ctx)
+	if !valid {
+		return
+	}
+	reservations, err := server.store.ListReservations(ctx, user.Uuid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := make([]reservationResponse, len(reservations))
+	for i, reservation := range reservations {
+		rsp[i] = newReservationResponse(reservation)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type deleteReservationRequest struct {
+	Uuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+// deleteReservation releases the pcs before the reservation expires
+func (server *Server) deleteReservation(ctx *gin.Context) {
+	var req deleteReservationRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	reservation, err := server.store.GetReservation(ctx, req.Uuid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, notFoundResponse("reservation"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if _, valid := server.validUser(ctx, reservation.UserUuid); !valid {
+		return
+	}
+	r, err := server.store.DeleteReservation(ctx, reservation.Uuid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if r == 0 {
+		ctx.JSON(http.StatusNotFound, notFoundResponse("reservation"))
+		return
+	}
+	ctx.JSON(http.StatusOK, successDeleteResponse())
+}
+
+// listReservedQuantities returns the pcs of the products held by the active reservations, by the product uuid
+func (server *Server) listReservedQuantities(ctx *gin.Context, products []db.Product) (map[int64]int32, bool) {
+	uuids := make([]int64, len(products))
+	for i, product := range products {
+		uuids[i] = product.Uuid
+	}
+	quantities, err := server.store.ListReservedQuantities(ctx, uuids)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+	reserved := make(map[int64]int32, len(quantities))
+	for _, quantity := range quantities {
+		reserved[quantity.ProductUuid] = quantity.Reserved
+	}
+	return reserved, true
+}
+
+// sweepReservations deletes the expired reservations every interval until the context is done.
+// The expired reservations are already ignored by the availability, the sweeper only keeps the table small
+func (server *Server) sweepReservations(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := server.store.ReleaseExpiredReservations(ctx)
+			if err != nil {
+				log.Printf("cannot release the expired reservations: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("released %d expired reservations", n)
+			}
+		}
+	}
+}
@@ -1,22 +1,34 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/notify"
+	"github.com/alekseiapa/apple_store/storage"
 	"github.com/alekseiapa/apple_store/token"
 	"github.com/alekseiapa/apple_store/util"
 	"github.com/gin-gonic/gin"
 )
 
+var errTrustedProxiesNotSet = errors.New("TRUSTED_PROXIES is not set, set it to the addresses of the proxies in front of the server or to 127.0.0.1 when there is none")
+
 type Server struct {
 	config     util.Config
 	store      db.Store
+	rates      util.RateProvider
+	storage    storage.Storage
+	notifier   notify.Notifier
 	tokenMaker token.Maker
 	router     *gin.Engine
+	// background is the work the handlers leave running after the response, like sending the notifications
+	background sync.WaitGroup
 }
 
-func NewServer(config util.Config, store db.Store) (*Server, error) {
+func NewServer(config util.Config, store db.Store, rates util.RateProvider, files storage.Storage, notifier notify.Notifier) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("error creating token maker: %v", err)
@@ -24,45 +36,126 @@ func NewServer(config util.Config, store db.Store) (*Server, error) {
 	server := &Server{
 		config:     config,
 		store:      store,
+		rates:      rates,
+		storage:    files,
+		notifier:   notifier,
 		tokenMaker: tokenMaker,
 	}
 	server.setupRouter()
+	// the client ip is read from the X-Forwarded-For header only behind the trusted proxies,
+	// otherwise anyone could pick the ip their failed logins are counted for. Behind a proxy that isn't trusted
+	// all the clients share the ip of the proxy and lock each other out, so the proxies have to be set explicitly
+	if len(config.TrustedProxies) == 0 {
+		return nil, errTrustedProxiesNotSet
+	}
+	if err := server.router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %v", err)
+	}
 	return server, nil
 }
 
 func (server *Server) setupRouter() {
 	router := gin.Default()
-
-	// TODO: add protected routes
-	// authRoutes := router.Group("/api").Use(authMiddleware(server.tokenMaker))
+	// the handlers pass the gin context to the store, so a cancelled request or one that timed out stops its queries
+	router.ContextWithFallback = true
 
 	router.POST("/api/users", server.createUser)
 	router.POST("/api/users/login", server.loginUser)
-	router.GET("/api/users/:id", server.getUser)
-	router.GET("/api/users", server.listUser)
-	router.PUT("/api/users/:id", server.updateUser)
-	router.DELETE("/api/users/:id", server.deleteUser)
+	router.POST("/api/users/password/forgot", server.forgotPassword)
+	router.POST("/api/users/password/reset", server.resetPassword)
+	router.POST("/api/tokens/renew_access", server.renewAccessToken)
 
-	router.POST("/api/products", server.createProduct)
 	router.GET("/api/products/:id", server.getProduct)
 	router.GET("/api/products", server.listProduct)
-	router.PUT("/api/products/:id", server.updateProduct)
-	router.DELETE("/api/products/:id", server.deleteProduct)
+	router.GET("/api/products/:id/variants", server.listVariant)
+
+	router.GET("/api/categories/:id", server.getCategory)
+	router.GET("/api/categories", server.listCategory)
 
-	router.GET("/api/orders/:id", server.getOrder)
-	router.POST("/api/orders", server.createOrder)
-	router.DELETE("/api/orders/:id", server.deleteOrder)
+	router.GET("/api/rates", server.listRate)
 
-	// TODO: The following routes should be implemented
-	// router.GET("/api/orders/:id", server.getProduct)
-	// router.GET("/api/orders", server.listProduct)
-	// router.PUT("/api/orders/:id", server.updateProduct)
+	// the files of the local storage are served by the server itself
+	if server.config.StorageDir != "" {
+		router.Static(server.config.StorageURL, server.config.StorageDir)
+	}
+
+	authRoutes := router.Group("/api").Use(authMiddleware(server.tokenMaker, server.store))
+
+	authRoutes.POST("/users/logout", server.logoutUser)
+	authRoutes.GET("/users/:id", server.getUser)
+	authRoutes.PUT("/users/:id", server.updateUser)
+	authRoutes.PATCH("/users/:id", server.patchUser)
+	authRoutes.DELETE("/users/:id", server.deleteUser)
+	authRoutes.POST("/users/:id/password", server.changePassword)
+	authRoutes.GET("/users/:id/wallets", server.listWallet)
+	authRoutes.GET("/users/:id/balance", server.getBalance)
+	authRoutes.GET("/users/:id/balance/entries", server.listEntry)
+	authRoutes.POST("/users/:id/balance/withdraw", server.withdrawBalance)
+	authRoutes.POST("/users/:id/follow", server.followUser)
+	authRoutes.DELETE("/users/:id/follow", server.unfollowUser)
+	authRoutes.GET("/users/:id/followers", server.listFollowers)
+	authRoutes.GET("/users/:id/following", server.listFollowing)
+	authRoutes.GET("/users/:id/mutual", server.listMutualFollows)
+	authRoutes.GET("/users/:id/privacy", server.getPrivacy)
+	authRoutes.PUT("/users/:id/privacy", server.updatePrivacy)
+
+	authRoutes.GET("/feed", server.getFeed)
+
+	authRoutes.GET("/orders/:id", server.getOrder)
+	authRoutes.GET("/orders", server.listOrder)
+	authRoutes.POST("/orders", idempotencyMiddleware(server.store, server.config.IdempotencyKeyDuration, server.config.IdempotencyKeyTimeout), server.createOrder)
+	authRoutes.DELETE("/orders/:id", server.deleteOrder)
+
+	authRoutes.POST("/transfers", server.createTransfer)
+
+	authRoutes.GET("/cart", server.getCart)
+	authRoutes.POST("/cart/items", server.addCartItem)
+	authRoutes.PUT("/cart/items/:id", server.updateCartItem)
+	authRoutes.DELETE("/cart/items/:id", server.deleteCartItem)
+	authRoutes.POST("/cart/checkout", server.checkoutCart)
+
+	authRoutes.GET("/reservations", server.listReservation)
+	authRoutes.POST("/reservations", server.createReservation)
+	authRoutes.DELETE("/reservations/:id", server.deleteReservation)
+
+	adminRoutes := router.Group("/api").Use(authMiddleware(server.tokenMaker, server.store), roleMiddleware(util.AdminRole))
+
+	adminRoutes.GET("/users", server.listUser)
+	adminRoutes.GET("/failed_logins", server.listFailedLogin)
+	adminRoutes.POST("/users/:id/balance/top_up", server.topUpBalance)
+
+	adminRoutes.POST("/products", server.createProduct)
+	adminRoutes.PUT("/products/:id", server.updateProduct)
+	adminRoutes.PATCH("/products/:id", server.patchProduct)
+	adminRoutes.DELETE("/products/:id", server.deleteProduct)
+	adminRoutes.POST("/products/:id/images", server.uploadProductImage)
+	adminRoutes.POST("/products/:id/variants", server.createVariant)
+	adminRoutes.PUT("/products/:id/variants/:variant_id", server.updateVariant)
+	adminRoutes.DELETE("/products/:id/variants/:variant_id", server.deleteVariant)
+
+	adminRoutes.POST("/categories", server.createCategory)
+	adminRoutes.PUT("/categories/:id", server.updateCategory)
+	adminRoutes.DELETE("/categories/:id", server.deleteCategory)
+
+	adminRoutes.PUT("/orders/:id/status", server.updateOrderStatus)
+
+	adminRoutes.PUT("/rates/:currency", server.updateRate)
 
 	server.router = router
 }
 
-// Start runs the HTTP server on a specific address to start listening the api requests
+// Start runs the HTTP server on a specific address to start listening the api requests.
+// The expired reservations, idempotency keys and login throttles are deleted in the background while the server runs
 func (server *Server) Start(address string) error {
+	if server.config.ReservationSweepInterval > 0 {
+		go server.sweepReservations(context.Background(), server.config.ReservationSweepInterval)
+	}
+	if server.config.IdempotencyKeySweepInterval > 0 {
+		go server.sweepIdempotencyKeys(context.Background(), server.config.IdempotencyKeySweepInterval)
+	}
+	if server.config.LoginThrottleSweepInterval > 0 {
+		go server.sweepLoginThrottles(context.Background(), server.config.LoginThrottleSweepInterval)
+	}
 	return server.router.Run(address)
 }
 
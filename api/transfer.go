@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/gin-gonic/gin"
+)
+
+type transferResponse struct {
+	Uuid         int64      `json:"uuid"`
+	FromUserUuid int64      `json:"from_user_uuid"`
+	ToUserUuid   int64      `json:"to_user_uuid"`
+	Currency     string     `json:"currency"`
+	Amount       util.Money `json:"amount"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func newTransferResponse(transfer db.Transfer) transferResponse {
+	return transferResponse{
+		Uuid:         transfer.Uuid,
+		FromUserUuid: transfer.FromUserUuid,
+		ToUserUuid:   transfer.ToUserUuid,
+		Currency:     transfer.Currency,
+		Amount:       transfer.Amount,
+		CreatedAt:    transfer.CreatedAt,
+	}
+}
+
+// createTransferResponse only shows the side of the sender, the balance of the recipient stays private
+type createTransferResponse struct {
+	Transfer transferResponse `json:"transfer"`
+	Wallet   walletResponse   `json:"wallet"`
+	Entry    entryResponse    `json:"entry"`
+}
+
+type createTransferRequest struct {
+	ToUserUuid int64      `json:"to_user_uuid" binding:"required,min=1"`
+	Currency   string     `json:"currency" binding:"required,len=3"`
+	Amount     util.Money `json:"amount" binding:"required,gt=0"`
+}
+
+// createTransfer sends money from the wallet of the authenticated user to the wallet of the same currency of another user
+func (server *Server) createTransfer(ctx *gin.Context) {
+	var req createTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	user, valid := server.authUser(ctx)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.TransferTx(ctx, db.TransferTxParams{
+		FromUserUuid: user.Uuid,
+		ToUserUuid:   req.ToUserUuid,
+		Currency:     req.Currency,
+		Amount:       req.Amount,
+	})
+	if err != nil {
+		ledgerError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, createTransferResponse{
+		Transfer: newTransferResponse(result.Transfer),
+		Wallet:   newWalletResponse(result.FromWallet),
+		Entry:    newEntryResponse(result.FromEntry),
+	})
+}
@@ -2,34 +2,38 @@ package api
 
 import (
 	"database/sql"
+	"errors"
+	"log"
 	"net/http"
+	"time"
 
 	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/token"
 	"github.com/alekseiapa/apple_store/util"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
 type createUserRequest struct {
-	FirstName  string  `json:"first_name" binding:"required"`
-	MiddleName string  `json:"middle_name" binding:"required"`
-	LastName   string  `json:"last_name" binding:"required"`
-	Gender     string  `json:"gender" binding:"required,oneof=M F"`
-	Age        int16   `json:"age" binding:"required"`
-	Balance    float32 `json:"balance" binding:"required"`
-	Username   string  `json:"username" binding:"required,alphanum"`
-	Password   string  `json:"password" binding:"required,min=6"`
+	FirstName  string `json:"first_name" binding:"required"`
+	MiddleName string `json:"middle_name" binding:"required"`
+	LastName   string `json:"last_name" binding:"required"`
+	Gender     string `json:"gender" binding:"required,oneof=M F"`
+	Age        int16  `json:"age" binding:"required"`
+	Username   string `json:"username" binding:"required,alphanum"`
+	Password   string `json:"password" binding:"required,min=6"`
 }
 
 type userResponse struct {
-	Uuid       int64   `json:"uuid"`
-	FirstName  string  `json:"first_name"`
-	MiddleName string  `json:"middle_name"`
-	LastName   string  `json:"last_name"`
-	Gender     string  `json:"gender"`
-	Age        int16   `json:"age"`
-	Balance    float32 `json:"balance"`
-	Username   string  `json:"username"`
+	Uuid       int64  `json:"uuid"`
+	FirstName  string `json:"first_name"`
+	MiddleName string `json:"middle_name"`
+	LastName   string `json:"last_name"`
+	Gender     string `json:"gender"`
+	Age        int16  `json:"age"`
+	Username   string `json:"username"`
+	Role       string `json:"role"`
 }
 
 func newUserResponse(user db.User) userResponse {
@@ -40,8 +44,8 @@ func newUserResponse(user db.User) userResponse {
 		LastName:   user.LastName,
 		Gender:     user.Gender,
 		Age:        user.Age,
-		Balance:    user.Balance,
 		Username:   user.Username,
+		Role:       user.Role,
 	}
 }
 
@@ -63,7 +67,6 @@ func (server *Server) createUser(ctx *gin.Context) {
 		LastName:       req.LastName,
 		Gender:         req.Gender,
 		Age:            req.Age,
-		Balance:        req.Balance,
 		Username:       req.Username,
 		HashedPassword: hashedPassword,
 	}
@@ -98,30 +101,28 @@ func (server *Server) getUser(ctx *gin.Context) {
 		return
 	}
 
-	user, err := server.store.GetUser(ctx, req.Uuid)
-	// authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	// if user.Username != authPayload.Username {
-	// 	err := errors.New("can't buy on a behalf of other user")
-	// 	ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-	// 	return
-	// }
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	user, valid := server.validUser(ctx, req.Uuid)
+	if !valid {
 		return
 	}
 	rsp := newUserResponse(user)
+	setETag(ctx, user.Version)
 	ctx.JSON(http.StatusOK, rsp)
 }
 
 type listUserRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5"`
+	PageID    int32  `form:"page_id" binding:"omitempty,min=1"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5"`
+	Cursor    string `form:"cursor"`
+	WithTotal bool   `form:"with_total"`
+}
+
+type listUserResponse struct {
+	Items []userResponse `json:"items"`
+	listPage
 }
 
+// listUser pages through the users by the cursor, the page_id keeps the old offset pages working
 func (server *Server) listUser(ctx *gin.Context) {
 	var req listUserRequest
 	var userRespList []userResponse
@@ -129,6 +130,14 @@ func (server *Server) listUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	if req.PageID == 0 {
+		server.listUserByCursor(ctx, req)
+		return
+	}
+	if req.Cursor != "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errCursorWithPageID))
+		return
+	}
 	arg := db.ListUsersParams{
 		Limit:  req.PageSize,
 		Offset: (req.PageID - 1) * req.PageSize,
@@ -148,17 +157,66 @@ func (server *Server) listUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, userRespList)
 }
 
+func (server *Server) listUserByCursor(ctx *gin.Context, req listUserRequest) {
+	cursor, err := listCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	// one more user tells if there is another page
+	var users []db.User
+	if cursor.Backward {
+		users, err = server.store.ListUsersBefore(ctx, db.ListUsersBeforeParams{
+			BeforeUuid: cursor.Uuid,
+			Limit:      req.PageSize + 1,
+		})
+	} else {
+		users, err = server.store.ListUsersAfter(ctx, db.ListUsersAfterParams{
+			AfterUuid: cursor.Uuid,
+			Limit:     req.PageSize + 1,
+		})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	more := len(users) > int(req.PageSize)
+	if more {
+		users = users[:req.PageSize]
+	}
+
+	rsp := listUserResponse{Items: make([]userResponse, len(users))}
+	uuids := make([]int64, len(users))
+	for i, user := range users {
+		// the backward pages come in the reverse order
+		j := i
+		if cursor.Backward {
+			j = len(users) - 1 - i
+		}
+		rsp.Items[j] = newUserResponse(user)
+		uuids[j] = user.Uuid
+	}
+	rsp.listPage = newListPage(cursor, uuids, more)
+	if req.WithTotal {
+		total, err := server.store.CountUsers(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		rsp.Total = &total
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
 type updateUserRequestUri struct {
 	Uuid int64 `uri:"id" binding:"required,min=1"`
 }
 type updateUserRequestJson struct {
-	FirstName  string  `json:"first_name" binding:"required"`
-	MiddleName string  `json:"middle_name" binding:"required"`
-	LastName   string  `json:"last_name" binding:"required"`
-	Gender     string  `json:"gender" binding:"required,oneof=M F"`
-	Age        int16   `json:"age" binding:"required"`
-	Balance    float32 `json:"balance" binding:"required"`
-	Password   string  `json:"password" binding:"required"`
+	FirstName  string `json:"first_name" binding:"required"`
+	MiddleName string `json:"middle_name" binding:"required"`
+	LastName   string `json:"last_name" binding:"required"`
+	Gender     string `json:"gender" binding:"required,oneof=M F"`
+	Age        int16  `json:"age" binding:"required"`
 }
 
 func (server *Server) updateUser(ctx *gin.Context) {
@@ -173,33 +231,89 @@ func (server *Server) updateUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-	hashedPassword, err := util.HashPassword(reqJson.Password)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	version, valid := ifMatchVersion(ctx)
+	if !valid {
+		return
+	}
+	current, valid := server.validUser(ctx, reqUri.Uuid)
+	if !valid || !matchVersion(ctx, version, current.Version) {
 		return
 	}
 
 	arg := db.UpdateUserParams{
-		Uuid:           reqUri.Uuid,
-		FirstName:      reqJson.FirstName,
-		MiddleName:     reqJson.MiddleName,
-		LastName:       reqJson.LastName,
-		Gender:         reqJson.Gender,
-		Age:            reqJson.Age,
-		Balance:        reqJson.Balance,
-		HashedPassword: hashedPassword,
+		Uuid:       reqUri.Uuid,
+		FirstName:  reqJson.FirstName,
+		MiddleName: reqJson.MiddleName,
+		LastName:   reqJson.LastName,
+		Gender:     reqJson.Gender,
+		Age:        reqJson.Age,
+		Version:    version,
 	}
 	user, err := server.store.UpdateUser(ctx, arg)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			userGone(ctx, version)
 			return
 		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 	rsp := newUserResponse(user)
+	setETag(ctx, user.Version)
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// patchUserRequestJson only holds the fields to change (JSON Merge Patch)
+type patchUserRequestJson struct {
+	FirstName  *string `json:"first_name" binding:"omitempty,min=1,max=256"`
+	MiddleName *string `json:"middle_name" binding:"omitempty,min=1,max=256"`
+	LastName   *string `json:"last_name" binding:"omitempty,min=1,max=256"`
+	Gender     *string `json:"gender" binding:"omitempty,oneof=M F"`
+	Age        *int16  `json:"age" binding:"omitempty,min=1"`
+}
+
+func (server *Server) patchUser(ctx *gin.Context) {
+	var reqUri updateUserRequestUri
+	var reqJson patchUserRequestJson
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	version, valid := ifMatchVersion(ctx)
+	if !valid {
+		return
+	}
+	current, valid := server.validUser(ctx, reqUri.Uuid)
+	if !valid || !matchVersion(ctx, version, current.Version) {
+		return
+	}
+
+	arg := db.PatchUserParams{
+		Uuid:       reqUri.Uuid,
+		FirstName:  nullString(reqJson.FirstName),
+		MiddleName: nullString(reqJson.MiddleName),
+		LastName:   nullString(reqJson.LastName),
+		Gender:     nullString(reqJson.Gender),
+		Age:        nullInt16(reqJson.Age),
+		Version:    version,
+	}
+	user, err := server.store.PatchUser(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			userGone(ctx, version)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := newUserResponse(user)
+	setETag(ctx, user.Version)
 	ctx.JSON(http.StatusOK, rsp)
 }
 
@@ -213,13 +327,18 @@ func (server *Server) deleteUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-	// authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	// if user.Username != authPayload.Username {
-	// 	err := errors.New("can't buy on a behalf of other user")
-	// 	ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-	// 	return
-	// }
-	r, err := server.store.DeleteUser(ctx, req.Uuid)
+	version, valid := ifMatchVersion(ctx)
+	if !valid {
+		return
+	}
+	current, valid := server.validUser(ctx, req.Uuid)
+	if !valid || !matchVersion(ctx, version, current.Version) {
+		return
+	}
+	r, err := server.store.DeleteUser(ctx, db.DeleteUserParams{
+		Uuid:    req.Uuid,
+		Version: version,
+	})
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
@@ -229,20 +348,34 @@ func (server *Server) deleteUser(ctx *gin.Context) {
 		return
 	}
 	if r == 0 {
-		ctx.JSON(http.StatusNotFound, notFoundResponse("user"))
+		userGone(ctx, version)
 		return
 	}
 	ctx.JSON(http.StatusOK, successDeleteResponse())
 }
 
+// userGone responds to an update or a delete that found no user with the version. The user was read right before,
+// so it was either changed or deleted in the meantime
+func userGone(ctx *gin.Context, version sql.NullInt32) {
+	if version.Valid {
+		ctx.JSON(http.StatusPreconditionFailed, errorResponse(errVersionMismatch))
+		return
+	}
+	ctx.JSON(http.StatusNotFound, notFoundResponse("user"))
+}
+
 type loginUserRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
 
 type loginUserResponse struct {
-	AccessToken string       `json:"access_token" binding:"required"`
-	User        userResponse `json:"user" binding:"required"`
+	SessionID             uuid.UUID    `json:"session_id"`
+	AccessToken           string       `json:"access_token"`
+	AccessTokenExpiresAt  time.Time    `json:"access_token_expires_at"`
+	RefreshToken          string       `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time    `json:"refresh_token_expires_at"`
+	User                  userResponse `json:"user"`
 }
 
 func (server *Server) loginUser(ctx *gin.Context) {
@@ -251,10 +384,15 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	reserved, valid := server.reserveLoginAttempt(ctx, req.Username)
+	if !valid {
+		return
+	}
 	user, err := server.store.GetUserByUserName(ctx, req.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			checkDummyPassword(req.Password)
+			server.failedLogin(ctx, req.Username, failedLoginUnknownUser)
 			return
 		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -262,22 +400,110 @@ func (server *Server) loginUser(ctx *gin.Context) {
 	}
 	err = util.CheckPassword(req.Password, user.HashedPassword)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		server.failedLogin(ctx, req.Username, failedLoginWrongPassword)
+		return
+	}
+	if !server.loginSucceeded(ctx, user.Username, reserved) {
 		return
 	}
 	// Only when the password is correct we will create a new access token
-	accessToken, err := server.tokenMaker.CreateToken(
+	rsp, err := server.newSession(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// newSession creates the access token and the session with the refresh token of the user
+func (server *Server) newSession(ctx *gin.Context, user db.User) (loginUserResponse, error) {
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
+		user.Role,
+		token.TokenTypeAccessToken,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
+		return loginUserResponse{}, err
+	}
+	// The refresh token lives much longer and is stored in the Session table,
+	// so that it can be used to renew the access token and can be blocked if it's leaked
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		token.TokenTypeRefreshToken,
+		server.config.RefreshTokenDuration,
+	)
+	if err != nil {
+		return loginUserResponse{}, err
+	}
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		Uuid:         refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    ctx.Request.UserAgent(),
+		ClientIp:     ctx.ClientIP(),
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		return loginUserResponse{}, err
+	}
+	return loginUserResponse{
+		SessionID:             session.Uuid,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+		User:                  newUserResponse(user),
+	}, nil
+}
+
+type logoutUserRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// logoutUser revokes the access token used for the request and blocks the session of the refresh token,
+// so that neither of them can be used anymore even though they have not expired yet
+func (server *Server) logoutUser(ctx *gin.Context) {
+	var req logoutUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken, token.TokenTypeRefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if refreshPayload.Username != authPayload.Username {
+		err := errors.New("session doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	_, err = server.store.BlockSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-	rsp := loginUserResponse{
-		AccessToken: accessToken,
-		User:        newUserResponse(user),
+	err = server.store.CreateRevokedToken(ctx, db.CreateRevokedTokenParams{
+		Uuid:      authPayload.ID,
+		Username:  authPayload.Username,
+		ExpiresAt: authPayload.ExpiredAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
 	}
-	ctx.JSON(http.StatusOK, rsp)
-
+	// keep the revocation list small by pruning the tokens that have expired on their own
+	if _, err := server.store.DeleteExpiredRevokedTokens(ctx); err != nil {
+		log.Println("cannot prune expired revoked tokens:", err)
+	}
+	ctx.JSON(http.StatusOK, gin.H{"success": "Logged out successfully"})
 }
@@ -2,16 +2,20 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	mockdb "github.com/alekseiapa/apple_store/db/mock"
 	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/token"
 	"github.com/alekseiapa/apple_store/util"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -25,12 +29,16 @@ func TestGetUserAPI(t *testing.T) {
 	testCases := []struct {
 		name          string
 		UserUuid      int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
 	}{
 		{
 			name:     "OK",
 			UserUuid: user.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
@@ -39,12 +47,46 @@ func TestGetUserAPI(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"1"`, recorder.Header().Get(etagHeaderKey))
 				requireBodyMatchUser(t, recorder.Body, user)
 			},
 		},
+		{
+			name:     "UnauthorizedUser",
+			UserUuid: user.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", util.CustomerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NoAuthorization",
+			UserUuid: user.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name:     "NotFound",
 			UserUuid: user.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
@@ -58,6 +100,9 @@ func TestGetUserAPI(t *testing.T) {
 		{
 			name:     "InternalError",
 			UserUuid: user.Uuid,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
@@ -71,6 +116,9 @@ func TestGetUserAPI(t *testing.T) {
 		{
 			name:     "InvalidID",
 			UserUuid: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
@@ -90,6 +138,7 @@ func TestGetUserAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
 
 			server := NewTestServer(t, store)
 			//For testing an HTTP API in Go, we don’t have to start a real HTTP server,
@@ -101,6 +150,7 @@ func TestGetUserAPI(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -108,6 +158,356 @@ func TestGetUserAPI(t *testing.T) {
 
 }
 
+func TestUpdateUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	body := `{"first_name": "Tim", "middle_name": "A", "last_name": "Cook", "gender": "M", "age": 30}`
+	updated := user
+	updated.Version = user.Version + 1
+
+	testCases := []struct {
+		name          string
+		ifMatch       string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			ifMatch: `"1"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpdateUserParams) (db.User, error) {
+						require.Equal(t, user.Uuid, arg.Uuid)
+						require.Equal(t, sql.NullInt32{Int32: 1, Valid: true}, arg.Version)
+						return updated, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"2"`, recorder.Header().Get(etagHeaderKey))
+			},
+		},
+		{
+			name:    "AnyVersion",
+			ifMatch: "*",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpdateUserParams) (db.User, error) {
+						require.False(t, arg.Version.Valid)
+						return updated, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NoIfMatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+		{
+			name:    "VersionMismatch",
+			ifMatch: `"7"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "ChangedConcurrently",
+			ifMatch: `"1"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "InvalidIfMatch",
+			ifMatch: `W/"1"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/users/%d", user.Uuid)
+			request, err := http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+			require.NoError(t, err)
+			if tc.ifMatch != "" {
+				request.Header.Set(ifMatchHeaderKey, tc.ifMatch)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPatchUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	patched := user
+	patched.LastName = "Cook"
+	patched.Version = user.Version + 1
+
+	testCases := []struct {
+		name          string
+		ifMatch       string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			ifMatch: `"1"`,
+			body:    `{"last_name": "Cook", "age": null}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				arg := db.PatchUserParams{
+					Uuid:     user.Uuid,
+					LastName: sql.NullString{String: "Cook", Valid: true},
+					Version:  sql.NullInt32{Int32: 1, Valid: true},
+				}
+				store.EXPECT().
+					PatchUser(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(patched, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"2"`, recorder.Header().Get(etagHeaderKey))
+				requireBodyMatchUser(t, recorder.Body, patched)
+			},
+		},
+		{
+			name:    "InvalidGender",
+			ifMatch: `"1"`,
+			body:    `{"gender": "X"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PatchUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "InternalError",
+			ifMatch: "*",
+			body:    `{"first_name": "Tim"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					PatchUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "NoIfMatch",
+			body: `{"first_name": "Tim"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PatchUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/users/%d", user.Uuid)
+			request, err := http.NewRequest(http.MethodPatch, url, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			if tc.ifMatch != "" {
+				request.Header.Set(ifMatchHeaderKey, tc.ifMatch)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		ifMatch       string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			ifMatch: `"1"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				arg := db.DeleteUserParams{
+					Uuid:    user.Uuid,
+					Version: sql.NullInt32{Int32: 1, Valid: true},
+				}
+				store.EXPECT().
+					DeleteUser(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "ChangedConcurrently",
+			ifMatch: `"1"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "VersionMismatch",
+			ifMatch: `"2"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Uuid)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name: "NoIfMatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/users/%d", user.Uuid)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+			request.Header.Set(ifMatchHeaderKey, tc.ifMatch)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func TestCreateUserAPI(t *testing.T) {
 	user, password := randomUser(t)
 	testCases := []struct {
@@ -124,7 +524,6 @@ func TestCreateUserAPI(t *testing.T) {
 				"last_name":   user.LastName,
 				"gender":      user.Gender,
 				"age":         user.Age,
-				"balance":     user.Balance,
 				"username":    user.Username,
 				"password":    password,
 			},
@@ -147,7 +546,6 @@ func TestCreateUserAPI(t *testing.T) {
 				"last_name":   user.LastName,
 				"gender":      user.Gender,
 				"age":         user.Age,
-				"balance":     user.Balance,
 				"username":    user.Username,
 				"password":    password,
 			},
@@ -169,7 +567,6 @@ func TestCreateUserAPI(t *testing.T) {
 				"last_name":   user.LastName,
 				"gender":      user.Gender,
 				"age":         user.Age,
-				"balance":     user.Balance,
 				"username":    user.Username,
 				"password":    password,
 			},
@@ -191,7 +588,6 @@ func TestCreateUserAPI(t *testing.T) {
 				"last_name":   user.LastName,
 				"gender":      user.Gender,
 				"age":         user.Age,
-				"balance":     user.Balance,
 				"username":    "invalid-username",
 				"password":    password,
 			},
@@ -212,7 +608,6 @@ func TestCreateUserAPI(t *testing.T) {
 				"last_name":   user.LastName,
 				"gender":      user.Gender,
 				"age":         user.Age,
-				"balance":     user.Balance,
 				"username":    user.Username,
 				"password":    "123",
 			},
@@ -265,6 +660,384 @@ func TestCreateUserAPI(t *testing.T) {
 	}
 }
 
+func TestLoginUserAPI(t *testing.T) {
+	user, password := randomUser(t)
+	clientIp := "192.0.2.1"
+	keys := []string{"username:" + user.Username, "ip:" + clientIp}
+	failureAt := sql.NullTime{Time: time.Now(), Valid: true}
+	previousFailureAt := sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}
+	reserved := db.ReserveLoginAttemptTxResult{
+		Attempts: []db.ReservedLoginAttempt{
+			{Throttle: db.LoginThrottle{Key: keys[0], Failures: 1, UpdatedAt: time.Now(), LastFailureAt: failureAt}},
+			{
+				Throttle:          db.LoginThrottle{Key: keys[1], Failures: 2, UpdatedAt: time.Now(), LastFailureAt: failureAt},
+				PreviousFailureAt: previousFailureAt,
+			},
+		},
+	}
+
+	// buildReserveStubs stubs the reservation of the attempt made before the password is checked
+	buildReserveStubs := func(store *mockdb.MockStore, result db.ReserveLoginAttemptTxResult, err error) {
+		store.EXPECT().
+			ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(ctx context.Context, arg db.ReserveLoginAttemptTxParams) (db.ReserveLoginAttemptTxResult, error) {
+				require.Equal(t, []db.LoginThrottleLimit{
+					{Key: keys[0], MaxFailures: 3},
+					{Key: keys[1], MaxFailures: 10},
+				}, arg.Limits)
+				require.WithinDuration(t, time.Now().Add(-24*time.Hour), arg.ResetBefore, time.Minute)
+				require.Equal(t, time.Minute, arg.Lockout)
+				require.Equal(t, time.Hour, arg.MaxLockout)
+				return result, err
+			})
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"username": user.Username, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildReserveStubs(store, reserved, nil)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteLoginThrottle(gomock.Any(), gomock.Eq(keys[0])).
+					Times(1).
+					Return(nil)
+				// the last failure before the attempt is put back, so successful logins don't keep the failures from resetting
+				arg := db.RefundLoginAttemptParams{
+					Key:               keys[1],
+					FailureAt:         failureAt,
+					PreviousFailureAt: previousFailureAt,
+				}
+				store.EXPECT().
+					RefundLoginAttempt(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{Username: user.Username}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RefundsLock",
+			body: gin.H{"username": user.Username, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				// the attempt reached the max failures of the client ip, the lock it took is lifted again
+				lockedUntil := sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true}
+				result := db.ReserveLoginAttemptTxResult{
+					Attempts: []db.ReservedLoginAttempt{
+						{Throttle: db.LoginThrottle{Key: keys[0], Failures: 1, UpdatedAt: time.Now(), LastFailureAt: failureAt}},
+						{Throttle: db.LoginThrottle{Key: keys[1], Failures: 10, UpdatedAt: time.Now(), LockedUntil: lockedUntil, LastFailureAt: failureAt}},
+					},
+				}
+				buildReserveStubs(store, result, nil)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteLoginThrottle(gomock.Any(), gomock.Eq(keys[0])).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					RefundLoginAttempt(gomock.Any(), gomock.Eq(db.RefundLoginAttemptParams{Key: keys[1], FailureAt: failureAt, LockedUntil: lockedUntil})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{Username: user.Username}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{"username": user.Username, "password": "wrong-password"},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildReserveStubs(store, reserved, nil)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				arg := db.CreateFailedLoginParams{
+					Username: user.Username,
+					ClientIp: clientIp,
+					Reason:   failedLoginWrongPassword,
+				}
+				store.EXPECT().
+					CreateFailedLogin(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.FailedLogin{}, nil)
+				// the reserved attempt stays counted as a failure
+				store.EXPECT().
+					DeleteLoginThrottle(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					RefundLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.JSONEq(t, `{"error": "invalid username or password"}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "UnknownUser",
+			body: gin.H{"username": user.Username, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildReserveStubs(store, reserved, nil)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateFailedLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateFailedLoginParams) (db.FailedLogin, error) {
+						require.Equal(t, failedLoginUnknownUser, arg.Reason)
+						return db.FailedLogin{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				// the same response as for a wrong password
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.JSONEq(t, `{"error": "invalid username or password"}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "LockedOut",
+			body: gin.H{"username": user.Username, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				result := db.ReserveLoginAttemptTxResult{LockedUntil: time.Now().Add(2 * time.Minute)}
+				buildReserveStubs(store, result, db.ErrLoginLocked)
+				arg := db.CreateFailedLoginParams{
+					Username: user.Username,
+					ClientIp: clientIp,
+					Reason:   failedLoginLockedOut,
+				}
+				store.EXPECT().
+					CreateFailedLogin(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.FailedLogin{}, nil)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "120", recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "ReserveError",
+			body: gin.H{"username": user.Username, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildReserveStubs(store, db.ReserveLoginAttemptTxResult{}, sql.ErrConnDone)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{"username": user.Username, "password": "wrong-password"},
+			buildStubs: func(store *mockdb.MockStore) {
+				buildReserveStubs(store, reserved, nil)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateFailedLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FailedLogin{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api/users/login", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.RemoteAddr = clientIp + ":1234"
+			// the header isn't trusted, so the failures can't be counted for another ip
+			request.Header.Set("X-Forwarded-For", "198.51.100.7")
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestLogoutUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		refreshOwner  string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:         "OK",
+			refreshOwner: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					IsTokenRevoked(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(false, nil)
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{IsBlocked: true}, nil)
+				store.EXPECT().
+					CreateRevokedToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					DeleteExpiredRevokedTokens(gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:         "SessionOfAnotherUser",
+			refreshOwner: "another_user",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					IsTokenRevoked(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(false, nil)
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateRevokedToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "SessionNotFound",
+			refreshOwner: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					IsTokenRevoked(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(false, nil)
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateRevokedToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:         "NoAuthorization",
+			refreshOwner: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockSession(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateRevokedToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			refreshToken, _, err := server.tokenMaker.CreateToken(tc.refreshOwner, user.Role, token.TokenTypeRefreshToken, time.Hour)
+			require.NoError(t, err)
+			data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+			require.NoError(t, err)
+
+			url := "/api/users/logout"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
 func randomUser(t *testing.T) (user db.User, password string) {
 	password = util.RandomString(6)
 	hashedPassword, err := util.HashPassword(password)
@@ -276,9 +1049,10 @@ func randomUser(t *testing.T) (user db.User, password string) {
 		LastName:       util.RandomUserLastName(),
 		Gender:         "M",
 		Age:            int16(util.RandomUserAge()),
-		Balance:        util.RandomUserBalance(),
 		HashedPassword: hashedPassword,
 		Username:       util.RandomString(6),
+		Role:           util.CustomerRole,
+		Version:        1,
 	}
 
 	return
@@ -296,6 +1070,6 @@ func requireBodyMatchUser(t *testing.T, body *bytes.Buffer, user db.User) {
 	require.Equal(t, user.MiddleName, gotUser.MiddleName)
 	require.Equal(t, user.Gender, gotUser.Gender)
 	require.Equal(t, user.Age, gotUser.Age)
-	require.Equal(t, user.Balance, gotUser.Balance)
 	require.Equal(t, user.Username, gotUser.Username)
+	require.Equal(t, user.Role, gotUser.Role)
 }
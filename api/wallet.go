@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/gin-gonic/gin"
+)
+
+type walletResponse struct {
+	Currency  string     `json:"currency"`
+	Balance   util.Money `json:"balance"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func newWalletResponse(wallet db.Wallet) walletResponse {
+	return walletResponse{
+		Currency:  wallet.Currency,
+		Balance:   wallet.Balance,
+		CreatedAt: wallet.CreatedAt,
+	}
+}
+
+type listWalletRequest struct {
+	UserUuid int64 `uri:"id" binding:"required,min=1"`
+}
+
+// listWallet returns the wallets of the user, one for every currency the user has ever had money in
+func (server *Server) listWallet(ctx *gin.Context) {
+	var req listWalletRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, valid := server.validUser(ctx, req.UserUuid); !valid {
+		return
+	}
+
+	wallets, err := server.store.ListWallets(ctx, req.UserUuid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rsp := []walletResponse{}
+	for _, wallet := range wallets {
+		rsp = append(rsp, newWalletResponse(wallet))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
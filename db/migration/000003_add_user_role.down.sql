@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "User" DROP COLUMN IF EXISTS "Role";
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE "User" ADD COLUMN "Role" varchar NOT NULL DEFAULT 'customer';
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "Session";
\ No newline at end of file
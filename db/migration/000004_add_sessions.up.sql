@@ -0,0 +1,12 @@
+CREATE TABLE "Session" (
+  "Uuid" uuid PRIMARY KEY,
+  "Username" varchar NOT NULL,
+  "RefreshToken" varchar NOT NULL,
+  "UserAgent" varchar NOT NULL,
+  "ClientIp" varchar NOT NULL,
+  "IsBlocked" boolean NOT NULL DEFAULT false,
+  "ExpiresAt" timestamptz NOT NULL,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "Session" ADD FOREIGN KEY ("Username") REFERENCES "User" ("Username") ON DELETE CASCADE;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "RevokedToken";
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE "RevokedToken" (
+  "Uuid" uuid PRIMARY KEY,
+  "Username" varchar NOT NULL,
+  "ExpiresAt" timestamptz NOT NULL,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "RevokedToken" ("ExpiresAt");
+
+ALTER TABLE "RevokedToken" ADD FOREIGN KEY ("Username") REFERENCES "User" ("Username") ON DELETE CASCADE;
\ No newline at end of file
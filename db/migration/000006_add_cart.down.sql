@@ -0,0 +1,3 @@
+--Drop associative table first since there are foreign constraints:
+DROP TABLE IF EXISTS "CartItem";
+DROP TABLE IF EXISTS "Cart";
\ No newline at end of file
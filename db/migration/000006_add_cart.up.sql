@@ -0,0 +1,18 @@
+CREATE TABLE "Cart" (
+  "Uuid" bigserial PRIMARY KEY,
+  "UserUuid" bigint UNIQUE NOT NULL,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "CartItem" (
+  "CartUuid" bigint NOT NULL,
+  "ProductUuid" bigint NOT NULL,
+  "Quantity" integer NOT NULL CHECK ("Quantity" > 0),
+  PRIMARY KEY ("CartUuid", "ProductUuid")
+);
+
+ALTER TABLE "Cart" ADD FOREIGN KEY ("UserUuid") REFERENCES "User" ("Uuid") ON DELETE CASCADE;
+
+ALTER TABLE "CartItem" ADD FOREIGN KEY ("CartUuid") REFERENCES "Cart" ("Uuid") ON DELETE CASCADE;
+
+ALTER TABLE "CartItem" ADD FOREIGN KEY ("ProductUuid") REFERENCES "Product" ("Uuid") ON DELETE CASCADE;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "OrderProduct" DROP COLUMN IF EXISTS "Quantity";
+
+ALTER TABLE IF EXISTS "OrderProduct" DROP COLUMN IF EXISTS "UnitPrice";
+
+ALTER TABLE IF EXISTS "OrderProduct" DROP COLUMN IF EXISTS "Currency";
\ No newline at end of file
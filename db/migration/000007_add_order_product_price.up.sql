@@ -0,0 +1,20 @@
+ALTER TABLE "OrderProduct" ADD COLUMN "Quantity" integer NOT NULL DEFAULT 0;
+
+ALTER TABLE "OrderProduct" ADD COLUMN "UnitPrice" real NOT NULL DEFAULT 0;
+
+ALTER TABLE "OrderProduct" ADD COLUMN "Currency" varchar(3) NOT NULL DEFAULT 'USD';
+
+-- Orders placed so far had a single product, so the quantity of the order belongs to that line.
+-- The price paid wasn't stored, the current price of the product is the best we can do
+UPDATE "OrderProduct"
+  set "Quantity" = "Order"."Quantity",
+      "UnitPrice" = "Product"."Price"
+FROM "Order", "Product"
+WHERE "Order"."Uuid" = "OrderProduct"."OrderUuid"
+    AND "Product"."Uuid" = "OrderProduct"."ProductUuid";
+
+ALTER TABLE "OrderProduct" ALTER COLUMN "Quantity" DROP DEFAULT;
+
+ALTER TABLE "OrderProduct" ALTER COLUMN "UnitPrice" DROP DEFAULT;
+
+ALTER TABLE "OrderProduct" ALTER COLUMN "Currency" DROP DEFAULT;
\ No newline at end of file
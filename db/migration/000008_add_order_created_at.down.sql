@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "Order" DROP COLUMN IF EXISTS "CreatedAt";
\ No newline at end of file
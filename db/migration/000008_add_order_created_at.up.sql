@@ -0,0 +1,3 @@
+ALTER TABLE "Order" ADD COLUMN "CreatedAt" timestamptz NOT NULL DEFAULT (now());
+
+CREATE INDEX ON "Order" ("CreatedAt");
\ No newline at end of file
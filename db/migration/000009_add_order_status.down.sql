@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "Order" DROP CONSTRAINT IF EXISTS "Order_Status_check";
+
+ALTER TABLE IF EXISTS "Order" DROP COLUMN IF EXISTS "Status";
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE "Order" ADD COLUMN "Status" varchar NOT NULL DEFAULT 'paid';
+
+ALTER TABLE "Order" ADD CONSTRAINT "Order_Status_check"
+    CHECK ("Status" IN ('pending', 'paid', 'shipped', 'delivered', 'cancelled', 'refunded'));
+
+CREATE INDEX ON "Order" ("Status");
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE "OrderProduct" ALTER COLUMN "UnitPrice" TYPE real USING ("UnitPrice"::numeric / 100)::real;
+
+ALTER TABLE "Product" ALTER COLUMN "Price" TYPE real USING ("Price"::numeric / 100)::real;
+
+ALTER TABLE "User" ALTER COLUMN "Balance" DROP DEFAULT;
+ALTER TABLE "User" ALTER COLUMN "Balance" TYPE real USING ("Balance"::numeric / 100)::real;
+ALTER TABLE "User" ALTER COLUMN "Balance" SET DEFAULT 0;
\ No newline at end of file
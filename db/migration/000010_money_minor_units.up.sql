@@ -0,0 +1,8 @@
+-- money is stored in minor units (cents), so the arithmetic on balances and prices is exact
+ALTER TABLE "User" ALTER COLUMN "Balance" DROP DEFAULT;
+ALTER TABLE "User" ALTER COLUMN "Balance" TYPE bigint USING round("Balance"::numeric * 100)::bigint;
+ALTER TABLE "User" ALTER COLUMN "Balance" SET DEFAULT 0;
+
+ALTER TABLE "Product" ALTER COLUMN "Price" TYPE bigint USING round("Price"::numeric * 100)::bigint;
+
+ALTER TABLE "OrderProduct" ALTER COLUMN "UnitPrice" TYPE bigint USING round("UnitPrice"::numeric * 100)::bigint;
\ No newline at end of file
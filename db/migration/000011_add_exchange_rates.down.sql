@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "ExchangeRate";
\ No newline at end of file
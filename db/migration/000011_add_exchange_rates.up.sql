@@ -0,0 +1,13 @@
+CREATE TABLE "ExchangeRate" (
+  "Currency" varchar(3) PRIMARY KEY,
+  "Rate" numeric(20,10) NOT NULL,
+  "UpdatedAt" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "ExchangeRate_Rate_check" CHECK ("Rate" > 0)
+);
+
+-- The rate is the amount of the currency worth one unit of the base currency (USD).
+-- Cross rates between other currencies are calculated from these
+INSERT INTO "ExchangeRate" ("Currency", "Rate") VALUES
+  ('USD', 1),
+  ('EUR', 0.95),
+  ('RUB', 62.5);
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TABLE "User" ADD COLUMN "Balance" bigint NOT NULL DEFAULT 0;
+
+-- Only the USD wallets can be restored, the other currencies are lost
+UPDATE "User"
+  set "Balance" = "Wallet"."Balance"
+FROM "Wallet"
+WHERE "Wallet"."UserUuid" = "User"."Uuid"
+    AND "Wallet"."Currency" = 'USD';
+
+DROP TABLE IF EXISTS "Wallet";
\ No newline at end of file
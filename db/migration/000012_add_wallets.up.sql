@@ -0,0 +1,19 @@
+CREATE TABLE "Wallet" (
+  "UserUuid" bigint NOT NULL,
+  "Currency" varchar(3) NOT NULL,
+  "Balance" bigint NOT NULL DEFAULT 0,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("UserUuid", "Currency"),
+  CONSTRAINT "Wallet_Balance_check" CHECK ("Balance" >= 0)
+);
+
+ALTER TABLE "Wallet" ADD FOREIGN KEY ("UserUuid") REFERENCES "User" ("Uuid") ON DELETE CASCADE;
+
+ALTER TABLE "Wallet" ADD FOREIGN KEY ("Currency") REFERENCES "ExchangeRate" ("Currency");
+
+-- The balance of every user was implicitly in USD, so it becomes the USD wallet of the user
+INSERT INTO "Wallet" ("UserUuid", "Currency", "Balance")
+SELECT "Uuid", 'USD', "Balance" FROM "User"
+WHERE "Balance" > 0;
+
+ALTER TABLE "User" DROP COLUMN "Balance";
\ No newline at end of file
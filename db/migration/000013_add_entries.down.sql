@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "Entry";
\ No newline at end of file
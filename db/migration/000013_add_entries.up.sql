@@ -0,0 +1,26 @@
+CREATE TABLE "Entry" (
+  "Uuid" bigserial PRIMARY KEY,
+  "UserUuid" bigint NOT NULL,
+  "Currency" varchar(3) NOT NULL,
+  "Amount" bigint NOT NULL,
+  "Kind" varchar NOT NULL,
+  "OrderUuid" bigint,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "Entry_Amount_check" CHECK ("Amount" <> 0)
+);
+
+-- The ledger is append-only: entries are never updated, the balance of a wallet
+-- is always the sum of the amounts of its entries
+ALTER TABLE "Entry" ADD CONSTRAINT "Entry_Kind_check"
+    CHECK ("Kind" IN ('opening', 'top_up', 'withdrawal', 'transfer', 'purchase', 'refund'));
+
+ALTER TABLE "Entry" ADD FOREIGN KEY ("UserUuid", "Currency") REFERENCES "Wallet" ("UserUuid", "Currency") ON DELETE CASCADE;
+
+ALTER TABLE "Entry" ADD FOREIGN KEY ("OrderUuid") REFERENCES "Order" ("Uuid") ON DELETE SET NULL;
+
+CREATE INDEX ON "Entry" ("UserUuid", "Currency", "Uuid");
+
+-- The money which is already in the wallets has no history, so it is recorded as their opening balance
+INSERT INTO "Entry" ("UserUuid", "Currency", "Amount", "Kind")
+SELECT "UserUuid", "Currency", "Balance", 'opening' FROM "Wallet"
+WHERE "Balance" > 0;
\ No newline at end of file
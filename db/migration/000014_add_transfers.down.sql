@@ -0,0 +1,3 @@
+ALTER TABLE "Entry" DROP COLUMN IF EXISTS "TransferUuid";
+
+DROP TABLE IF EXISTS "Transfer";
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE TABLE "Transfer" (
+  "Uuid" bigserial PRIMARY KEY,
+  "FromUserUuid" bigint NOT NULL,
+  "ToUserUuid" bigint NOT NULL,
+  "Currency" varchar(3) NOT NULL,
+  "Amount" bigint NOT NULL,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "Transfer_Amount_check" CHECK ("Amount" > 0),
+  CONSTRAINT "Transfer_Users_check" CHECK ("FromUserUuid" <> "ToUserUuid")
+);
+
+ALTER TABLE "Transfer" ADD FOREIGN KEY ("FromUserUuid") REFERENCES "User" ("Uuid") ON DELETE CASCADE;
+
+ALTER TABLE "Transfer" ADD FOREIGN KEY ("ToUserUuid") REFERENCES "User" ("Uuid") ON DELETE CASCADE;
+
+ALTER TABLE "Transfer" ADD FOREIGN KEY ("Currency") REFERENCES "ExchangeRate" ("Currency");
+
+CREATE INDEX ON "Transfer" ("FromUserUuid");
+
+CREATE INDEX ON "Transfer" ("ToUserUuid");
+
+-- Both entries of a transfer point to it, so each side of the transfer can be found from the other
+ALTER TABLE "Entry" ADD COLUMN "TransferUuid" bigint;
+
+ALTER TABLE "Entry" ADD FOREIGN KEY ("TransferUuid") REFERENCES "Transfer" ("Uuid") ON DELETE SET NULL;
\ No newline at end of file
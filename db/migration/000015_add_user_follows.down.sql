@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "UserToUser_SecondUserUuid_idx";
+
+ALTER TABLE "UserToUser" DROP COLUMN IF EXISTS "CreatedAt";
+
+ALTER TABLE "UserToUser" DROP CONSTRAINT IF EXISTS "UserToUser_Self_check";
+
+ALTER TABLE "UserToUser" DROP CONSTRAINT IF EXISTS "UserToUser_pkey";
\ No newline at end of file
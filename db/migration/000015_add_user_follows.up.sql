@@ -0,0 +1,20 @@
+-- "FirstUserUuid" follows "SecondUserUuid".
+-- A user can follow another user only once and can't follow themselves
+DELETE FROM "UserToUser" a
+USING "UserToUser" b
+WHERE a.ctid < b.ctid
+    AND a."FirstUserUuid" = b."FirstUserUuid"
+    AND a."SecondUserUuid" = b."SecondUserUuid";
+
+DELETE FROM "UserToUser"
+WHERE "FirstUserUuid" = "SecondUserUuid";
+
+ALTER TABLE "UserToUser" ADD PRIMARY KEY ("FirstUserUuid", "SecondUserUuid");
+
+ALTER TABLE "UserToUser" ADD CONSTRAINT "UserToUser_Self_check"
+    CHECK ("FirstUserUuid" <> "SecondUserUuid");
+
+ALTER TABLE "UserToUser" ADD COLUMN "CreatedAt" timestamptz NOT NULL DEFAULT (now());
+
+-- The primary key already serves the lookups of the users someone follows, this one serves their followers
+CREATE INDEX ON "UserToUser" ("SecondUserUuid");
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "Order_UserUuid_CreatedAt_Uuid_idx";
+
+DROP TABLE IF EXISTS "PrivacySetting";
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE "PrivacySetting" (
+  "UserUuid" bigint PRIMARY KEY,
+  "PurchaseVisibility" varchar NOT NULL DEFAULT 'followers',
+  "UpdatedAt" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- Users without settings show their purchases to their followers
+ALTER TABLE "PrivacySetting" ADD CONSTRAINT "PrivacySetting_PurchaseVisibility_check"
+    CHECK ("PurchaseVisibility" IN ('followers', 'mutual', 'private'));
+
+ALTER TABLE "PrivacySetting" ADD FOREIGN KEY ("UserUuid") REFERENCES "User" ("Uuid") ON DELETE CASCADE;
+
+-- The feed reads the orders of a user from the newest to the oldest
+CREATE INDEX ON "Order" ("UserUuid", "CreatedAt" DESC, "Uuid" DESC);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "Product_Price_idx";
+
+DROP INDEX IF EXISTS "Product_Description_search_idx";
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- The search matches the description with the english dictionary, so the index is built the same way
+CREATE INDEX "Product_Description_search_idx" ON "Product" USING GIN (to_tsvector('english', "Description"));
+
+CREATE INDEX ON "Product" ("Price");
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE "Product" DROP COLUMN IF EXISTS "CategoryUuid";
+
+DROP TABLE IF EXISTS "ProductVariant";
+
+DROP TABLE IF EXISTS "Category";
\ No newline at end of file
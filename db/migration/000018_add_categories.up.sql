@@ -0,0 +1,26 @@
+CREATE TABLE "Category" (
+  "Uuid" bigserial PRIMARY KEY,
+  "Name" varchar UNIQUE NOT NULL,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- A variant tells the attributes a product comes with, accessories without a storage have a zero one
+CREATE TABLE "ProductVariant" (
+  "Uuid" bigserial PRIMARY KEY,
+  "ProductUuid" bigint NOT NULL,
+  "Model" varchar NOT NULL,
+  "Color" varchar NOT NULL DEFAULT '',
+  "StorageGb" integer NOT NULL DEFAULT 0,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "ProductVariant_StorageGb_check" CHECK ("StorageGb" >= 0),
+  CONSTRAINT "ProductVariant_Attributes_key" UNIQUE ("ProductUuid", "Model", "Color", "StorageGb")
+);
+
+ALTER TABLE "ProductVariant" ADD FOREIGN KEY ("ProductUuid") REFERENCES "Product" ("Uuid") ON DELETE CASCADE;
+
+-- The products of a deleted category are left without a category
+ALTER TABLE "Product" ADD COLUMN "CategoryUuid" bigint;
+
+ALTER TABLE "Product" ADD FOREIGN KEY ("CategoryUuid") REFERENCES "Category" ("Uuid") ON DELETE SET NULL;
+
+CREATE INDEX ON "Product" ("CategoryUuid");
\ No newline at end of file
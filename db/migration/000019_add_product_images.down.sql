@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "ProductImage";
\ No newline at end of file
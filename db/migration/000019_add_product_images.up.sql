@@ -0,0 +1,12 @@
+-- The original image and its thumbnail are kept in the storage under the keys
+CREATE TABLE "ProductImage" (
+  "Uuid" bigserial PRIMARY KEY,
+  "ProductUuid" bigint NOT NULL,
+  "Key" varchar NOT NULL,
+  "ThumbnailKey" varchar NOT NULL,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "ProductImage" ADD FOREIGN KEY ("ProductUuid") REFERENCES "Product" ("Uuid") ON DELETE CASCADE;
+
+CREATE INDEX ON "ProductImage" ("ProductUuid");
\ No newline at end of file
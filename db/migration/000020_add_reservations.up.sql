@@ -0,0 +1,20 @@
+-- A reservation holds the units of a product for the user until it expires, a user has one reservation per product
+CREATE TABLE "Reservation" (
+  "Uuid" bigserial PRIMARY KEY,
+  "UserUuid" bigint NOT NULL,
+  "ProductUuid" bigint NOT NULL,
+  "Quantity" integer NOT NULL,
+  "ExpiresAt" timestamptz NOT NULL,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "Reservation_Quantity_check" CHECK ("Quantity" > 0),
+  CONSTRAINT "Reservation_User_Product_key" UNIQUE ("UserUuid", "ProductUuid")
+);
+
+ALTER TABLE "Reservation" ADD FOREIGN KEY ("UserUuid") REFERENCES "User" ("Uuid") ON DELETE CASCADE;
+
+ALTER TABLE "Reservation" ADD FOREIGN KEY ("ProductUuid") REFERENCES "Product" ("Uuid") ON DELETE CASCADE;
+
+-- The availability sums the active reservations of a product, the sweeper deletes the expired ones
+CREATE INDEX ON "Reservation" ("ProductUuid", "ExpiresAt");
+
+CREATE INDEX ON "Reservation" ("ExpiresAt");
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "IdempotencyKey";
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- The response of a request is kept for the user and the key, so a retry of the request gets it back
+-- instead of running the request again. The response is empty while the first request is running
+CREATE TABLE "IdempotencyKey" (
+  "Uuid" bigserial PRIMARY KEY,
+  "UserUuid" bigint NOT NULL,
+  "Key" varchar NOT NULL,
+  "RequestHash" varchar NOT NULL,
+  "StatusCode" integer NOT NULL DEFAULT 0,
+  "ResponseBody" bytea,
+  "ExpiresAt" timestamptz NOT NULL,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "IdempotencyKey_User_Key_key" UNIQUE ("UserUuid", "Key")
+);
+
+ALTER TABLE "IdempotencyKey" ADD FOREIGN KEY ("UserUuid") REFERENCES "User" ("Uuid") ON DELETE CASCADE;
+
+CREATE INDEX ON "IdempotencyKey" ("ExpiresAt");
\ No newline at end of file
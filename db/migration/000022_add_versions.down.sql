@@ -0,0 +1,3 @@
+ALTER TABLE "Product" DROP COLUMN IF EXISTS "Version";
+
+ALTER TABLE "User" DROP COLUMN IF EXISTS "Version";
\ No newline at end of file
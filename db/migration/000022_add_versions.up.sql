@@ -0,0 +1,4 @@
+-- The version is raised by every change of a row, so a client can tell if the row changed since it was read
+ALTER TABLE "User" ADD COLUMN "Version" integer NOT NULL DEFAULT 1;
+
+ALTER TABLE "Product" ADD COLUMN "Version" integer NOT NULL DEFAULT 1;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "PasswordResetToken";
\ No newline at end of file
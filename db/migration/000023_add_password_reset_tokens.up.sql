@@ -0,0 +1,15 @@
+-- Only the hash of a reset token is kept, so the tokens can't be used by someone reading the table.
+-- A token can be used once before it expires
+CREATE TABLE "PasswordResetToken" (
+  "Uuid" bigserial PRIMARY KEY,
+  "UserUuid" bigint NOT NULL,
+  "TokenHash" varchar NOT NULL,
+  "ExpiresAt" timestamptz NOT NULL,
+  "UsedAt" timestamptz,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "PasswordResetToken_TokenHash_key" UNIQUE ("TokenHash")
+);
+
+ALTER TABLE "PasswordResetToken" ADD FOREIGN KEY ("UserUuid") REFERENCES "User" ("Uuid") ON DELETE CASCADE;
+
+CREATE INDEX ON "PasswordResetToken" ("UserUuid");
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "LoginThrottle";
+DROP TABLE IF EXISTS "FailedLogin";
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- Every failed login is kept for the audit, also the ones refused during a lockout.
+-- The username doesn't have to belong to a user, so there is no foreign key
+CREATE TABLE "FailedLogin" (
+  "Uuid" bigserial PRIMARY KEY,
+  "Username" varchar NOT NULL,
+  "ClientIp" varchar NOT NULL,
+  "UserAgent" varchar NOT NULL,
+  "Reason" varchar NOT NULL,
+  "CreatedAt" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- The failed logins in a row of a username or of a client ip, the key is like "username:alice" or "ip:127.0.0.1"
+CREATE TABLE "LoginThrottle" (
+  "Key" varchar PRIMARY KEY,
+  "Failures" integer NOT NULL DEFAULT 0,
+  "UpdatedAt" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "FailedLogin" ("Username", "CreatedAt");
+
+CREATE INDEX ON "FailedLogin" ("ClientIp", "CreatedAt");
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "UserTokenRevocation";
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- All the tokens of a user issued before "IssuedBefore" are revoked at once, like when the password is reset
+CREATE TABLE "UserTokenRevocation" (
+  "Username" varchar PRIMARY KEY,
+  "IssuedBefore" timestamptz NOT NULL
+);
+
+ALTER TABLE "UserTokenRevocation" ADD FOREIGN KEY ("Username") REFERENCES "User" ("Username") ON DELETE CASCADE;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "OrderProduct" DROP CONSTRAINT IF EXISTS "OrderProduct_Quantity_check";
\ No newline at end of file
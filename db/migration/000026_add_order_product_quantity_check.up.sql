@@ -0,0 +1,2 @@
+-- The check isn't validated against the lines stored so far, which may have been created with a quantity of 0 before it was checked
+ALTER TABLE "OrderProduct" ADD CONSTRAINT "OrderProduct_Quantity_check" CHECK ("Quantity" > 0) NOT VALID;
\ No newline at end of file
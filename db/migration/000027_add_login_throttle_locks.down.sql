@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "LoginThrottle" DROP COLUMN IF EXISTS "LockedUntil";
\ No newline at end of file
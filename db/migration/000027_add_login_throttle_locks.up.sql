@@ -0,0 +1,3 @@
+-- A key is locked out until "LockedUntil" as soon as an attempt reaches the max failures, so the concurrent attempts are refused
+-- while the password is still being checked
+ALTER TABLE "LoginThrottle" ADD COLUMN "LockedUntil" timestamptz;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "LoginThrottle" DROP COLUMN IF EXISTS "LastFailureAt";
\ No newline at end of file
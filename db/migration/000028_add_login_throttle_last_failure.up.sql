@@ -0,0 +1,7 @@
+-- The failures are counted in the window after the last failure, "UpdatedAt" is moved by every attempt including the
+-- successful ones, so steady traffic from a client ip would never let its failures reset
+ALTER TABLE "LoginThrottle" ADD COLUMN "LastFailureAt" timestamptz;
+
+UPDATE "LoginThrottle" SET "LastFailureAt" = "UpdatedAt" WHERE "Failures" > 0;
+
+CREATE INDEX ON "LoginThrottle" ("LastFailureAt");
\ No newline at end of file
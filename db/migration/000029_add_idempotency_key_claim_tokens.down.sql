@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "IdempotencyKey" DROP COLUMN IF EXISTS "ClaimToken";
\ No newline at end of file
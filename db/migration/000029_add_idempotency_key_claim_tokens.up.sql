@@ -0,0 +1,3 @@
+-- Every claim of a key gets a token of its own, so the request that claimed a key before it went stale
+-- can't store its response into or release the claim of the retry that reclaimed the key
+ALTER TABLE "IdempotencyKey" ADD COLUMN "ClaimToken" uuid NOT NULL DEFAULT (gen_random_uuid());
\ No newline at end of file
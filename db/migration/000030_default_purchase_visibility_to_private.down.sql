@@ -0,0 +1 @@
+ALTER TABLE "PrivacySetting" ALTER COLUMN "PurchaseVisibility" SET DEFAULT 'followers';
\ No newline at end of file
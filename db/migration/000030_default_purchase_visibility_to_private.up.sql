@@ -0,0 +1,2 @@
+-- The purchases are only shared once the user chooses to, a user without settings shows them to nobody
+ALTER TABLE "PrivacySetting" ALTER COLUMN "PurchaseVisibility" SET DEFAULT 'private';
\ No newline at end of file
@@ -0,0 +1,55 @@
+-- Every user has a single cart, so it is created the first time it is needed
+-- name: GetOrCreateCart :one
+INSERT INTO "Cart" (
+    "UserUuid")
+VALUES (
+    $1
+)
+ON CONFLICT ("UserUuid") DO UPDATE
+  set "UserUuid" = EXCLUDED."UserUuid"
+RETURNING *;
+
+-- name: GetCartByUserUuid :one
+SELECT * FROM "Cart"
+WHERE "UserUuid" = $1 LIMIT 1;
+
+-- The cart is locked before its items are read, so concurrent checkouts of the same cart run one after the other
+-- name: GetCartByUserUuidForUpdate :one
+SELECT * FROM "Cart"
+WHERE "UserUuid" = $1 LIMIT 1
+FOR UPDATE;
+
+-- Adding a product which is already in the cart increases its quantity
+-- name: AddCartItem :one
+INSERT INTO "CartItem" (
+    "CartUuid",
+    "ProductUuid",
+    "Quantity")
+VALUES (
+    $1, $2, $3
+)
+ON CONFLICT ("CartUuid", "ProductUuid") DO UPDATE
+  set "Quantity" = "CartItem"."Quantity" + EXCLUDED."Quantity"
+RETURNING *;
+
+-- Items are ordered by "ProductUuid" so that the checkout locks the products in a deterministic order
+-- name: ListCartItems :many
+SELECT * FROM "CartItem"
+WHERE "CartUuid" = $1
+ORDER BY "ProductUuid";
+
+-- name: UpdateCartItem :one
+UPDATE "CartItem"
+  set "Quantity" = $3
+WHERE "CartUuid" = $1
+    AND "ProductUuid" = $2
+RETURNING *;
+
+-- name: DeleteCartItem :execrows
+DELETE FROM "CartItem"
+WHERE "CartUuid" = $1
+    AND "ProductUuid" = $2;
+
+-- name: ClearCart :exec
+DELETE FROM "CartItem"
+WHERE "CartUuid" = $1;
\ No newline at end of file
@@ -0,0 +1,25 @@
+-- name: CreateCategory :one
+INSERT INTO "Category" (
+    "Name"
+) VALUES (
+    $1
+)
+RETURNING *;
+
+-- name: GetCategory :one
+SELECT * FROM "Category"
+WHERE "Uuid" = $1 LIMIT 1;
+
+-- name: ListCategories :many
+SELECT * FROM "Category"
+ORDER BY "Name";
+
+-- name: UpdateCategory :one
+UPDATE "Category"
+    set "Name" = $2
+WHERE "Uuid" = $1
+RETURNING *;
+
+-- name: DeleteCategory :execrows
+DELETE FROM "Category"
+WHERE "Uuid" = $1;
\ No newline at end of file
@@ -0,0 +1,34 @@
+-- name: CreateEntry :one
+INSERT INTO "Entry" (
+    "UserUuid",
+    "Currency",
+    "Amount",
+    "Kind",
+    "OrderUuid",
+    "TransferUuid")
+VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: ListEntries :many
+SELECT * FROM "Entry"
+WHERE "UserUuid" = sqlc.arg(user_uuid)
+    AND (sqlc.narg(currency)::varchar IS NULL OR "Currency" = sqlc.narg(currency))
+ORDER BY "Uuid" DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- The balance of a wallet is always equal to the sum of the amounts of its entries,
+-- a "LedgerBalance" different from the "Balance" means the wallet was changed outside of the ledger
+-- name: ReconcileWallets :many
+SELECT
+"Wallet"."UserUuid",
+"Wallet"."Currency",
+"Wallet"."Balance",
+COALESCE(SUM("Entry"."Amount"), 0)::bigint AS "LedgerBalance"
+FROM "Wallet"
+LEFT JOIN "Entry" ON "Entry"."UserUuid" = "Wallet"."UserUuid" AND "Entry"."Currency" = "Wallet"."Currency"
+WHERE "Wallet"."UserUuid" = $1
+GROUP BY "Wallet"."UserUuid", "Wallet"."Currency"
+ORDER BY "Wallet"."Currency";
\ No newline at end of file
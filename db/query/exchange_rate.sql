@@ -0,0 +1,20 @@
+-- name: GetExchangeRate :one
+SELECT * FROM "ExchangeRate"
+WHERE "Currency" = $1 LIMIT 1;
+
+-- name: ListExchangeRates :many
+SELECT * FROM "ExchangeRate"
+ORDER BY "Currency";
+
+-- Setting the rate of a new currency makes it supported
+-- name: UpsertExchangeRate :one
+INSERT INTO "ExchangeRate" (
+    "Currency",
+    "Rate")
+VALUES (
+    $1, $2
+)
+ON CONFLICT ("Currency") DO UPDATE
+  set "Rate" = EXCLUDED."Rate",
+      "UpdatedAt" = now()
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- name: CreateFailedLogin :one
+INSERT INTO "FailedLogin" (
+    "Username",
+    "ClientIp",
+    "UserAgent",
+    "Reason"
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: ListFailedLogins :many
+SELECT * FROM "FailedLogin"
+WHERE (sqlc.narg(username)::varchar IS NULL OR "Username" = sqlc.narg(username))
+    AND (sqlc.narg(client_ip)::varchar IS NULL OR "ClientIp" = sqlc.narg(client_ip))
+ORDER BY "Uuid" DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
\ No newline at end of file
@@ -0,0 +1,37 @@
+-- The feed of a user shows the purchases of the users they follow, newest first.
+-- Users show their purchases to all their followers with "followers", only to the followers they follow back
+-- with "mutual" and to nobody with "private" or without privacy settings. The page starts after the (created at, uuid) cursor
+-- name: ListFeed :many
+SELECT
+"Order"."Uuid",
+"Order"."UserUuid",
+"Order"."CreatedAt",
+"User"."Username",
+"User"."FullName"
+FROM "Order"
+INNER JOIN "UserToUser" ON "UserToUser"."SecondUserUuid" = "Order"."UserUuid"
+INNER JOIN "User" ON "User"."Uuid" = "Order"."UserUuid"
+LEFT JOIN "PrivacySetting" ON "PrivacySetting"."UserUuid" = "Order"."UserUuid"
+WHERE "UserToUser"."FirstUserUuid" = sqlc.arg(follower_uuid)
+    AND "Order"."Status" IN ('paid', 'shipped', 'delivered')
+    AND ("PrivacySetting"."PurchaseVisibility" = 'followers'
+        OR ("PrivacySetting"."PurchaseVisibility" = 'mutual' AND EXISTS (
+            SELECT 1 FROM "UserToUser" AS "FollowBack"
+            WHERE "FollowBack"."FirstUserUuid" = "Order"."UserUuid"
+                AND "FollowBack"."SecondUserUuid" = sqlc.arg(follower_uuid))))
+    AND (sqlc.narg(before_created_at)::timestamptz IS NULL
+        OR ("Order"."CreatedAt", "Order"."Uuid") < (sqlc.narg(before_created_at), sqlc.narg(before_uuid)::bigint))
+ORDER BY "Order"."CreatedAt" DESC, "Order"."Uuid" DESC
+LIMIT sqlc.arg('limit');
+
+-- Only what was bought is shown in the feed, the prices stay private
+-- name: ListFeedLines :many
+SELECT
+"OrderProduct"."OrderUuid",
+"OrderProduct"."ProductUuid",
+"Product"."Description" AS "ProductDescription",
+"OrderProduct"."Quantity"
+FROM "OrderProduct"
+INNER JOIN "Product" ON "Product"."Uuid" = "OrderProduct"."ProductUuid"
+WHERE "OrderProduct"."OrderUuid" = ANY(sqlc.arg(order_uuids)::bigint[])
+ORDER BY "OrderProduct"."OrderUuid", "OrderProduct"."ProductUuid";
\ No newline at end of file
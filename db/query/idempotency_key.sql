@@ -0,0 +1,42 @@
+-- The key is only claimed when the user hasn't used it yet, its response has expired
+-- or the request that claimed it is still in progress since before stale_before, like when the server crashed,
+-- otherwise no row is returned
+-- name: CreateIdempotencyKey :one
+INSERT INTO "IdempotencyKey" (
+    "UserUuid",
+    "Key",
+    "RequestHash",
+    "ExpiresAt",
+    "ClaimToken"
+) VALUES (
+    sqlc.arg(user_uuid), sqlc.arg(key), sqlc.arg(request_hash), sqlc.arg(expires_at), sqlc.arg(claim_token)
+)
+ON CONFLICT ("UserUuid", "Key") DO UPDATE
+    SET "RequestHash" = EXCLUDED."RequestHash",
+        "StatusCode" = 0,
+        "ResponseBody" = NULL,
+        "ExpiresAt" = EXCLUDED."ExpiresAt",
+        "ClaimToken" = EXCLUDED."ClaimToken",
+        "CreatedAt" = now()
+    WHERE "IdempotencyKey"."ExpiresAt" <= now()
+        OR ("IdempotencyKey"."StatusCode" = 0 AND "IdempotencyKey"."CreatedAt" < sqlc.arg(stale_before))
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM "IdempotencyKey"
+WHERE "UserUuid" = $1 AND "Key" = $2 LIMIT 1;
+
+-- Nothing is changed when the key was claimed again by another request in the meantime
+-- name: UpdateIdempotencyKeyResponse :exec
+UPDATE "IdempotencyKey"
+SET "StatusCode" = $3, "ResponseBody" = $4
+WHERE "Uuid" = $1 AND "ClaimToken" = $2;
+
+-- Nothing is deleted when the key was claimed again by another request in the meantime
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM "IdempotencyKey"
+WHERE "Uuid" = $1 AND "ClaimToken" = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM "IdempotencyKey"
+WHERE "ExpiresAt" <= now();
\ No newline at end of file
@@ -0,0 +1,59 @@
+-- name: ListLoginThrottles :many
+SELECT * FROM "LoginThrottle"
+WHERE "Key" = ANY(sqlc.arg(keys)::varchar[]);
+
+-- name: GetLoginThrottleForUpdate :one
+SELECT * FROM "LoginThrottle"
+WHERE "Key" = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- The attempt is counted as a failure before the password is checked. The failures are counted again from one
+-- when the last one is older than reset_before. No row is returned while the key is locked out
+-- name: ReserveLoginAttempt :one
+INSERT INTO "LoginThrottle" (
+    "Key",
+    "Failures",
+    "LastFailureAt"
+) VALUES (
+    sqlc.arg(key), 1, now()
+)
+ON CONFLICT ("Key") DO UPDATE
+SET "Failures" = CASE
+        WHEN "LoginThrottle"."LastFailureAt" IS NULL OR "LoginThrottle"."LastFailureAt" < sqlc.arg(reset_before)::timestamptz THEN 1
+        ELSE "LoginThrottle"."Failures" + 1
+    END,
+    "LastFailureAt" = now(),
+    "UpdatedAt" = now()
+WHERE "LoginThrottle"."LockedUntil" IS NULL OR "LoginThrottle"."LockedUntil" <= now()
+RETURNING *;
+
+-- name: LockLoginThrottle :one
+UPDATE "LoginThrottle"
+SET "LockedUntil" = sqlc.arg(locked_until)
+WHERE "Key" = sqlc.arg(key)
+RETURNING *;
+
+-- The attempt of a successful login isn't a failure, the last failure before it is put back unless another attempt
+-- was counted in the meantime. The lock is only lifted when the attempt took it, a lock taken by another attempt stays
+-- name: RefundLoginAttempt :exec
+UPDATE "LoginThrottle"
+SET "Failures" = GREATEST("Failures" - 1, 0),
+    "LastFailureAt" = CASE
+        WHEN "LastFailureAt" = sqlc.narg(failure_at) THEN sqlc.narg(previous_failure_at)
+        ELSE "LastFailureAt"
+    END,
+    "LockedUntil" = CASE
+        WHEN "LockedUntil" = sqlc.narg(locked_until) THEN NULL
+        ELSE "LockedUntil"
+    END
+WHERE "Key" = sqlc.arg(key);
+
+-- name: DeleteLoginThrottle :exec
+DELETE FROM "LoginThrottle"
+WHERE "Key" = $1;
+
+-- The keys without a failure since reset_before would be counted from one again anyway, the locked out keys are kept
+-- name: DeleteStaleLoginThrottles :execrows
+DELETE FROM "LoginThrottle"
+WHERE ("LastFailureAt" IS NULL OR "LastFailureAt" < sqlc.arg(reset_before)::timestamptz)
+  AND ("LockedUntil" IS NULL OR "LockedUntil" <= now());
\ No newline at end of file
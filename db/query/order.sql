@@ -11,6 +11,12 @@ RETURNING *;
 SELECT * FROM "Order"
 WHERE "Uuid" = $1 LIMIT 1;
 
+-- This will allow us to block transactions till the end of commit
+-- name: GetOrderForUpdate :one
+SELECT * FROM "Order"
+WHERE "Uuid" = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
 -- name: ListOrders :many
 SELECT * FROM "Order"
 ORDER BY "Uuid"
@@ -28,14 +34,69 @@ RETURNING *;
 DELETE FROM "Order"
 WHERE "Uuid" = $1;
 
--- TODO: Read the doc of sqlc to determine the way how to retrieve many2many data
--- SELECT 
--- "Order"."Uuid" AS "Order Uuid",
--- (SELECT "User"."FullName" FROM "User" WHERE "Order"."UserUuid" = "User"."Uuid") AS "User Name",
--- "Order"."Quantity" AS "Order Qty", 
--- "Product"."Uuid" AS "Product Uuid",
--- "Product"."Description" AS "Product Description"
--- FROM "OrderProduct"
--- INNER JOIN "Order" ON "Order"."Uuid" = "OrderProduct"."OrderUuid"
--- INNER JOIN "Product" ON "Product"."Uuid" = "OrderProduct"."ProductUuid"
--- WHERE "Order"."Uuid" = 1;dd
\ No newline at end of file
+-- name: ListOrdersByFilter :many
+SELECT * FROM "Order"
+WHERE (sqlc.narg(user_uuid)::bigint IS NULL OR "UserUuid" = sqlc.narg(user_uuid))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR "CreatedAt" >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR "CreatedAt" < sqlc.narg(created_to))
+ORDER BY "Uuid"
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetOrderDetails :one
+SELECT
+"Order"."Uuid",
+"Order"."UserUuid",
+"Order"."Quantity",
+"Order"."CreatedAt",
+"Order"."Status",
+"User"."FullName" AS "UserFullName"
+FROM "Order"
+INNER JOIN "User" ON "User"."Uuid" = "Order"."UserUuid"
+WHERE "Order"."Uuid" = $1 LIMIT 1;
+
+-- name: ListOrderLines :many
+SELECT
+"OrderProduct"."ProductUuid",
+"Product"."Description" AS "ProductDescription",
+"OrderProduct"."Quantity",
+"OrderProduct"."UnitPrice",
+"OrderProduct"."Currency"
+FROM "OrderProduct"
+INNER JOIN "Product" ON "Product"."Uuid" = "OrderProduct"."ProductUuid"
+WHERE "OrderProduct"."OrderUuid" = $1
+ORDER BY "OrderProduct"."ProductUuid";
+
+-- The status is only changed if nobody changed it since it was read
+-- name: UpdateOrderStatus :one
+UPDATE "Order"
+  set "Status" = sqlc.arg(status)
+WHERE "Uuid" = sqlc.arg(uuid)
+    AND "Status" = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: CountOrdersByFilter :one
+SELECT count(*) FROM "Order"
+WHERE (sqlc.narg(user_uuid)::bigint IS NULL OR "UserUuid" = sqlc.narg(user_uuid))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR "CreatedAt" >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR "CreatedAt" < sqlc.narg(created_to));
+
+-- The page starts right after the order with the uuid, 0 starts from the first order
+-- name: ListOrdersAfter :many
+SELECT * FROM "Order"
+WHERE (sqlc.narg(user_uuid)::bigint IS NULL OR "UserUuid" = sqlc.narg(user_uuid))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR "CreatedAt" >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR "CreatedAt" < sqlc.narg(created_to))
+    AND "Uuid" > sqlc.arg(after_uuid)
+ORDER BY "Uuid"
+LIMIT sqlc.arg('limit');
+
+-- The page ends right before the order with the uuid, so the orders come in the reverse order
+-- name: ListOrdersBefore :many
+SELECT * FROM "Order"
+WHERE (sqlc.narg(user_uuid)::bigint IS NULL OR "UserUuid" = sqlc.narg(user_uuid))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR "CreatedAt" >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR "CreatedAt" < sqlc.narg(created_to))
+    AND "Uuid" < sqlc.arg(before_uuid)
+ORDER BY "Uuid" DESC
+LIMIT sqlc.arg('limit');
\ No newline at end of file
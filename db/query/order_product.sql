@@ -1,10 +1,12 @@
 -- name: CreateOrderProduct :one
 INSERT INTO "OrderProduct" (
 	"OrderUuid",
-    "ProductUuid") 
+    "ProductUuid",
+    "Quantity",
+    "UnitPrice",
+    "Currency") 
 VALUES (
-    $1,
-    $2
+    $1, $2, $3, $4, $5
 )
 RETURNING *;
 
@@ -14,6 +16,11 @@ WHERE "OrderUuid" = $1
     AND "ProductUuid" = $2 
 LIMIT 1;
 
+-- name: ListOrderProductsByOrder :many
+SELECT * FROM "OrderProduct"
+WHERE "OrderUuid" = $1
+ORDER BY "ProductUuid";
+
 -- name: ListOrderProducts :many
 SELECT * FROM "OrderProduct"
 ORDER BY "OrderUuid"
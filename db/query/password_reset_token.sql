@@ -0,0 +1,23 @@
+-- name: CreatePasswordResetToken :one
+INSERT INTO "PasswordResetToken" (
+    "UserUuid",
+    "TokenHash",
+    "ExpiresAt"
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
+-- The token is marked as used at once, so two requests with the same token can't both reset the password.
+-- No row is returned for a token that is unknown, used or expired
+-- name: UsePasswordResetToken :one
+UPDATE "PasswordResetToken"
+SET "UsedAt" = now()
+WHERE "TokenHash" = $1 AND "UsedAt" IS NULL AND "ExpiresAt" > now()
+RETURNING *;
+
+-- The other tokens of the user can't be used once the password is reset
+-- name: UseUserPasswordResetTokens :exec
+UPDATE "PasswordResetToken"
+SET "UsedAt" = now()
+WHERE "UserUuid" = $1 AND "UsedAt" IS NULL;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- name: GetPrivacySetting :one
+SELECT * FROM "PrivacySetting"
+WHERE "UserUuid" = $1 LIMIT 1;
+
+-- name: UpsertPrivacySetting :one
+INSERT INTO "PrivacySetting" (
+    "UserUuid",
+    "PurchaseVisibility")
+VALUES (
+    sqlc.arg(user_uuid), sqlc.arg(purchase_visibility)
+)
+ON CONFLICT ("UserUuid") DO UPDATE
+  set "PurchaseVisibility" = EXCLUDED."PurchaseVisibility",
+      "UpdatedAt" = now()
+RETURNING *;
\ No newline at end of file
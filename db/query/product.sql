@@ -2,9 +2,10 @@
 INSERT INTO "Product" (
     "Description",
     "Price",
-    "InStock") 
+    "InStock",
+    "CategoryUuid") 
 VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4
 )
 RETURNING *;
 
@@ -24,20 +25,93 @@ ORDER BY "Uuid"
 LIMIT $1
 OFFSET $2;
 
+-- The product is only updated when it still has the version, a null version updates any version
 -- name: UpdateProduct :one
 UPDATE "Product"
-    set "Description" = $2,
-        "Price" = $3,
-        "InStock" = $4
-WHERE "Uuid" = $1
+    set "Description" = sqlc.arg(description),
+        "Price" = sqlc.arg(price),
+        "InStock" = sqlc.arg(in_stock),
+        "CategoryUuid" = sqlc.narg(category_uuid),
+        "Version" = "Version" + 1
+WHERE "Uuid" = sqlc.arg(uuid) AND "Version" = COALESCE(sqlc.narg(version)::integer, "Version")
 RETURNING *;
 
 -- name: ReduceProductInStock :one
 UPDATE "Product"
-  set "InStock" = "InStock" - sqlc.arg(amount) 
+  set "InStock" = "InStock" - sqlc.arg(amount),
+      "Version" = "Version" + 1
 WHERE "Uuid" = sqlc.arg(Uuid)
 RETURNING *;
 
+-- name: AddProductInStock :one
+UPDATE "Product"
+  set "InStock" = "InStock" + sqlc.arg(amount),
+      "Version" = "Version" + 1
+WHERE "Uuid" = sqlc.arg(Uuid)
+RETURNING *;
+
+-- Only the fields that aren't null are changed, the category is removed with clear_category
+-- name: PatchProduct :one
+UPDATE "Product"
+    set "Description" = COALESCE(sqlc.narg(description)::varchar, "Description"),
+        "Price" = COALESCE(sqlc.narg(price)::bigint, "Price"),
+        "InStock" = COALESCE(sqlc.narg(in_stock)::integer, "InStock"),
+        "CategoryUuid" = CASE WHEN sqlc.arg(clear_category)::boolean THEN NULL
+            ELSE COALESCE(sqlc.narg(category_uuid)::bigint, "CategoryUuid") END,
+        "Version" = "Version" + 1
+WHERE "Uuid" = sqlc.arg(uuid) AND "Version" = COALESCE(sqlc.narg(version)::integer, "Version")
+RETURNING *;
+
 -- name: DeleteProduct :execrows
 DELETE FROM "Product"
-WHERE "Uuid" = $1;
\ No newline at end of file
+WHERE "Uuid" = sqlc.arg(uuid) AND "Version" = COALESCE(sqlc.narg(version)::integer, "Version");
+
+-- name: CountProducts :one
+SELECT count(*) FROM "Product";
+
+-- The page starts right after the product with the uuid, 0 starts from the first product
+-- name: ListProductsAfter :many
+SELECT * FROM "Product"
+WHERE "Uuid" > sqlc.arg(after_uuid)
+ORDER BY "Uuid"
+LIMIT sqlc.arg('limit');
+
+-- The page ends right before the product with the uuid, so the products come in the reverse order
+-- name: ListProductsBefore :many
+SELECT * FROM "Product"
+WHERE "Uuid" < sqlc.arg(before_uuid)
+ORDER BY "Uuid" DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountSearchProducts :one
+SELECT count(*) FROM "Product"
+WHERE (sqlc.narg(search)::text IS NULL OR to_tsvector('english', "Description") @@ websearch_to_tsquery('english', sqlc.narg(search)))
+    AND (sqlc.narg(min_price)::bigint IS NULL OR "Price" >= sqlc.narg(min_price))
+    AND (sqlc.narg(max_price)::bigint IS NULL OR "Price" <= sqlc.narg(max_price))
+    AND (NOT sqlc.arg(in_stock)::boolean OR "InStock" > 0)
+    AND (sqlc.narg(category_uuid)::bigint IS NULL OR "CategoryUuid" = sqlc.narg(category_uuid));
+
+-- The products are sorted by the price (price_asc, price_desc), the description (name), from the newest or by the uuid.
+-- The page starts right after the product the after args point to, they are null on the first page
+-- name: SearchProducts :many
+SELECT * FROM "Product"
+WHERE (sqlc.narg(search)::text IS NULL OR to_tsvector('english', "Description") @@ websearch_to_tsquery('english', sqlc.narg(search)))
+    AND (sqlc.narg(min_price)::bigint IS NULL OR "Price" >= sqlc.narg(min_price))
+    AND (sqlc.narg(max_price)::bigint IS NULL OR "Price" <= sqlc.narg(max_price))
+    AND (NOT sqlc.arg(in_stock)::boolean OR "InStock" > 0)
+    AND (sqlc.narg(category_uuid)::bigint IS NULL OR "CategoryUuid" = sqlc.narg(category_uuid))
+    AND (sqlc.narg(after_uuid)::bigint IS NULL OR CASE sqlc.arg(sort)::text
+        WHEN 'price_asc' THEN ("Price", "Uuid") > (sqlc.narg(after_price)::bigint, sqlc.narg(after_uuid))
+        WHEN 'price_desc' THEN ("Price", "Uuid") < (sqlc.narg(after_price)::bigint, sqlc.narg(after_uuid))
+        WHEN 'name' THEN ("Description", "Uuid") > (sqlc.narg(after_name)::varchar, sqlc.narg(after_uuid))
+        WHEN 'newest' THEN "Uuid" < sqlc.narg(after_uuid)
+        ELSE "Uuid" > sqlc.narg(after_uuid)
+    END)
+ORDER BY
+    CASE WHEN sqlc.arg(sort) = 'price_asc' THEN "Price" END,
+    CASE WHEN sqlc.arg(sort) = 'price_desc' THEN "Price" END DESC,
+    CASE WHEN sqlc.arg(sort) = 'name' THEN "Description" END,
+    CASE WHEN sqlc.arg(sort) IN ('price_desc', 'newest') THEN "Uuid" END DESC,
+    "Uuid"
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- name: CreateProductImage :one
+INSERT INTO "ProductImage" (
+    "ProductUuid",
+    "Key",
+    "ThumbnailKey"
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
+-- The images of a page of products are read at once, in the order they were uploaded
+-- name: ListProductImages :many
+SELECT * FROM "ProductImage"
+WHERE "ProductUuid" = ANY(sqlc.arg(product_uuids)::bigint[])
+ORDER BY "ProductUuid", "Uuid";
\ No newline at end of file
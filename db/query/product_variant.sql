@@ -0,0 +1,28 @@
+-- name: CreateProductVariant :one
+INSERT INTO "ProductVariant" (
+    "ProductUuid",
+    "Model",
+    "Color",
+    "StorageGb"
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: ListProductVariants :many
+SELECT * FROM "ProductVariant"
+WHERE "ProductUuid" = $1
+ORDER BY "Model", "Color", "StorageGb";
+
+-- The variant is only found among the variants of its product
+-- name: UpdateProductVariant :one
+UPDATE "ProductVariant"
+    set "Model" = $3,
+        "Color" = $4,
+        "StorageGb" = $5
+WHERE "Uuid" = $1 AND "ProductUuid" = $2
+RETURNING *;
+
+-- name: DeleteProductVariant :execrows
+DELETE FROM "ProductVariant"
+WHERE "Uuid" = $1 AND "ProductUuid" = $2;
\ No newline at end of file
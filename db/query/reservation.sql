@@ -0,0 +1,52 @@
+-- Reserving a product again replaces the quantity of the reservation but keeps its expiry,
+-- so the pcs can't be held forever by reserving them over and over. An expired reservation starts again
+-- name: UpsertReservation :one
+INSERT INTO "Reservation" (
+    "UserUuid",
+    "ProductUuid",
+    "Quantity",
+    "ExpiresAt"
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT ("UserUuid", "ProductUuid") DO UPDATE
+    SET "Quantity" = EXCLUDED."Quantity",
+        "ExpiresAt" = CASE WHEN "Reservation"."ExpiresAt" > now()
+            THEN "Reservation"."ExpiresAt"
+            ELSE EXCLUDED."ExpiresAt"
+        END
+RETURNING *;
+
+-- name: GetReservation :one
+SELECT * FROM "Reservation"
+WHERE "Uuid" = $1 LIMIT 1;
+
+-- name: ListReservations :many
+SELECT * FROM "Reservation"
+WHERE "UserUuid" = $1 AND "ExpiresAt" > now()
+ORDER BY "ExpiresAt";
+
+-- The units the other users hold, they can't be bought or reserved by the user
+-- name: GetReservedQuantity :one
+SELECT COALESCE(sum("Quantity"), 0)::integer AS "Reserved" FROM "Reservation"
+WHERE "ProductUuid" = sqlc.arg(product_uuid)
+    AND "UserUuid" <> sqlc.arg(except_user_uuid)
+    AND "ExpiresAt" > now();
+
+-- name: ListReservedQuantities :many
+SELECT "ProductUuid", sum("Quantity")::integer AS "Reserved" FROM "Reservation"
+WHERE "ProductUuid" = ANY(sqlc.arg(product_uuids)::bigint[]) AND "ExpiresAt" > now()
+GROUP BY "ProductUuid";
+
+-- name: DeleteReservation :execrows
+DELETE FROM "Reservation"
+WHERE "Uuid" = $1;
+
+-- The reservation of the product is used up once the user buys it
+-- name: DeleteUserReservation :exec
+DELETE FROM "Reservation"
+WHERE "UserUuid" = $1 AND "ProductUuid" = $2;
+
+-- name: ReleaseExpiredReservations :execrows
+DELETE FROM "Reservation"
+WHERE "ExpiresAt" <= now();
\ No newline at end of file
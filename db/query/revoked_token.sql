@@ -0,0 +1,38 @@
+-- name: CreateRevokedToken :exec
+INSERT INTO "RevokedToken" (
+    "Uuid",
+    "Username",
+    "ExpiresAt")
+VALUES (
+    $1, $2, $3
+)
+ON CONFLICT ("Uuid") DO NOTHING;
+
+-- A token is revoked on its own by logging out,
+-- or together with all the tokens of its user issued before the user's tokens were revoked
+-- name: IsTokenRevoked :one
+SELECT (
+    EXISTS (
+        SELECT 1 FROM "RevokedToken"
+        WHERE "Uuid" = sqlc.arg(uuid)
+    ) OR EXISTS (
+        SELECT 1 FROM "UserTokenRevocation"
+        WHERE "Username" = sqlc.arg(username) AND "IssuedBefore" > sqlc.arg(issued_at)::timestamptz
+    )
+)::boolean AS revoked;
+
+-- Revoked tokens are useless once they have expired since VerifyToken rejects them anyway
+-- name: DeleteExpiredRevokedTokens :execrows
+DELETE FROM "RevokedToken"
+WHERE "ExpiresAt" < now();
+
+-- The tokens of the user issued before issued_before can't be used anymore, a later revocation is never moved back
+-- name: RevokeUserTokens :exec
+INSERT INTO "UserTokenRevocation" (
+    "Username",
+    "IssuedBefore"
+) VALUES (
+    $1, $2
+)
+ON CONFLICT ("Username") DO UPDATE
+SET "IssuedBefore" = GREATEST("UserTokenRevocation"."IssuedBefore", EXCLUDED."IssuedBefore");
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- name: CreateSession :one
+INSERT INTO "Session" (
+    "Uuid",
+    "Username",
+    "RefreshToken",
+    "UserAgent",
+    "ClientIp",
+    "IsBlocked",
+    "ExpiresAt")
+VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetSession :one
+SELECT * FROM "Session"
+WHERE "Uuid" = $1 LIMIT 1;
+
+-- name: BlockSession :one
+UPDATE "Session"
+  set "IsBlocked" = true
+WHERE "Uuid" = $1
+RETURNING *;
+
+-- The refresh tokens of the user can't renew the access tokens anymore
+-- name: BlockUserSessions :exec
+UPDATE "Session"
+  set "IsBlocked" = true
+WHERE "Username" = $1;
\ No newline at end of file
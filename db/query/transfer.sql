@@ -0,0 +1,14 @@
+-- name: CreateTransfer :one
+INSERT INTO "Transfer" (
+    "FromUserUuid",
+    "ToUserUuid",
+    "Currency",
+    "Amount")
+VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetTransfer :one
+SELECT * FROM "Transfer"
+WHERE "Uuid" = $1 LIMIT 1;
\ No newline at end of file
@@ -5,11 +5,10 @@ INSERT INTO "User" (
 	"LastName", 
 	"Gender", 
 	"Age",
-  "Balance",
   "Username",
   "HashedPassword") 
 VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7
 )
 RETURNING *;
 
@@ -28,13 +27,6 @@ WHERE "Uuid" = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
 
--- name: ReduceUserBalance :one
-UPDATE "User"
-  set "Balance" = "Balance" - sqlc.arg(amount) 
-WHERE "Uuid" = sqlc.arg(Uuid)
-RETURNING *;
-
-
 -- name: ListUsers :many
 SELECT * FROM "User"
 ORDER BY "Uuid" ASC
@@ -42,18 +34,54 @@ LIMIT $1
 OFFSET $2;
 
 
+-- The user is only updated when it still has the version, a null version updates any version
 -- name: UpdateUser :one
 UPDATE "User"
-  set "FirstName" = $2,
-      "MiddleName" = $3,
-      "LastName" = $4,
-      "Gender" = $5,
-      "Age" = $6,
-      "Balance" = $7,
-      "HashedPassword" = $8
+  set "FirstName" = sqlc.arg(first_name),
+      "MiddleName" = sqlc.arg(middle_name),
+      "LastName" = sqlc.arg(last_name),
+      "Gender" = sqlc.arg(gender),
+      "Age" = sqlc.arg(age),
+      "Version" = "Version" + 1
+WHERE "Uuid" = sqlc.arg(uuid) AND "Version" = COALESCE(sqlc.narg(version)::integer, "Version")
+RETURNING *;
+
+-- Only the fields that aren't null are changed
+-- name: PatchUser :one
+UPDATE "User"
+  set "FirstName" = COALESCE(sqlc.narg(first_name)::varchar, "FirstName"),
+      "MiddleName" = COALESCE(sqlc.narg(middle_name)::varchar, "MiddleName"),
+      "LastName" = COALESCE(sqlc.narg(last_name)::varchar, "LastName"),
+      "Gender" = COALESCE(sqlc.narg(gender)::varchar, "Gender"),
+      "Age" = COALESCE(sqlc.narg(age)::smallint, "Age"),
+      "Version" = "Version" + 1
+WHERE "Uuid" = sqlc.arg(uuid) AND "Version" = COALESCE(sqlc.narg(version)::integer, "Version")
+RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE "User"
+  set "HashedPassword" = $2,
+      "Version" = "Version" + 1
 WHERE "Uuid" = $1
 RETURNING *;
 
 -- name: DeleteUser :execrows
 DELETE FROM "User"
-WHERE "Uuid" = $1;
\ No newline at end of file
+WHERE "Uuid" = sqlc.arg(uuid) AND "Version" = COALESCE(sqlc.narg(version)::integer, "Version");
+
+-- name: CountUsers :one
+SELECT count(*) FROM "User";
+
+-- The page starts right after the user with the uuid, 0 starts from the first user
+-- name: ListUsersAfter :many
+SELECT * FROM "User"
+WHERE "Uuid" > sqlc.arg(after_uuid)
+ORDER BY "Uuid" ASC
+LIMIT sqlc.arg('limit');
+
+-- The page ends right before the user with the uuid, so the users come in the reverse order
+-- name: ListUsersBefore :many
+SELECT * FROM "User"
+WHERE "Uuid" < sqlc.arg(before_uuid)
+ORDER BY "Uuid" DESC
+LIMIT sqlc.arg('limit');
\ No newline at end of file
@@ -25,4 +25,52 @@ UPDATE "UserToUser"
   set "SecondUserUuid" = $3
 WHERE "FirstUserUuid" = $1 
     AND "SecondUserUuid" = $2
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: DeleteUserToUser :execrows
+DELETE FROM "UserToUser"
+WHERE "FirstUserUuid" = $1
+    AND "SecondUserUuid" = $2;
+
+-- The followers of a user are the users following them
+-- name: ListFollowers :many
+SELECT
+"User"."Uuid",
+"User"."Username",
+"User"."FullName",
+"UserToUser"."CreatedAt" AS "FollowedAt"
+FROM "UserToUser"
+INNER JOIN "User" ON "User"."Uuid" = "UserToUser"."FirstUserUuid"
+WHERE "UserToUser"."SecondUserUuid" = sqlc.arg(user_uuid)
+ORDER BY "User"."Uuid"
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListFollowing :many
+SELECT
+"User"."Uuid",
+"User"."Username",
+"User"."FullName",
+"UserToUser"."CreatedAt" AS "FollowedAt"
+FROM "UserToUser"
+INNER JOIN "User" ON "User"."Uuid" = "UserToUser"."SecondUserUuid"
+WHERE "UserToUser"."FirstUserUuid" = sqlc.arg(user_uuid)
+ORDER BY "User"."Uuid"
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- Mutual follows are the users the user follows who follow the user back
+-- name: ListMutualFollows :many
+SELECT
+"User"."Uuid",
+"User"."Username",
+"User"."FullName",
+"Following"."CreatedAt" AS "FollowedAt"
+FROM "UserToUser" AS "Following"
+INNER JOIN "UserToUser" AS "Follower" ON "Follower"."FirstUserUuid" = "Following"."SecondUserUuid"
+    AND "Follower"."SecondUserUuid" = "Following"."FirstUserUuid"
+INNER JOIN "User" ON "User"."Uuid" = "Following"."SecondUserUuid"
+WHERE "Following"."FirstUserUuid" = sqlc.arg(user_uuid)
+ORDER BY "User"."Uuid"
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
\ No newline at end of file
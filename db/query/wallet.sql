@@ -0,0 +1,24 @@
+-- The wallet is created the first time money is added in its currency.
+-- A negative amount takes money from the wallet, the check on "Balance" rejects overdrafts
+-- name: AddWalletBalance :one
+INSERT INTO "Wallet" (
+    "UserUuid",
+    "Currency",
+    "Balance")
+VALUES (
+    sqlc.arg(user_uuid), sqlc.arg(currency), sqlc.arg(amount)
+)
+ON CONFLICT ("UserUuid", "Currency") DO UPDATE
+  set "Balance" = "Wallet"."Balance" + EXCLUDED."Balance"
+RETURNING *;
+
+-- name: GetWallet :one
+SELECT * FROM "Wallet"
+WHERE "UserUuid" = $1
+    AND "Currency" = $2
+LIMIT 1;
+
+-- name: ListWallets :many
+SELECT * FROM "Wallet"
+WHERE "UserUuid" = $1
+ORDER BY "Currency";
\ No newline at end of file
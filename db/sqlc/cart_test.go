@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomCart(t *testing.T) Cart {
+	user := createRandomUser(t)
+	cart, err := testQueries.GetOrCreateCart(context.Background(), user.Uuid)
+	require.NoError(t, err)
+	require.NotEmpty(t, cart)
+
+	require.Equal(t, user.Uuid, cart.UserUuid)
+	require.NotZero(t, cart.Uuid)
+	require.NotZero(t, cart.CreatedAt)
+	return cart
+}
+
+func TestGetOrCreateCart(t *testing.T) {
+	cart1 := createRandomCart(t)
+
+	// the user already has a cart, so the same one must be returned
+	cart2, err := testQueries.GetOrCreateCart(context.Background(), cart1.UserUuid)
+	require.NoError(t, err)
+	require.Equal(t, cart1.Uuid, cart2.Uuid)
+
+	cart3, err := testQueries.GetCartByUserUuid(context.Background(), cart1.UserUuid)
+	require.NoError(t, err)
+	require.Equal(t, cart1.Uuid, cart3.Uuid)
+}
+
+func TestAddCartItem(t *testing.T) {
+	cart := createRandomCart(t)
+	product := createRandomProduct(t)
+
+	arg := AddCartItemParams{
+		CartUuid:    cart.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    2,
+	}
+	item, err := testQueries.AddCartItem(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Quantity, item.Quantity)
+
+	// adding the same product again increases its quantity
+	item, err = testQueries.AddCartItem(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, 2*arg.Quantity, item.Quantity)
+}
+
+func TestUpdateCartItem(t *testing.T) {
+	cart := createRandomCart(t)
+	product := createRandomProduct(t)
+
+	_, err := testQueries.AddCartItem(context.Background(), AddCartItemParams{
+		CartUuid:    cart.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    1,
+	})
+	require.NoError(t, err)
+
+	item, err := testQueries.UpdateCartItem(context.Background(), UpdateCartItemParams{
+		CartUuid:    cart.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(5), item.Quantity)
+}
+
+func TestDeleteCartItem(t *testing.T) {
+	cart := createRandomCart(t)
+	product := createRandomProduct(t)
+
+	_, err := testQueries.AddCartItem(context.Background(), AddCartItemParams{
+		CartUuid:    cart.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    1,
+	})
+	require.NoError(t, err)
+
+	arg := DeleteCartItemParams{
+		CartUuid:    cart.Uuid,
+		ProductUuid: product.Uuid,
+	}
+	r, err := testQueries.DeleteCartItem(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), r)
+
+	r, err = testQueries.DeleteCartItem(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, r)
+}
+
+func TestListCartItems(t *testing.T) {
+	cart := createRandomCart(t)
+	for i := 0; i < 5; i++ {
+		product := createRandomProduct(t)
+		_, err := testQueries.AddCartItem(context.Background(), AddCartItemParams{
+			CartUuid:    cart.Uuid,
+			ProductUuid: product.Uuid,
+			Quantity:    1,
+		})
+		require.NoError(t, err)
+	}
+
+	items, err := testQueries.ListCartItems(context.Background(), cart.Uuid)
+	require.NoError(t, err)
+	require.Len(t, items, 5)
+	for i := 1; i < len(items); i++ {
+		require.Less(t, items[i-1].ProductUuid, items[i].ProductUuid)
+	}
+
+	err = testQueries.ClearCart(context.Background(), cart.Uuid)
+	require.NoError(t, err)
+
+	items, err = testQueries.ListCartItems(context.Background(), cart.Uuid)
+	require.NoError(t, err)
+	require.Empty(t, items)
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomCategory(t *testing.T) Category {
+	name := util.RandomString(12)
+	category, err := testQueries.CreateCategory(context.Background(), name)
+	require.NoError(t, err)
+	require.NotZero(t, category.Uuid)
+	require.Equal(t, name, category.Name)
+	require.NotZero(t, category.CreatedAt)
+	return category
+}
+
+func TestCreateCategory(t *testing.T) {
+	category := createRandomCategory(t)
+
+	_, err := testQueries.CreateCategory(context.Background(), category.Name)
+	require.ErrorContains(t, err, "Category_Name_key")
+}
+
+func TestGetCategory(t *testing.T) {
+	category1 := createRandomCategory(t)
+	category2, err := testQueries.GetCategory(context.Background(), category1.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, category1, category2)
+}
+
+func TestUpdateCategory(t *testing.T) {
+	category1 := createRandomCategory(t)
+	arg := UpdateCategoryParams{
+		Uuid: category1.Uuid,
+		Name: util.RandomString(12),
+	}
+	category2, err := testQueries.UpdateCategory(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, category1.Uuid, category2.Uuid)
+	require.Equal(t, arg.Name, category2.Name)
+}
+
+func TestListCategories(t *testing.T) {
+	category := createRandomCategory(t)
+
+	categories, err := testQueries.ListCategories(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, categories, category)
+	for i := 1; i < len(categories); i++ {
+		require.LessOrEqual(t, categories[i-1].Name, categories[i].Name)
+	}
+}
+
+func TestDeleteCategory(t *testing.T) {
+	category := createRandomCategory(t)
+	product, err := testQueries.CreateProduct(context.Background(), CreateProductParams{
+		Description:  util.RandomProductDescription(),
+		Price:        util.RandomProductPrice(),
+		InStock:      util.RandomProductInStock(),
+		CategoryUuid: sql.NullInt64{Int64: category.Uuid, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, category.Uuid, product.CategoryUuid.Int64)
+
+	r, err := testQueries.DeleteCategory(context.Background(), category.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), r)
+
+	// the product is left without a category
+	product, err = testQueries.GetProduct(context.Background(), product.Uuid)
+	require.NoError(t, err)
+	require.False(t, product.CategoryUuid.Valid)
+
+	_, err = testQueries.GetCategory(context.Background(), category.Uuid)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createLedgerEntry(t *testing.T, user *User, currency string, amount util.Money, kind string) Entry {
+	arg := CreateEntryParams{
+		UserUuid: user.Uuid,
+		Currency: currency,
+		Amount:   amount,
+		Kind:     kind,
+	}
+	entry, err := testQueries.CreateEntry(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, entry.Uuid)
+	require.Equal(t, arg.UserUuid, entry.UserUuid)
+	require.Equal(t, arg.Currency, entry.Currency)
+	require.Equal(t, arg.Amount, entry.Amount)
+	require.Equal(t, arg.Kind, entry.Kind)
+	require.False(t, entry.OrderUuid.Valid)
+	require.NotZero(t, entry.CreatedAt)
+	return entry
+}
+
+func TestCreateEntry(t *testing.T) {
+	user := createRandomUserWithBalance(t, 1000)
+	createLedgerEntry(t, user, util.BaseCurrency, -100, util.EntryWithdrawal)
+
+	// an entry belongs to a wallet
+	_, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		UserUuid: user.Uuid,
+		Currency: "EUR",
+		Amount:   100,
+		Kind:     util.EntryTopUp,
+	})
+	require.Error(t, err)
+
+	_, err = testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		UserUuid: user.Uuid,
+		Currency: util.BaseCurrency,
+		Amount:   100,
+		Kind:     "gift",
+	})
+	require.ErrorContains(t, err, "Entry_Kind_check")
+}
+
+func TestListEntries(t *testing.T) {
+	user := createRandomUserWithBalance(t, 1000)
+	createWallet(t, user, "EUR", 500)
+	withdrawal := createLedgerEntry(t, user, util.BaseCurrency, -100, util.EntryWithdrawal)
+
+	entries, err := testQueries.ListEntries(context.Background(), ListEntriesParams{
+		UserUuid: user.Uuid,
+		Limit:    10,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	// the newest entries come first
+	require.Equal(t, withdrawal, entries[0])
+
+	entries, err = testQueries.ListEntries(context.Background(), ListEntriesParams{
+		UserUuid: user.Uuid,
+		Currency: sql.NullString{String: "EUR", Valid: true},
+		Limit:    10,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "EUR", entries[0].Currency)
+	require.Equal(t, util.Money(500), entries[0].Amount)
+}
+
+func TestReconcileWallets(t *testing.T) {
+	user := createRandomUserWithBalance(t, 1000)
+	createWallet(t, user, "EUR", 500)
+
+	rows, err := testQueries.ReconcileWallets(context.Background(), user.Uuid)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	for _, row := range rows {
+		require.Equal(t, int64(row.Balance), row.LedgerBalance)
+	}
+
+	// changing a balance without an entry is noticed
+	_, err = testQueries.AddWalletBalance(context.Background(), AddWalletBalanceParams{
+		UserUuid: user.Uuid,
+		Currency: "EUR",
+		Amount:   1,
+	})
+	require.NoError(t, err)
+
+	rows, err = testQueries.ReconcileWallets(context.Background(), user.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, "EUR", rows[0].Currency)
+	require.Equal(t, util.Money(501), rows[0].Balance)
+	require.Equal(t, int64(500), rows[0].LedgerBalance)
+}
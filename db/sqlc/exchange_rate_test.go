@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/stretchr/testify/require"
+)
+
+// createRandomExchangeRate creates a rate for a random currency code, so the tests don't change the seeded rates
+func createRandomExchangeRate(t *testing.T) ExchangeRate {
+	arg := UpsertExchangeRateParams{
+		Currency: util.RandomString(3),
+		Rate:     "1.2500000000",
+	}
+	rate, err := testQueries.UpsertExchangeRate(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Currency, rate.Currency)
+	require.Equal(t, arg.Rate, rate.Rate)
+	require.NotZero(t, rate.UpdatedAt)
+	return rate
+}
+
+func TestUpsertExchangeRate(t *testing.T) {
+	rate1 := createRandomExchangeRate(t)
+
+	rate2, err := testQueries.UpsertExchangeRate(context.Background(), UpsertExchangeRateParams{
+		Currency: rate1.Currency,
+		Rate:     "2.0000000000",
+	})
+	require.NoError(t, err)
+	require.Equal(t, rate1.Currency, rate2.Currency)
+	require.Equal(t, "2.0000000000", rate2.Rate)
+	require.False(t, rate2.UpdatedAt.Before(rate1.UpdatedAt))
+}
+
+func TestListExchangeRates(t *testing.T) {
+	createRandomExchangeRate(t)
+
+	rates, err := testQueries.ListExchangeRates(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, rates)
+	for i := 1; i < len(rates); i++ {
+		require.Less(t, rates[i-1].Currency, rates[i].Currency)
+	}
+}
+
+func TestSQLRateProvider(t *testing.T) {
+	rate := createRandomExchangeRate(t)
+	provider := NewRateProvider(testQueries)
+
+	got, err := provider.Rate(context.Background(), rate.Currency)
+	require.NoError(t, err)
+	require.Zero(t, got.Cmp(big.NewRat(5, 4)))
+
+	_, err = provider.Rate(context.Background(), "???")
+	require.ErrorIs(t, err, util.ErrUnsupportedCurrency)
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomFailedLogin(t *testing.T, username string, clientIp string) FailedLogin {
+	arg := CreateFailedLoginParams{
+		Username:  username,
+		ClientIp:  clientIp,
+		UserAgent: util.RandomString(6),
+		Reason:    "wrong_password",
+	}
+	failedLogin, err := testQueries.CreateFailedLogin(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, failedLogin.Uuid)
+	require.Equal(t, arg.Username, failedLogin.Username)
+	require.Equal(t, arg.ClientIp, failedLogin.ClientIp)
+	require.Equal(t, arg.UserAgent, failedLogin.UserAgent)
+	require.Equal(t, arg.Reason, failedLogin.Reason)
+	require.WithinDuration(t, time.Now(), failedLogin.CreatedAt, time.Second)
+	return failedLogin
+}
+
+func TestCreateFailedLogin(t *testing.T) {
+	// the username doesn't have to belong to a user
+	createRandomFailedLogin(t, util.RandomString(8), "192.0.2.1")
+}
+
+func TestListFailedLogins(t *testing.T) {
+	username := util.RandomString(8)
+	first := createRandomFailedLogin(t, username, "192.0.2.1")
+	second := createRandomFailedLogin(t, username, "192.0.2.2")
+	createRandomFailedLogin(t, util.RandomString(8), "192.0.2.1")
+
+	failedLogins, err := testQueries.ListFailedLogins(context.Background(), ListFailedLoginsParams{
+		Username: sql.NullString{String: username, Valid: true},
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, failedLogins, 2)
+	// the newest come first
+	require.Equal(t, second.Uuid, failedLogins[0].Uuid)
+	require.Equal(t, first.Uuid, failedLogins[1].Uuid)
+
+	failedLogins, err = testQueries.ListFailedLogins(context.Background(), ListFailedLoginsParams{
+		Username: sql.NullString{String: username, Valid: true},
+		ClientIp: sql.NullString{String: "192.0.2.1", Valid: true},
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, failedLogins, 1)
+	require.Equal(t, first.Uuid, failedLogins[0].Uuid)
+}
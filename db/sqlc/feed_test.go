@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFeed(t *testing.T) {
+	follower := createRandomUser(t)
+
+	public := createRandomUser(t)
+	mutualOnly := createRandomUser(t)
+	mutualBack := createRandomUser(t)
+	private := createRandomUser(t)
+	withoutSettings := createRandomUser(t)
+	stranger := createRandomUser(t)
+
+	for _, user := range []*User{public, mutualOnly, mutualBack, private, withoutSettings} {
+		followUser(t, follower, user)
+	}
+	followUser(t, mutualBack, follower)
+	setPurchaseVisibility(t, public, util.VisibilityFollowers)
+	setPurchaseVisibility(t, mutualOnly, util.VisibilityMutual)
+	setPurchaseVisibility(t, mutualBack, util.VisibilityMutual)
+	setPurchaseVisibility(t, private, util.VisibilityPrivate)
+
+	publicOrder1 := createRandomOrderForUser(t, public)
+	publicOrder2 := createRandomOrderForUser(t, public)
+	mutualOrder := createRandomOrderForUser(t, mutualBack)
+	createRandomOrderForUser(t, mutualOnly)
+	createRandomOrderForUser(t, private)
+	// the purchases are private until the user shares them
+	createRandomOrderForUser(t, withoutSettings)
+	createRandomOrderForUser(t, stranger)
+
+	// cancelled orders aren't purchases
+	cancelled := createRandomOrderForUser(t, public)
+	_, err := testQueries.UpdateOrderStatus(context.Background(), UpdateOrderStatusParams{
+		Status:     util.OrderCancelled,
+		Uuid:       cancelled.Uuid,
+		FromStatus: cancelled.Status,
+	})
+	require.NoError(t, err)
+
+	feed, err := testQueries.ListFeed(context.Background(), ListFeedParams{
+		FollowerUuid: follower.Uuid,
+		Limit:        2,
+	})
+	require.NoError(t, err)
+	require.Len(t, feed, 2)
+	require.Equal(t, mutualOrder.Uuid, feed[0].Uuid)
+	require.Equal(t, mutualBack.Username, feed[0].Username)
+	require.Equal(t, publicOrder2.Uuid, feed[1].Uuid)
+
+	// the next page starts after the last order of the previous one
+	feed, err = testQueries.ListFeed(context.Background(), ListFeedParams{
+		FollowerUuid:    follower.Uuid,
+		BeforeCreatedAt: sql.NullTime{Time: feed[1].CreatedAt, Valid: true},
+		BeforeUuid:      sql.NullInt64{Int64: feed[1].Uuid, Valid: true},
+		Limit:           2,
+	})
+	require.NoError(t, err)
+	require.Len(t, feed, 1)
+	require.Equal(t, publicOrder1.Uuid, feed[0].Uuid)
+}
+
+func TestListFeedLines(t *testing.T) {
+	user := createRandomUser(t)
+	order1 := createRandomOrderForUser(t, user)
+	order2 := createRandomOrderForUser(t, user)
+	product := createRandomProduct(t)
+
+	for _, order := range []Order{order1, order2} {
+		_, err := testQueries.CreateOrderProduct(context.Background(), CreateOrderProductParams{
+			OrderUuid:   order.Uuid,
+			ProductUuid: product.Uuid,
+			Quantity:    1,
+			UnitPrice:   product.Price,
+			Currency:    util.BaseCurrency,
+		})
+		require.NoError(t, err)
+	}
+
+	lines, err := testQueries.ListFeedLines(context.Background(), []int64{order1.Uuid, order2.Uuid})
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	require.Equal(t, order1.Uuid, lines[0].OrderUuid)
+	require.Equal(t, order2.Uuid, lines[1].OrderUuid)
+	require.Equal(t, product.Description, lines[0].ProductDescription)
+	require.Equal(t, int32(1), lines[0].Quantity)
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	arg := CreateIdempotencyKeyParams{
+		UserUuid:    user.Uuid,
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   time.Now().Add(time.Hour),
+		ClaimToken:  uuid.New(),
+	}
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, key.Uuid)
+	require.Equal(t, arg.ClaimToken, key.ClaimToken)
+	require.Equal(t, arg.Key, key.Key)
+	require.Equal(t, arg.RequestHash, key.RequestHash)
+	require.Zero(t, key.StatusCode)
+	require.Nil(t, key.ResponseBody)
+
+	// the key can't be claimed again until it expires
+	_, err = testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = testQueries.UpdateIdempotencyKeyResponse(context.Background(), UpdateIdempotencyKeyResponseParams{
+		Uuid:         key.Uuid,
+		ClaimToken:   key.ClaimToken,
+		StatusCode:   201,
+		ResponseBody: []byte(`{"ok":true}`),
+	})
+	require.NoError(t, err)
+	stored, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		UserUuid: user.Uuid,
+		Key:      arg.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(201), stored.StatusCode)
+	require.Equal(t, []byte(`{"ok":true}`), stored.ResponseBody)
+
+	// the same key of another user is a different key
+	other := arg
+	other.UserUuid = createRandomUser(t).Uuid
+	_, err = testQueries.CreateIdempotencyKey(context.Background(), other)
+	require.NoError(t, err)
+
+	err = testQueries.DeleteIdempotencyKey(context.Background(), DeleteIdempotencyKeyParams{
+		Uuid:       key.Uuid,
+		ClaimToken: key.ClaimToken,
+	})
+	require.NoError(t, err)
+	_, err = testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+}
+
+func TestCreateExpiredIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	arg := CreateIdempotencyKeyParams{
+		UserUuid:    user.Uuid,
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   time.Now().Add(-time.Minute),
+		ClaimToken:  uuid.New(),
+	}
+	key1, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the expired key is claimed again by the next request
+	arg.RequestHash = util.RandomString(64)
+	arg.ExpiresAt = time.Now().Add(time.Hour)
+	key2, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, key1.Uuid, key2.Uuid)
+	require.Equal(t, arg.RequestHash, key2.RequestHash)
+}
+
+func TestCreateStaleIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	arg := CreateIdempotencyKeyParams{
+		UserUuid:    user.Uuid,
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   time.Now().Add(time.Hour),
+		ClaimToken:  uuid.New(),
+		StaleBefore: time.Now().Add(-time.Minute),
+	}
+	key1, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the request that claimed the key is in progress for longer than the timeout, so the key is claimed again
+	arg.StaleBefore = time.Now().Add(time.Minute)
+	arg.ClaimToken = uuid.New()
+	key2, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, key1.Uuid, key2.Uuid)
+	require.Equal(t, arg.ClaimToken, key2.ClaimToken)
+
+	// the request that claimed the key first can neither store its response nor release the new claim
+	err = testQueries.UpdateIdempotencyKeyResponse(context.Background(), UpdateIdempotencyKeyResponseParams{
+		Uuid:         key1.Uuid,
+		ClaimToken:   key1.ClaimToken,
+		StatusCode:   201,
+		ResponseBody: []byte(`{"first":true}`),
+	})
+	require.NoError(t, err)
+	err = testQueries.DeleteIdempotencyKey(context.Background(), DeleteIdempotencyKeyParams{
+		Uuid:       key1.Uuid,
+		ClaimToken: key1.ClaimToken,
+	})
+	require.NoError(t, err)
+	stored, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		UserUuid: user.Uuid,
+		Key:      arg.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, key2.ClaimToken, stored.ClaimToken)
+	require.Zero(t, stored.StatusCode)
+
+	// a key with a response isn't stale however old it is
+	err = testQueries.UpdateIdempotencyKeyResponse(context.Background(), UpdateIdempotencyKeyResponseParams{
+		Uuid:         key2.Uuid,
+		ClaimToken:   key2.ClaimToken,
+		StatusCode:   201,
+		ResponseBody: []byte(`{"ok":true}`),
+	})
+	require.NoError(t, err)
+	_, err = testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+	user := createRandomUser(t)
+	arg := CreateIdempotencyKeyParams{
+		UserUuid:    user.Uuid,
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(64),
+		ExpiresAt:   time.Now().Add(-time.Minute),
+		ClaimToken:  uuid.New(),
+	}
+	expired, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	arg.Key = util.RandomString(16)
+	arg.ExpiresAt = time.Now().Add(time.Hour)
+	active, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	n, err := testQueries.DeleteExpiredIdempotencyKeys(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, n, int64(1))
+
+	_, err = testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{UserUuid: user.Uuid, Key: expired.Key})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{UserUuid: user.Uuid, Key: active.Key})
+	require.NoError(t, err)
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveLoginAttempt(t *testing.T) {
+	key := "username:" + util.RandomString(8)
+	arg := ReserveLoginAttemptParams{
+		Key:         key,
+		ResetBefore: time.Now().Add(-time.Hour),
+	}
+	for i := 1; i <= 3; i++ {
+		throttle, err := testQueries.ReserveLoginAttempt(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, key, throttle.Key)
+		require.Equal(t, int32(i), throttle.Failures)
+		require.WithinDuration(t, time.Now(), throttle.UpdatedAt, time.Second)
+		require.WithinDuration(t, time.Now(), throttle.LastFailureAt.Time, time.Second)
+		require.False(t, throttle.LockedUntil.Valid)
+	}
+
+	// the last failure is older than reset_before, so the failures are counted from one again
+	arg.ResetBefore = time.Now().Add(time.Minute)
+	throttle, err := testQueries.ReserveLoginAttempt(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), throttle.Failures)
+}
+
+func TestLockLoginThrottle(t *testing.T) {
+	key := "ip:" + util.RandomString(8)
+	arg := ReserveLoginAttemptParams{
+		Key:         key,
+		ResetBefore: time.Now().Add(-time.Hour),
+	}
+	_, err := testQueries.ReserveLoginAttempt(context.Background(), arg)
+	require.NoError(t, err)
+
+	lockedUntil := sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true}
+	throttle, err := testQueries.LockLoginThrottle(context.Background(), LockLoginThrottleParams{
+		Key:         key,
+		LockedUntil: lockedUntil,
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, lockedUntil.Time, throttle.LockedUntil.Time, time.Millisecond)
+
+	// nothing is counted while the key is locked out
+	_, err = testQueries.ReserveLoginAttempt(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// the lock taken by another attempt stays and so does the last failure counted by another attempt
+	err = testQueries.RefundLoginAttempt(context.Background(), RefundLoginAttemptParams{Key: key})
+	require.NoError(t, err)
+	throttles, err := testQueries.ListLoginThrottles(context.Background(), []string{key})
+	require.NoError(t, err)
+	require.Len(t, throttles, 1)
+	require.Zero(t, throttles[0].Failures)
+	require.True(t, throttles[0].LockedUntil.Valid)
+	require.Equal(t, throttle.LastFailureAt, throttles[0].LastFailureAt)
+
+	// the attempt that took the lock lifts it
+	err = testQueries.RefundLoginAttempt(context.Background(), RefundLoginAttemptParams{
+		Key:         key,
+		LockedUntil: throttle.LockedUntil,
+	})
+	require.NoError(t, err)
+	throttle, err = testQueries.ReserveLoginAttempt(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), throttle.Failures)
+	require.False(t, throttle.LockedUntil.Valid)
+}
+
+func TestListLoginThrottles(t *testing.T) {
+	key1 := "username:" + util.RandomString(8)
+	key2 := "ip:" + util.RandomString(8)
+	for _, key := range []string{key1, key2, key2} {
+		_, err := testQueries.ReserveLoginAttempt(context.Background(), ReserveLoginAttemptParams{
+			Key:         key,
+			ResetBefore: time.Now().Add(-time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	throttles, err := testQueries.ListLoginThrottles(context.Background(), []string{key1, key2, "ip:" + util.RandomString(8)})
+	require.NoError(t, err)
+	require.Len(t, throttles, 2)
+	failures := map[string]int32{}
+	for _, throttle := range throttles {
+		failures[throttle.Key] = throttle.Failures
+	}
+	require.Equal(t, map[string]int32{key1: 1, key2: 2}, failures)
+
+	err = testQueries.DeleteLoginThrottle(context.Background(), key2)
+	require.NoError(t, err)
+	throttles, err = testQueries.ListLoginThrottles(context.Background(), []string{key2})
+	require.NoError(t, err)
+	require.Empty(t, throttles)
+}
+
+func TestDeleteStaleLoginThrottles(t *testing.T) {
+	arg := ReserveLoginAttemptParams{
+		Key:         "ip:" + util.RandomString(8),
+		ResetBefore: time.Now().Add(-time.Hour),
+	}
+	stale, err := testQueries.ReserveLoginAttempt(context.Background(), arg)
+	require.NoError(t, err)
+	arg.Key = "ip:" + util.RandomString(8)
+	locked, err := testQueries.ReserveLoginAttempt(context.Background(), arg)
+	require.NoError(t, err)
+	_, err = testQueries.LockLoginThrottle(context.Background(), LockLoginThrottleParams{
+		Key:         locked.Key,
+		LockedUntil: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	arg.Key = "ip:" + util.RandomString(8)
+	recent, err := testQueries.ReserveLoginAttempt(context.Background(), arg)
+	require.NoError(t, err)
+
+	// only the failures of the first key are before reset_before
+	resetBefore := recent.LastFailureAt.Time
+	n, err := testQueries.DeleteStaleLoginThrottles(context.Background(), resetBefore)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, n, int64(1))
+
+	throttles, err := testQueries.ListLoginThrottles(context.Background(), []string{stale.Key, locked.Key, recent.Key})
+	require.NoError(t, err)
+	keys := []string{}
+	for _, throttle := range throttles {
+		keys = append(keys, throttle.Key)
+	}
+	require.ElementsMatch(t, []string{locked.Key, recent.Key}, keys)
+}
+
+func TestLoginLockout(t *testing.T) {
+	testCases := []struct {
+		name     string
+		failures int32
+		lockout  time.Duration
+	}{
+		{name: "MaxFailures", failures: 3, lockout: time.Minute},
+		{name: "Doubled", failures: 5, lockout: 4 * time.Minute},
+		{name: "Capped", failures: 100, lockout: time.Hour},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.lockout, loginLockout(tc.failures, 3, time.Minute, time.Hour))
+		})
+	}
+}
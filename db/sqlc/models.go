@@ -4,40 +4,194 @@
 
 package db
 
-import ()
+import (
+	"database/sql"
+	"time"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/google/uuid"
+)
+
+type Cart struct {
+	Uuid      int64     `json:"Uuid"`
+	UserUuid  int64     `json:"UserUuid"`
+	CreatedAt time.Time `json:"CreatedAt"`
+}
+
+type CartItem struct {
+	CartUuid    int64 `json:"CartUuid"`
+	ProductUuid int64 `json:"ProductUuid"`
+	Quantity    int32 `json:"Quantity"`
+}
+
+type Category struct {
+	Uuid      int64     `json:"Uuid"`
+	Name      string    `json:"Name"`
+	CreatedAt time.Time `json:"CreatedAt"`
+}
+
+type Entry struct {
+	Uuid         int64         `json:"Uuid"`
+	UserUuid     int64         `json:"UserUuid"`
+	Currency     string        `json:"Currency"`
+	Amount       util.Money    `json:"Amount"`
+	Kind         string        `json:"Kind"`
+	OrderUuid    sql.NullInt64 `json:"OrderUuid"`
+	CreatedAt    time.Time     `json:"CreatedAt"`
+	TransferUuid sql.NullInt64 `json:"TransferUuid"`
+}
+
+type ExchangeRate struct {
+	Currency  string    `json:"Currency"`
+	Rate      string    `json:"Rate"`
+	UpdatedAt time.Time `json:"UpdatedAt"`
+}
+
+type FailedLogin struct {
+	Uuid      int64     `json:"Uuid"`
+	Username  string    `json:"Username"`
+	ClientIp  string    `json:"ClientIp"`
+	UserAgent string    `json:"UserAgent"`
+	Reason    string    `json:"Reason"`
+	CreatedAt time.Time `json:"CreatedAt"`
+}
+
+type IdempotencyKey struct {
+	Uuid         int64     `json:"Uuid"`
+	UserUuid     int64     `json:"UserUuid"`
+	Key          string    `json:"Key"`
+	RequestHash  string    `json:"RequestHash"`
+	StatusCode   int32     `json:"StatusCode"`
+	ResponseBody []byte    `json:"ResponseBody"`
+	ExpiresAt    time.Time `json:"ExpiresAt"`
+	CreatedAt    time.Time `json:"CreatedAt"`
+	ClaimToken   uuid.UUID `json:"ClaimToken"`
+}
+
+type LoginThrottle struct {
+	Key           string       `json:"Key"`
+	Failures      int32        `json:"Failures"`
+	UpdatedAt     time.Time    `json:"UpdatedAt"`
+	LockedUntil   sql.NullTime `json:"LockedUntil"`
+	LastFailureAt sql.NullTime `json:"LastFailureAt"`
+}
 
 type Order struct {
-	Uuid     int64 `json:"Uuid"`
-	UserUuid int64 `json:"UserUuid"`
-	Quantity int64 `json:"Quantity"`
+	Uuid      int64     `json:"Uuid"`
+	UserUuid  int64     `json:"UserUuid"`
+	Quantity  int64     `json:"Quantity"`
+	CreatedAt time.Time `json:"CreatedAt"`
+	Status    string    `json:"Status"`
 }
 
 type OrderProduct struct {
-	OrderUuid   int64 `json:"OrderUuid"`
-	ProductUuid int64 `json:"ProductUuid"`
+	OrderUuid   int64      `json:"OrderUuid"`
+	ProductUuid int64      `json:"ProductUuid"`
+	Quantity    int32      `json:"Quantity"`
+	UnitPrice   util.Money `json:"UnitPrice"`
+	Currency    string     `json:"Currency"`
+}
+
+type PasswordResetToken struct {
+	Uuid      int64        `json:"Uuid"`
+	UserUuid  int64        `json:"UserUuid"`
+	TokenHash string       `json:"TokenHash"`
+	ExpiresAt time.Time    `json:"ExpiresAt"`
+	UsedAt    sql.NullTime `json:"UsedAt"`
+	CreatedAt time.Time    `json:"CreatedAt"`
+}
+
+type PrivacySetting struct {
+	UserUuid           int64     `json:"UserUuid"`
+	PurchaseVisibility string    `json:"PurchaseVisibility"`
+	UpdatedAt          time.Time `json:"UpdatedAt"`
 }
 
 type Product struct {
-	Uuid        int64   `json:"Uuid"`
-	Description string  `json:"Description"`
-	Price       float32 `json:"Price"`
-	InStock     int32   `json:"InStock"`
+	Uuid         int64         `json:"Uuid"`
+	Description  string        `json:"Description"`
+	Price        util.Money    `json:"Price"`
+	InStock      int32         `json:"InStock"`
+	CategoryUuid sql.NullInt64 `json:"CategoryUuid"`
+	Version      int32         `json:"Version"`
+}
+
+type ProductImage struct {
+	Uuid         int64     `json:"Uuid"`
+	ProductUuid  int64     `json:"ProductUuid"`
+	Key          string    `json:"Key"`
+	ThumbnailKey string    `json:"ThumbnailKey"`
+	CreatedAt    time.Time `json:"CreatedAt"`
+}
+
+type ProductVariant struct {
+	Uuid        int64     `json:"Uuid"`
+	ProductUuid int64     `json:"ProductUuid"`
+	Model       string    `json:"Model"`
+	Color       string    `json:"Color"`
+	StorageGb   int32     `json:"StorageGb"`
+	CreatedAt   time.Time `json:"CreatedAt"`
+}
+
+type Reservation struct {
+	Uuid        int64     `json:"Uuid"`
+	UserUuid    int64     `json:"UserUuid"`
+	ProductUuid int64     `json:"ProductUuid"`
+	Quantity    int32     `json:"Quantity"`
+	ExpiresAt   time.Time `json:"ExpiresAt"`
+	CreatedAt   time.Time `json:"CreatedAt"`
+}
+
+type RevokedToken struct {
+	Uuid      uuid.UUID `json:"Uuid"`
+	Username  string    `json:"Username"`
+	ExpiresAt time.Time `json:"ExpiresAt"`
+	CreatedAt time.Time `json:"CreatedAt"`
+}
+
+type Session struct {
+	Uuid         uuid.UUID `json:"Uuid"`
+	Username     string    `json:"Username"`
+	RefreshToken string    `json:"RefreshToken"`
+	UserAgent    string    `json:"UserAgent"`
+	ClientIp     string    `json:"ClientIp"`
+	IsBlocked    bool      `json:"IsBlocked"`
+	ExpiresAt    time.Time `json:"ExpiresAt"`
+	CreatedAt    time.Time `json:"CreatedAt"`
+}
+
+type Transfer struct {
+	Uuid         int64      `json:"Uuid"`
+	FromUserUuid int64      `json:"FromUserUuid"`
+	ToUserUuid   int64      `json:"ToUserUuid"`
+	Currency     string     `json:"Currency"`
+	Amount       util.Money `json:"Amount"`
+	CreatedAt    time.Time  `json:"CreatedAt"`
 }
 
 type User struct {
-	Uuid           int64   `json:"Uuid"`
-	FirstName      string  `json:"FirstName"`
-	MiddleName     string  `json:"MiddleName"`
-	LastName       string  `json:"LastName"`
-	FullName       string  `json:"FullName"`
-	Gender         string  `json:"Gender"`
-	Age            int16   `json:"Age"`
-	Balance        float32 `json:"Balance"`
-	Username       string  `json:"Username"`
-	HashedPassword string  `json:"HashedPassword"`
+	Uuid           int64  `json:"Uuid"`
+	FirstName      string `json:"FirstName"`
+	MiddleName     string `json:"MiddleName"`
+	LastName       string `json:"LastName"`
+	FullName       string `json:"FullName"`
+	Gender         string `json:"Gender"`
+	Age            int16  `json:"Age"`
+	Username       string `json:"Username"`
+	HashedPassword string `json:"HashedPassword"`
+	Role           string `json:"Role"`
+	Version        int32  `json:"Version"`
 }
 
 type UserToUser struct {
-	FirstUserUuid  int64 `json:"FirstUserUuid"`
-	SecondUserUuid int64 `json:"SecondUserUuid"`
+	FirstUserUuid  int64     `json:"FirstUserUuid"`
+	SecondUserUuid int64     `json:"SecondUserUuid"`
+	CreatedAt      time.Time `json:"CreatedAt"`
+}
+
+type Wallet struct {
+	UserUuid  int64      `json:"UserUuid"`
+	Currency  string     `json:"Currency"`
+	Balance   util.Money `json:"Balance"`
+	CreatedAt time.Time  `json:"CreatedAt"`
 }
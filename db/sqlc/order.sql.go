@@ -7,8 +7,32 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/alekseiapa/apple_store/util"
 )
 
+const countOrdersByFilter = `-- name: CountOrdersByFilter :one
+SELECT count(*) FROM "Order"
+WHERE ($1::bigint IS NULL OR "UserUuid" = $1)
+    AND ($2::timestamptz IS NULL OR "CreatedAt" >= $2)
+    AND ($3::timestamptz IS NULL OR "CreatedAt" < $3)
+`
+
+type CountOrdersByFilterParams struct {
+	UserUuid    sql.NullInt64 `json:"user_uuid"`
+	CreatedFrom sql.NullTime  `json:"created_from"`
+	CreatedTo   sql.NullTime  `json:"created_to"`
+}
+
+func (q *Queries) CountOrdersByFilter(ctx context.Context, arg CountOrdersByFilterParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOrdersByFilter, arg.UserUuid, arg.CreatedFrom, arg.CreatedTo)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createOrder = `-- name: CreateOrder :one
 INSERT INTO "Order" (
 	"UserUuid",
@@ -16,7 +40,7 @@ INSERT INTO "Order" (
 VALUES (
     $1, $2
 )
-RETURNING "Uuid", "UserUuid", "Quantity"
+RETURNING "Uuid", "UserUuid", "Quantity", "CreatedAt", "Status"
 `
 
 type CreateOrderParams struct {
@@ -27,7 +51,13 @@ type CreateOrderParams struct {
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error) {
 	row := q.db.QueryRowContext(ctx, createOrder, arg.UserUuid, arg.Quantity)
 	var i Order
-	err := row.Scan(&i.Uuid, &i.UserUuid, &i.Quantity)
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.Quantity,
+		&i.CreatedAt,
+		&i.Status,
+	)
 	return i, err
 }
 
@@ -45,19 +75,131 @@ func (q *Queries) DeleteOrder(ctx context.Context, uuid int64) (int64, error) {
 }
 
 const getOrder = `-- name: GetOrder :one
-SELECT "Uuid", "UserUuid", "Quantity" FROM "Order"
+SELECT "Uuid", "UserUuid", "Quantity", "CreatedAt", "Status" FROM "Order"
 WHERE "Uuid" = $1 LIMIT 1
 `
 
 func (q *Queries) GetOrder(ctx context.Context, uuid int64) (Order, error) {
 	row := q.db.QueryRowContext(ctx, getOrder, uuid)
 	var i Order
-	err := row.Scan(&i.Uuid, &i.UserUuid, &i.Quantity)
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.Quantity,
+		&i.CreatedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getOrderDetails = `-- name: GetOrderDetails :one
+SELECT
+"Order"."Uuid",
+"Order"."UserUuid",
+"Order"."Quantity",
+"Order"."CreatedAt",
+"Order"."Status",
+"User"."FullName" AS "UserFullName"
+FROM "Order"
+INNER JOIN "User" ON "User"."Uuid" = "Order"."UserUuid"
+WHERE "Order"."Uuid" = $1 LIMIT 1
+`
+
+type GetOrderDetailsRow struct {
+	Uuid         int64     `json:"Uuid"`
+	UserUuid     int64     `json:"UserUuid"`
+	Quantity     int64     `json:"Quantity"`
+	CreatedAt    time.Time `json:"CreatedAt"`
+	Status       string    `json:"Status"`
+	UserFullName string    `json:"UserFullName"`
+}
+
+func (q *Queries) GetOrderDetails(ctx context.Context, uuid int64) (GetOrderDetailsRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrderDetails, uuid)
+	var i GetOrderDetailsRow
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.Quantity,
+		&i.CreatedAt,
+		&i.Status,
+		&i.UserFullName,
+	)
+	return i, err
+}
+
+const getOrderForUpdate = `-- name: GetOrderForUpdate :one
+SELECT "Uuid", "UserUuid", "Quantity", "CreatedAt", "Status" FROM "Order"
+WHERE "Uuid" = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+// This will allow us to block transactions till the end of commit
+func (q *Queries) GetOrderForUpdate(ctx context.Context, uuid int64) (Order, error) {
+	row := q.db.QueryRowContext(ctx, getOrderForUpdate, uuid)
+	var i Order
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.Quantity,
+		&i.CreatedAt,
+		&i.Status,
+	)
 	return i, err
 }
 
+const listOrderLines = `-- name: ListOrderLines :many
+SELECT
+"OrderProduct"."ProductUuid",
+"Product"."Description" AS "ProductDescription",
+"OrderProduct"."Quantity",
+"OrderProduct"."UnitPrice",
+"OrderProduct"."Currency"
+FROM "OrderProduct"
+INNER JOIN "Product" ON "Product"."Uuid" = "OrderProduct"."ProductUuid"
+WHERE "OrderProduct"."OrderUuid" = $1
+ORDER BY "OrderProduct"."ProductUuid"
+`
+
+type ListOrderLinesRow struct {
+	ProductUuid        int64      `json:"ProductUuid"`
+	ProductDescription string     `json:"ProductDescription"`
+	Quantity           int32      `json:"Quantity"`
+	UnitPrice          util.Money `json:"UnitPrice"`
+	Currency           string     `json:"Currency"`
+}
+
+func (q *Queries) ListOrderLines(ctx context.Context, orderUuid int64) ([]ListOrderLinesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOrderLines, orderUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOrderLinesRow{}
+	for rows.Next() {
+		var i ListOrderLinesRow
+		if err := rows.Scan(
+			&i.ProductUuid,
+			&i.ProductDescription,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listOrders = `-- name: ListOrders :many
-SELECT "Uuid", "UserUuid", "Quantity" FROM "Order"
+SELECT "Uuid", "UserUuid", "Quantity", "CreatedAt", "Status" FROM "Order"
 ORDER BY "Uuid"
 LIMIT $1
 OFFSET $2
@@ -77,7 +219,174 @@ func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]Order
 	items := []Order{}
 	for rows.Next() {
 		var i Order
-		if err := rows.Scan(&i.Uuid, &i.UserUuid, &i.Quantity); err != nil {
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.UserUuid,
+			&i.Quantity,
+			&i.CreatedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrdersAfter = `-- name: ListOrdersAfter :many
+SELECT "Uuid", "UserUuid", "Quantity", "CreatedAt", "Status" FROM "Order"
+WHERE ($1::bigint IS NULL OR "UserUuid" = $1)
+    AND ($2::timestamptz IS NULL OR "CreatedAt" >= $2)
+    AND ($3::timestamptz IS NULL OR "CreatedAt" < $3)
+    AND "Uuid" > $4
+ORDER BY "Uuid"
+LIMIT $5
+`
+
+type ListOrdersAfterParams struct {
+	UserUuid    sql.NullInt64 `json:"user_uuid"`
+	CreatedFrom sql.NullTime  `json:"created_from"`
+	CreatedTo   sql.NullTime  `json:"created_to"`
+	AfterUuid   int64         `json:"after_uuid"`
+	Limit       int32         `json:"limit"`
+}
+
+// The page starts right after the order with the uuid, 0 starts from the first order
+func (q *Queries) ListOrdersAfter(ctx context.Context, arg ListOrdersAfterParams) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, listOrdersAfter,
+		arg.UserUuid,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.AfterUuid,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Order{}
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.UserUuid,
+			&i.Quantity,
+			&i.CreatedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrdersBefore = `-- name: ListOrdersBefore :many
+SELECT "Uuid", "UserUuid", "Quantity", "CreatedAt", "Status" FROM "Order"
+WHERE ($1::bigint IS NULL OR "UserUuid" = $1)
+    AND ($2::timestamptz IS NULL OR "CreatedAt" >= $2)
+    AND ($3::timestamptz IS NULL OR "CreatedAt" < $3)
+    AND "Uuid" < $4
+ORDER BY "Uuid" DESC
+LIMIT $5
+`
+
+type ListOrdersBeforeParams struct {
+	UserUuid    sql.NullInt64 `json:"user_uuid"`
+	CreatedFrom sql.NullTime  `json:"created_from"`
+	CreatedTo   sql.NullTime  `json:"created_to"`
+	BeforeUuid  int64         `json:"before_uuid"`
+	Limit       int32         `json:"limit"`
+}
+
+// The page ends right before the order with the uuid, so the orders come in the reverse order
+func (q *Queries) ListOrdersBefore(ctx context.Context, arg ListOrdersBeforeParams) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, listOrdersBefore,
+		arg.UserUuid,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.BeforeUuid,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Order{}
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.UserUuid,
+			&i.Quantity,
+			&i.CreatedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrdersByFilter = `-- name: ListOrdersByFilter :many
+SELECT "Uuid", "UserUuid", "Quantity", "CreatedAt", "Status" FROM "Order"
+WHERE ($1::bigint IS NULL OR "UserUuid" = $1)
+    AND ($2::timestamptz IS NULL OR "CreatedAt" >= $2)
+    AND ($3::timestamptz IS NULL OR "CreatedAt" < $3)
+ORDER BY "Uuid"
+LIMIT $4
+OFFSET $5
+`
+
+type ListOrdersByFilterParams struct {
+	UserUuid    sql.NullInt64 `json:"user_uuid"`
+	CreatedFrom sql.NullTime  `json:"created_from"`
+	CreatedTo   sql.NullTime  `json:"created_to"`
+	Limit       int32         `json:"limit"`
+	Offset      int32         `json:"offset"`
+}
+
+func (q *Queries) ListOrdersByFilter(ctx context.Context, arg ListOrdersByFilterParams) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, listOrdersByFilter,
+		arg.UserUuid,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Order{}
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.UserUuid,
+			&i.Quantity,
+			&i.CreatedAt,
+			&i.Status,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -96,7 +405,7 @@ UPDATE "Order"
   set "UserUuid" = $2,
       "Quantity" = $3
 WHERE "Uuid" = $1
-RETURNING "Uuid", "UserUuid", "Quantity"
+RETURNING "Uuid", "UserUuid", "Quantity", "CreatedAt", "Status"
 `
 
 type UpdateOrderParams struct {
@@ -108,6 +417,40 @@ type UpdateOrderParams struct {
 func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) (Order, error) {
 	row := q.db.QueryRowContext(ctx, updateOrder, arg.Uuid, arg.UserUuid, arg.Quantity)
 	var i Order
-	err := row.Scan(&i.Uuid, &i.UserUuid, &i.Quantity)
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.Quantity,
+		&i.CreatedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const updateOrderStatus = `-- name: UpdateOrderStatus :one
+UPDATE "Order"
+  set "Status" = $1
+WHERE "Uuid" = $2
+    AND "Status" = $3
+RETURNING "Uuid", "UserUuid", "Quantity", "CreatedAt", "Status"
+`
+
+type UpdateOrderStatusParams struct {
+	Status     string `json:"status"`
+	Uuid       int64  `json:"uuid"`
+	FromStatus string `json:"from_status"`
+}
+
+// The status is only changed if nobody changed it since it was read
+func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, updateOrderStatus, arg.Status, arg.Uuid, arg.FromStatus)
+	var i Order
+	err := row.Scan(
+		&i.Uuid,
+		&i.UserUuid,
+		&i.Quantity,
+		&i.CreatedAt,
+		&i.Status,
+	)
 	return i, err
 }
@@ -7,33 +7,52 @@ package db
 
 import (
 	"context"
+
+	"github.com/alekseiapa/apple_store/util"
 )
 
 const createOrderProduct = `-- name: CreateOrderProduct :one
 INSERT INTO "OrderProduct" (
 	"OrderUuid",
-    "ProductUuid") 
+    "ProductUuid",
+    "Quantity",
+    "UnitPrice",
+    "Currency") 
 VALUES (
-    $1,
-    $2
+    $1, $2, $3, $4, $5
 )
-RETURNING "OrderUuid", "ProductUuid"
+RETURNING "OrderUuid", "ProductUuid", "Quantity", "UnitPrice", "Currency"
 `
 
 type CreateOrderProductParams struct {
-	OrderUuid   int64 `json:"OrderUuid"`
-	ProductUuid int64 `json:"ProductUuid"`
+	OrderUuid   int64      `json:"OrderUuid"`
+	ProductUuid int64      `json:"ProductUuid"`
+	Quantity    int32      `json:"Quantity"`
+	UnitPrice   util.Money `json:"UnitPrice"`
+	Currency    string     `json:"Currency"`
 }
 
 func (q *Queries) CreateOrderProduct(ctx context.Context, arg CreateOrderProductParams) (OrderProduct, error) {
-	row := q.db.QueryRowContext(ctx, createOrderProduct, arg.OrderUuid, arg.ProductUuid)
+	row := q.db.QueryRowContext(ctx, createOrderProduct,
+		arg.OrderUuid,
+		arg.ProductUuid,
+		arg.Quantity,
+		arg.UnitPrice,
+		arg.Currency,
+	)
 	var i OrderProduct
-	err := row.Scan(&i.OrderUuid, &i.ProductUuid)
+	err := row.Scan(
+		&i.OrderUuid,
+		&i.ProductUuid,
+		&i.Quantity,
+		&i.UnitPrice,
+		&i.Currency,
+	)
 	return i, err
 }
 
 const getOrderProduct = `-- name: GetOrderProduct :one
-SELECT "OrderUuid", "ProductUuid" FROM "OrderProduct"
+SELECT "OrderUuid", "ProductUuid", "Quantity", "UnitPrice", "Currency" FROM "OrderProduct"
 WHERE "OrderUuid" = $1 
     AND "ProductUuid" = $2 
 LIMIT 1
@@ -47,12 +66,18 @@ type GetOrderProductParams struct {
 func (q *Queries) GetOrderProduct(ctx context.Context, arg GetOrderProductParams) (OrderProduct, error) {
 	row := q.db.QueryRowContext(ctx, getOrderProduct, arg.OrderUuid, arg.ProductUuid)
 	var i OrderProduct
-	err := row.Scan(&i.OrderUuid, &i.ProductUuid)
+	err := row.Scan(
+		&i.OrderUuid,
+		&i.ProductUuid,
+		&i.Quantity,
+		&i.UnitPrice,
+		&i.Currency,
+	)
 	return i, err
 }
 
 const listOrderProducts = `-- name: ListOrderProducts :many
-SELECT "OrderUuid", "ProductUuid" FROM "OrderProduct"
+SELECT "OrderUuid", "ProductUuid", "Quantity", "UnitPrice", "Currency" FROM "OrderProduct"
 ORDER BY "OrderUuid"
 LIMIT $1
 OFFSET $2
@@ -72,7 +97,48 @@ func (q *Queries) ListOrderProducts(ctx context.Context, arg ListOrderProductsPa
 	items := []OrderProduct{}
 	for rows.Next() {
 		var i OrderProduct
-		if err := rows.Scan(&i.OrderUuid, &i.ProductUuid); err != nil {
+		if err := rows.Scan(
+			&i.OrderUuid,
+			&i.ProductUuid,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderProductsByOrder = `-- name: ListOrderProductsByOrder :many
+SELECT "OrderUuid", "ProductUuid", "Quantity", "UnitPrice", "Currency" FROM "OrderProduct"
+WHERE "OrderUuid" = $1
+ORDER BY "ProductUuid"
+`
+
+func (q *Queries) ListOrderProductsByOrder(ctx context.Context, orderUuid int64) ([]OrderProduct, error) {
+	rows, err := q.db.QueryContext(ctx, listOrderProductsByOrder, orderUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OrderProduct{}
+	for rows.Next() {
+		var i OrderProduct
+		if err := rows.Scan(
+			&i.OrderUuid,
+			&i.ProductUuid,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.Currency,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -91,7 +157,7 @@ UPDATE "OrderProduct"
   set "ProductUuid" = $3
 WHERE "OrderUuid" = $1 
     AND "ProductUuid" = $2
-RETURNING "OrderUuid", "ProductUuid"
+RETURNING "OrderUuid", "ProductUuid", "Quantity", "UnitPrice", "Currency"
 `
 
 type UpdateOrderProductParams struct {
@@ -103,6 +169,12 @@ type UpdateOrderProductParams struct {
 func (q *Queries) UpdateOrderProduct(ctx context.Context, arg UpdateOrderProductParams) (OrderProduct, error) {
 	row := q.db.QueryRowContext(ctx, updateOrderProduct, arg.OrderUuid, arg.ProductUuid, arg.ProductUuid_2)
 	var i OrderProduct
-	err := row.Scan(&i.OrderUuid, &i.ProductUuid)
+	err := row.Scan(
+		&i.OrderUuid,
+		&i.ProductUuid,
+		&i.Quantity,
+		&i.UnitPrice,
+		&i.Currency,
+	)
 	return i, err
 }
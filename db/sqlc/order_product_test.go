@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/alekseiapa/apple_store/util"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,6 +16,9 @@ func createRandomOrderProduct(t *testing.T) OrderProduct {
 	arg := CreateOrderProductParams{
 		OrderUuid:   order.Uuid,
 		ProductUuid: product.Uuid,
+		Quantity:    int32(order.Quantity),
+		UnitPrice:   product.Price,
+		Currency:    util.BaseCurrency,
 	}
 	orderProduct, err := testQueries.CreateOrderProduct(context.Background(), arg)
 	require.NoError(t, err)
@@ -21,6 +26,9 @@ func createRandomOrderProduct(t *testing.T) OrderProduct {
 
 	require.Equal(t, arg.OrderUuid, orderProduct.OrderUuid)
 	require.Equal(t, arg.ProductUuid, orderProduct.ProductUuid)
+	require.Equal(t, arg.Quantity, orderProduct.Quantity)
+	require.Equal(t, arg.UnitPrice, orderProduct.UnitPrice)
+	require.Equal(t, arg.Currency, orderProduct.Currency)
 
 	return orderProduct
 }
@@ -30,13 +38,19 @@ func TestCreateOrderProduct(t *testing.T) {
 
 func TestGetOrderProduct(t *testing.T) {
 	orderProduct1 := createRandomOrderProduct(t)
-	arg := GetOrderProductParams(orderProduct1)
+	arg := GetOrderProductParams{
+		OrderUuid:   orderProduct1.OrderUuid,
+		ProductUuid: orderProduct1.ProductUuid,
+	}
 	orderProduct2, err := testQueries.GetOrderProduct(context.Background(), arg)
 	require.NoError(t, err)
 	require.NotEmpty(t, orderProduct2)
 
 	require.Equal(t, orderProduct1.OrderUuid, orderProduct2.OrderUuid)
 	require.Equal(t, orderProduct1.ProductUuid, orderProduct2.ProductUuid)
+	require.Equal(t, orderProduct1.Quantity, orderProduct2.Quantity)
+	require.Equal(t, orderProduct1.UnitPrice, orderProduct2.UnitPrice)
+	require.Equal(t, orderProduct1.Currency, orderProduct2.Currency)
 
 }
 
@@ -66,7 +80,10 @@ func TestDeleteOrderProduct(t *testing.T) {
 
 	require.NoError(t, err)
 
-	getOrderProductArg := GetOrderProductParams(orderProduct1)
+	getOrderProductArg := GetOrderProductParams{
+		OrderUuid:   orderProduct1.OrderUuid,
+		ProductUuid: orderProduct1.ProductUuid,
+	}
 	orderProduct2, err := testQueries.GetOrderProduct(context.Background(), getOrderProductArg)
 	require.Error(t, err)
 	require.EqualError(t, err, sql.ErrNoRows.Error())
@@ -74,6 +91,15 @@ func TestDeleteOrderProduct(t *testing.T) {
 
 }
 
+func TestListOrderProductsByOrder(t *testing.T) {
+	orderProduct1 := createRandomOrderProduct(t)
+
+	orderProducts, err := testQueries.ListOrderProductsByOrder(context.Background(), orderProduct1.OrderUuid)
+	require.NoError(t, err)
+	require.Len(t, orderProducts, 1)
+	require.Equal(t, orderProduct1, orderProducts[0])
+}
+
 func TestOrderProducts(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		createRandomOrderProduct(t)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/alekseiapa/apple_store/util"
 
@@ -19,6 +20,22 @@ func createRandomOrder(t *testing.T) Order {
 	require.NoError(t, err)
 	require.NotEmpty(t, order)
 
+	require.Equal(t, arg.UserUuid, order.UserUuid)
+	require.Equal(t, arg.Quantity, order.Quantity)
+	require.NotZero(t, order.Uuid)
+	require.NotZero(t, order.CreatedAt)
+	return order
+}
+
+func createRandomOrderForUser(t *testing.T, user *User) Order {
+	arg := CreateOrderParams{
+		UserUuid: user.Uuid,
+		Quantity: util.RandomOrderQuantity(),
+	}
+	order, err := testQueries.CreateOrder(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, order)
+
 	require.Equal(t, arg.UserUuid, order.UserUuid)
 	require.Equal(t, arg.Quantity, order.Quantity)
 	require.NotZero(t, order.Uuid)
@@ -55,6 +72,28 @@ func TestUpdateOrder(t *testing.T) {
 
 }
 
+func TestUpdateOrderStatus(t *testing.T) {
+	order1 := createRandomOrder(t)
+	require.Equal(t, util.OrderPaid, order1.Status)
+
+	order2, err := testQueries.UpdateOrderStatus(context.Background(), UpdateOrderStatusParams{
+		Status:     util.OrderShipped,
+		Uuid:       order1.Uuid,
+		FromStatus: order1.Status,
+	})
+	require.NoError(t, err)
+	require.Equal(t, order1.Uuid, order2.Uuid)
+	require.Equal(t, util.OrderShipped, order2.Status)
+
+	// the order isn't paid anymore, so the update is skipped
+	_, err = testQueries.UpdateOrderStatus(context.Background(), UpdateOrderStatusParams{
+		Status:     util.OrderCancelled,
+		Uuid:       order1.Uuid,
+		FromStatus: order1.Status,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
 func TestDeleteOrder(t *testing.T) {
 	order1 := createRandomOrder(t)
 	_, err := testQueries.DeleteOrder(context.Background(), order1.Uuid)
@@ -87,3 +126,123 @@ func TestListOrders(t *testing.T) {
 	}
 
 }
+
+func TestListOrdersByFilter(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 6; i++ {
+		createRandomOrderForUser(t, user)
+	}
+
+	arg := ListOrdersByFilterParams{
+		UserUuid: sql.NullInt64{Int64: user.Uuid, Valid: true},
+		Limit:    5,
+		Offset:   0,
+	}
+	orders, err := testQueries.ListOrdersByFilter(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, orders, 5)
+	for _, order := range orders {
+		require.Equal(t, user.Uuid, order.UserUuid)
+	}
+
+	arg.Offset = 5
+	orders, err = testQueries.ListOrdersByFilter(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+
+	// all the orders were created just now, so none of them was created tomorrow
+	arg.Offset = 0
+	arg.CreatedFrom = sql.NullTime{Time: time.Now().AddDate(0, 0, 1), Valid: true}
+	orders, err = testQueries.ListOrdersByFilter(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, orders)
+
+	arg.CreatedFrom = sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}
+	arg.CreatedTo = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	orders, err = testQueries.ListOrdersByFilter(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, orders, 5)
+}
+
+func TestListOrdersByCursor(t *testing.T) {
+	user := createRandomUser(t)
+	var orders []Order
+	for i := 0; i < 6; i++ {
+		orders = append(orders, createRandomOrderForUser(t, user))
+	}
+	userUuid := sql.NullInt64{Int64: user.Uuid, Valid: true}
+
+	page, err := testQueries.ListOrdersAfter(context.Background(), ListOrdersAfterParams{
+		UserUuid:  userUuid,
+		AfterUuid: 0,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 5)
+	for i, order := range page {
+		require.Equal(t, orders[i].Uuid, order.Uuid)
+	}
+
+	page, err = testQueries.ListOrdersAfter(context.Background(), ListOrdersAfterParams{
+		UserUuid:  userUuid,
+		AfterUuid: page[4].Uuid,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, orders[5].Uuid, page[0].Uuid)
+
+	page, err = testQueries.ListOrdersBefore(context.Background(), ListOrdersBeforeParams{
+		UserUuid:   userUuid,
+		BeforeUuid: orders[5].Uuid,
+		Limit:      5,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 5)
+	for i, order := range page {
+		require.Equal(t, orders[4-i].Uuid, order.Uuid)
+	}
+
+	total, err := testQueries.CountOrdersByFilter(context.Background(), CountOrdersByFilterParams{
+		UserUuid: userUuid,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), total)
+}
+
+func TestGetOrderDetails(t *testing.T) {
+	user := createRandomUser(t)
+	order := createRandomOrderForUser(t, user)
+
+	details, err := testQueries.GetOrderDetails(context.Background(), order.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, order.Uuid, details.Uuid)
+	require.Equal(t, order.UserUuid, details.UserUuid)
+	require.Equal(t, order.Quantity, details.Quantity)
+	require.Equal(t, user.FullName, details.UserFullName)
+	require.WithinDuration(t, order.CreatedAt, details.CreatedAt, time.Second)
+}
+
+func TestListOrderLines(t *testing.T) {
+	user := createRandomUser(t)
+	order := createRandomOrderForUser(t, user)
+	product := createRandomProduct(t)
+
+	_, err := testQueries.CreateOrderProduct(context.Background(), CreateOrderProductParams{
+		OrderUuid:   order.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    int32(order.Quantity),
+		UnitPrice:   product.Price,
+		Currency:    util.BaseCurrency,
+	})
+	require.NoError(t, err)
+
+	lines, err := testQueries.ListOrderLines(context.Background(), order.Uuid)
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	require.Equal(t, product.Uuid, lines[0].ProductUuid)
+	require.Equal(t, product.Description, lines[0].ProductDescription)
+	require.Equal(t, int32(order.Quantity), lines[0].Quantity)
+	require.Equal(t, product.Price, lines[0].UnitPrice)
+	require.Equal(t, util.BaseCurrency, lines[0].Currency)
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPasswordResetToken(t *testing.T, user *User, expiresAt time.Time) PasswordResetToken {
+	arg := CreatePasswordResetTokenParams{
+		UserUuid:  user.Uuid,
+		TokenHash: util.RandomString(64),
+		ExpiresAt: expiresAt,
+	}
+	token, err := testQueries.CreatePasswordResetToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, token.Uuid)
+	require.Equal(t, arg.UserUuid, token.UserUuid)
+	require.Equal(t, arg.TokenHash, token.TokenHash)
+	require.WithinDuration(t, arg.ExpiresAt, token.ExpiresAt, time.Second)
+	require.False(t, token.UsedAt.Valid)
+	require.NotZero(t, token.CreatedAt)
+	return token
+}
+
+func TestUsePasswordResetToken(t *testing.T) {
+	user := createRandomUser(t)
+	token := createRandomPasswordResetToken(t, user, time.Now().Add(time.Hour))
+
+	used, err := testQueries.UsePasswordResetToken(context.Background(), token.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, token.Uuid, used.Uuid)
+	require.True(t, used.UsedAt.Valid)
+
+	// the token can be used only once
+	_, err = testQueries.UsePasswordResetToken(context.Background(), token.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	expired := createRandomPasswordResetToken(t, user, time.Now().Add(-time.Minute))
+	_, err = testQueries.UsePasswordResetToken(context.Background(), expired.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.UsePasswordResetToken(context.Background(), util.RandomString(64))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUseUserPasswordResetTokens(t *testing.T) {
+	user := createRandomUser(t)
+	token1 := createRandomPasswordResetToken(t, user, time.Now().Add(time.Hour))
+	token2 := createRandomPasswordResetToken(t, user, time.Now().Add(time.Hour))
+	other := createRandomPasswordResetToken(t, createRandomUser(t), time.Now().Add(time.Hour))
+
+	err := testQueries.UseUserPasswordResetTokens(context.Background(), user.Uuid)
+	require.NoError(t, err)
+
+	for _, token := range []PasswordResetToken{token1, token2} {
+		_, err = testQueries.UsePasswordResetToken(context.Background(), token.TokenHash)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	}
+	// the tokens of the other users are untouched
+	_, err = testQueries.UsePasswordResetToken(context.Background(), other.TokenHash)
+	require.NoError(t, err)
+}
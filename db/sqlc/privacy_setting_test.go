@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/stretchr/testify/require"
+)
+
+func setPurchaseVisibility(t *testing.T, user *User, visibility string) PrivacySetting {
+	arg := UpsertPrivacySettingParams{
+		UserUuid:           user.Uuid,
+		PurchaseVisibility: visibility,
+	}
+	setting, err := testQueries.UpsertPrivacySetting(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.UserUuid, setting.UserUuid)
+	require.Equal(t, arg.PurchaseVisibility, setting.PurchaseVisibility)
+	require.NotZero(t, setting.UpdatedAt)
+	return setting
+}
+
+func TestUpsertPrivacySetting(t *testing.T) {
+	user := createRandomUser(t)
+	setting1 := setPurchaseVisibility(t, user, util.VisibilityMutual)
+	setting2 := setPurchaseVisibility(t, user, util.VisibilityPrivate)
+	require.False(t, setting2.UpdatedAt.Before(setting1.UpdatedAt))
+
+	_, err := testQueries.UpsertPrivacySetting(context.Background(), UpsertPrivacySettingParams{
+		UserUuid:           user.Uuid,
+		PurchaseVisibility: "everyone",
+	})
+	require.ErrorContains(t, err, "PrivacySetting_PurchaseVisibility_check")
+}
+
+func TestGetPrivacySetting(t *testing.T) {
+	user := createRandomUser(t)
+
+	// the users who never changed their settings have none
+	_, err := testQueries.GetPrivacySetting(context.Background(), user.Uuid)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	setting1 := setPurchaseVisibility(t, user, util.VisibilityPrivate)
+	setting2, err := testQueries.GetPrivacySetting(context.Background(), user.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, setting1, setting2)
+}
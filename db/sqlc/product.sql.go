@@ -7,44 +7,129 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/alekseiapa/apple_store/util"
 )
 
+const addProductInStock = `-- name: AddProductInStock :one
+UPDATE "Product"
+  set "InStock" = "InStock" + $1,
+      "Version" = "Version" + 1
+WHERE "Uuid" = $2
+RETURNING "Uuid", "Description", "Price", "InStock", "CategoryUuid", "Version"
+`
+
+type AddProductInStockParams struct {
+	Amount int32 `json:"amount"`
+	Uuid   int64 `json:"uuid"`
+}
+
+func (q *Queries) AddProductInStock(ctx context.Context, arg AddProductInStockParams) (Product, error) {
+	row := q.db.QueryRowContext(ctx, addProductInStock, arg.Amount, arg.Uuid)
+	var i Product
+	err := row.Scan(
+		&i.Uuid,
+		&i.Description,
+		&i.Price,
+		&i.InStock,
+		&i.CategoryUuid,
+		&i.Version,
+	)
+	return i, err
+}
+
+const countProducts = `-- name: CountProducts :one
+SELECT count(*) FROM "Product"
+`
+
+func (q *Queries) CountProducts(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countProducts)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchProducts = `-- name: CountSearchProducts :one
+SELECT count(*) FROM "Product"
+WHERE ($1::text IS NULL OR to_tsvector('english', "Description") @@ websearch_to_tsquery('english', $1))
+    AND ($2::bigint IS NULL OR "Price" >= $2)
+    AND ($3::bigint IS NULL OR "Price" <= $3)
+    AND (NOT $4::boolean OR "InStock" > 0)
+    AND ($5::bigint IS NULL OR "CategoryUuid" = $5)
+`
+
+type CountSearchProductsParams struct {
+	Search       sql.NullString `json:"search"`
+	MinPrice     sql.NullInt64  `json:"min_price"`
+	MaxPrice     sql.NullInt64  `json:"max_price"`
+	InStock      bool           `json:"in_stock"`
+	CategoryUuid sql.NullInt64  `json:"category_uuid"`
+}
+
+func (q *Queries) CountSearchProducts(ctx context.Context, arg CountSearchProductsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSearchProducts,
+		arg.Search,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.InStock,
+		arg.CategoryUuid,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createProduct = `-- name: CreateProduct :one
 INSERT INTO "Product" (
     "Description",
     "Price",
-    "InStock") 
+    "InStock",
+    "CategoryUuid") 
 VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4
 )
-RETURNING "Uuid", "Description", "Price", "InStock"
+RETURNING "Uuid", "Description", "Price", "InStock", "CategoryUuid", "Version"
 `
 
 type CreateProductParams struct {
-	Description string  `json:"Description"`
-	Price       float32 `json:"Price"`
-	InStock     int32   `json:"InStock"`
+	Description  string        `json:"Description"`
+	Price        util.Money    `json:"Price"`
+	InStock      int32         `json:"InStock"`
+	CategoryUuid sql.NullInt64 `json:"CategoryUuid"`
 }
 
 func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error) {
-	row := q.db.QueryRowContext(ctx, createProduct, arg.Description, arg.Price, arg.InStock)
+	row := q.db.QueryRowContext(ctx, createProduct,
+		arg.Description,
+		arg.Price,
+		arg.InStock,
+		arg.CategoryUuid,
+	)
 	var i Product
 	err := row.Scan(
 		&i.Uuid,
 		&i.Description,
 		&i.Price,
 		&i.InStock,
+		&i.CategoryUuid,
+		&i.Version,
 	)
 	return i, err
 }
 
 const deleteProduct = `-- name: DeleteProduct :execrows
 DELETE FROM "Product"
-WHERE "Uuid" = $1
+WHERE "Uuid" = $1 AND "Version" = COALESCE($2::integer, "Version")
 `
 
-func (q *Queries) DeleteProduct(ctx context.Context, uuid int64) (int64, error) {
-	result, err := q.db.ExecContext(ctx, deleteProduct, uuid)
+type DeleteProductParams struct {
+	Uuid    int64         `json:"uuid"`
+	Version sql.NullInt32 `json:"version"`
+}
+
+func (q *Queries) DeleteProduct(ctx context.Context, arg DeleteProductParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteProduct, arg.Uuid, arg.Version)
 	if err != nil {
 		return 0, err
 	}
@@ -52,7 +137,7 @@ func (q *Queries) DeleteProduct(ctx context.Context, uuid int64) (int64, error)
 }
 
 const getProduct = `-- name: GetProduct :one
-SELECT "Uuid", "Description", "Price", "InStock" FROM "Product"
+SELECT "Uuid", "Description", "Price", "InStock", "CategoryUuid", "Version" FROM "Product"
 WHERE "Uuid" = $1 LIMIT 1
 `
 
@@ -64,12 +149,14 @@ func (q *Queries) GetProduct(ctx context.Context, uuid int64) (Product, error) {
 		&i.Description,
 		&i.Price,
 		&i.InStock,
+		&i.CategoryUuid,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getProductForUpdate = `-- name: GetProductForUpdate :one
-SELECT "Uuid", "Description", "Price", "InStock" FROM "Product"
+SELECT "Uuid", "Description", "Price", "InStock", "CategoryUuid", "Version" FROM "Product"
 WHERE "Uuid" = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -82,12 +169,14 @@ func (q *Queries) GetProductForUpdate(ctx context.Context, uuid int64) (Product,
 		&i.Description,
 		&i.Price,
 		&i.InStock,
+		&i.CategoryUuid,
+		&i.Version,
 	)
 	return i, err
 }
 
 const listProducts = `-- name: ListProducts :many
-SELECT "Uuid", "Description", "Price", "InStock" FROM "Product"
+SELECT "Uuid", "Description", "Price", "InStock", "CategoryUuid", "Version" FROM "Product"
 ORDER BY "Uuid"
 LIMIT $1
 OFFSET $2
@@ -112,6 +201,94 @@ func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]P
 			&i.Description,
 			&i.Price,
 			&i.InStock,
+			&i.CategoryUuid,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductsAfter = `-- name: ListProductsAfter :many
+SELECT "Uuid", "Description", "Price", "InStock", "CategoryUuid", "Version" FROM "Product"
+WHERE "Uuid" > $1
+ORDER BY "Uuid"
+LIMIT $2
+`
+
+type ListProductsAfterParams struct {
+	AfterUuid int64 `json:"after_uuid"`
+	Limit     int32 `json:"limit"`
+}
+
+// The page starts right after the product with the uuid, 0 starts from the first product
+func (q *Queries) ListProductsAfter(ctx context.Context, arg ListProductsAfterParams) ([]Product, error) {
+	rows, err := q.db.QueryContext(ctx, listProductsAfter, arg.AfterUuid, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Product{}
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Description,
+			&i.Price,
+			&i.InStock,
+			&i.CategoryUuid,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductsBefore = `-- name: ListProductsBefore :many
+SELECT "Uuid", "Description", "Price", "InStock", "CategoryUuid", "Version" FROM "Product"
+WHERE "Uuid" < $1
+ORDER BY "Uuid" DESC
+LIMIT $2
+`
+
+type ListProductsBeforeParams struct {
+	BeforeUuid int64 `json:"before_uuid"`
+	Limit      int32 `json:"limit"`
+}
+
+// The page ends right before the product with the uuid, so the products come in the reverse order
+func (q *Queries) ListProductsBefore(ctx context.Context, arg ListProductsBeforeParams) ([]Product, error) {
+	rows, err := q.db.QueryContext(ctx, listProductsBefore, arg.BeforeUuid, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Product{}
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Description,
+			&i.Price,
+			&i.InStock,
+			&i.CategoryUuid,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -126,11 +303,57 @@ func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]P
 	return items, nil
 }
 
+const patchProduct = `-- name: PatchProduct :one
+UPDATE "Product"
+    set "Description" = COALESCE($1::varchar, "Description"),
+        "Price" = COALESCE($2::bigint, "Price"),
+        "InStock" = COALESCE($3::integer, "InStock"),
+        "CategoryUuid" = CASE WHEN $4::boolean THEN NULL
+            ELSE COALESCE($5::bigint, "CategoryUuid") END,
+        "Version" = "Version" + 1
+WHERE "Uuid" = $6 AND "Version" = COALESCE($7::integer, "Version")
+RETURNING "Uuid", "Description", "Price", "InStock", "CategoryUuid", "Version"
+`
+
+type PatchProductParams struct {
+	Description   sql.NullString `json:"description"`
+	Price         sql.NullInt64  `json:"price"`
+	InStock       sql.NullInt32  `json:"in_stock"`
+	ClearCategory bool           `json:"clear_category"`
+	CategoryUuid  sql.NullInt64  `json:"category_uuid"`
+	Uuid          int64          `json:"uuid"`
+	Version       sql.NullInt32  `json:"version"`
+}
+
+// Only the fields that aren't null are changed, the category is removed with clear_category
+func (q *Queries) PatchProduct(ctx context.Context, arg PatchProductParams) (Product, error) {
+	row := q.db.QueryRowContext(ctx, patchProduct,
+		arg.Description,
+		arg.Price,
+		arg.InStock,
+		arg.ClearCategory,
+		arg.CategoryUuid,
+		arg.Uuid,
+		arg.Version,
+	)
+	var i Product
+	err := row.Scan(
+		&i.Uuid,
+		&i.Description,
+		&i.Price,
+		&i.InStock,
+		&i.CategoryUuid,
+		&i.Version,
+	)
+	return i, err
+}
+
 const reduceProductInStock = `-- name: ReduceProductInStock :one
 UPDATE "Product"
-  set "InStock" = "InStock" - $1 
+  set "InStock" = "InStock" - $1,
+      "Version" = "Version" + 1
 WHERE "Uuid" = $2
-RETURNING "Uuid", "Description", "Price", "InStock"
+RETURNING "Uuid", "Description", "Price", "InStock", "CategoryUuid", "Version"
 `
 
 type ReduceProductInStockParams struct {
@@ -146,32 +369,123 @@ func (q *Queries) ReduceProductInStock(ctx context.Context, arg ReduceProductInS
 		&i.Description,
 		&i.Price,
 		&i.InStock,
+		&i.CategoryUuid,
+		&i.Version,
 	)
 	return i, err
 }
 
+const searchProducts = `-- name: SearchProducts :many
+SELECT "Uuid", "Description", "Price", "InStock", "CategoryUuid", "Version" FROM "Product"
+WHERE ($1::text IS NULL OR to_tsvector('english', "Description") @@ websearch_to_tsquery('english', $1))
+    AND ($2::bigint IS NULL OR "Price" >= $2)
+    AND ($3::bigint IS NULL OR "Price" <= $3)
+    AND (NOT $4::boolean OR "InStock" > 0)
+    AND ($5::bigint IS NULL OR "CategoryUuid" = $5)
+    AND ($6::bigint IS NULL OR CASE $7::text
+        WHEN 'price_asc' THEN ("Price", "Uuid") > ($8::bigint, $6)
+        WHEN 'price_desc' THEN ("Price", "Uuid") < ($8::bigint, $6)
+        WHEN 'name' THEN ("Description", "Uuid") > ($9::varchar, $6)
+        WHEN 'newest' THEN "Uuid" < $6
+        ELSE "Uuid" > $6
+    END)
+ORDER BY
+    CASE WHEN $7 = 'price_asc' THEN "Price" END,
+    CASE WHEN $7 = 'price_desc' THEN "Price" END DESC,
+    CASE WHEN $7 = 'name' THEN "Description" END,
+    CASE WHEN $7 IN ('price_desc', 'newest') THEN "Uuid" END DESC,
+    "Uuid"
+LIMIT $10
+OFFSET $11
+`
+
+type SearchProductsParams struct {
+	Search       sql.NullString `json:"search"`
+	MinPrice     sql.NullInt64  `json:"min_price"`
+	MaxPrice     sql.NullInt64  `json:"max_price"`
+	InStock      bool           `json:"in_stock"`
+	CategoryUuid sql.NullInt64  `json:"category_uuid"`
+	AfterUuid    sql.NullInt64  `json:"after_uuid"`
+	Sort         string         `json:"sort"`
+	AfterPrice   sql.NullInt64  `json:"after_price"`
+	AfterName    sql.NullString `json:"after_name"`
+	Limit        int32          `json:"limit"`
+	Offset       int32          `json:"offset"`
+}
+
+// The products are sorted by the price (price_asc, price_desc), the description (name), from the newest or by the uuid.
+// The page starts right after the product the after args point to, they are null on the first page
+func (q *Queries) SearchProducts(ctx context.Context, arg SearchProductsParams) ([]Product, error) {
+	rows, err := q.db.QueryContext(ctx, searchProducts,
+		arg.Search,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.InStock,
+		arg.CategoryUuid,
+		arg.AfterUuid,
+		arg.Sort,
+		arg.AfterPrice,
+		arg.AfterName,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Product{}
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Description,
+			&i.Price,
+			&i.InStock,
+			&i.CategoryUuid,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateProduct = `-- name: UpdateProduct :one
 UPDATE "Product"
-    set "Description" = $2,
-        "Price" = $3,
-        "InStock" = $4
-WHERE "Uuid" = $1
-RETURNING "Uuid", "Description", "Price", "InStock"
+    set "Description" = $1,
+        "Price" = $2,
+        "InStock" = $3,
+        "CategoryUuid" = $4,
+        "Version" = "Version" + 1
+WHERE "Uuid" = $5 AND "Version" = COALESCE($6::integer, "Version")
+RETURNING "Uuid", "Description", "Price", "InStock", "CategoryUuid", "Version"
 `
 
 type UpdateProductParams struct {
-	Uuid        int64   `json:"Uuid"`
-	Description string  `json:"Description"`
-	Price       float32 `json:"Price"`
-	InStock     int32   `json:"InStock"`
+	Description  string        `json:"description"`
+	Price        util.Money    `json:"price"`
+	InStock      int32         `json:"in_stock"`
+	CategoryUuid sql.NullInt64 `json:"category_uuid"`
+	Uuid         int64         `json:"uuid"`
+	Version      sql.NullInt32 `json:"version"`
 }
 
+// The product is only updated when it still has the version, a null version updates any version
 func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error) {
 	row := q.db.QueryRowContext(ctx, updateProduct,
-		arg.Uuid,
 		arg.Description,
 		arg.Price,
 		arg.InStock,
+		arg.CategoryUuid,
+		arg.Uuid,
+		arg.Version,
 	)
 	var i Product
 	err := row.Scan(
@@ -179,6 +493,8 @@ func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (P
 		&i.Description,
 		&i.Price,
 		&i.InStock,
+		&i.CategoryUuid,
+		&i.Version,
 	)
 	return i, err
 }
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomProductImage(t *testing.T, product *Product) ProductImage {
+	name := util.RandomString(8)
+	arg := CreateProductImageParams{
+		ProductUuid:  product.Uuid,
+		Key:          fmt.Sprintf("products/%d/%s.jpg", product.Uuid, name),
+		ThumbnailKey: fmt.Sprintf("products/%d/%s_thumb.jpg", product.Uuid, name),
+	}
+	image, err := testQueries.CreateProductImage(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, image.Uuid)
+	require.Equal(t, arg.ProductUuid, image.ProductUuid)
+	require.Equal(t, arg.Key, image.Key)
+	require.Equal(t, arg.ThumbnailKey, image.ThumbnailKey)
+	require.NotZero(t, image.CreatedAt)
+	return image
+}
+
+func TestListProductImages(t *testing.T) {
+	product1 := createRandomProduct(t)
+	product2 := createRandomProduct(t)
+	image1 := createRandomProductImage(t, product1)
+	image2 := createRandomProductImage(t, product1)
+	image3 := createRandomProductImage(t, product2)
+	createRandomProductImage(t, createRandomProduct(t))
+
+	images, err := testQueries.ListProductImages(context.Background(), []int64{product2.Uuid, product1.Uuid})
+	require.NoError(t, err)
+	require.Equal(t, []ProductImage{image1, image2, image3}, images)
+
+	images, err = testQueries.ListProductImages(context.Background(), []int64{})
+	require.NoError(t, err)
+	require.Empty(t, images)
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"testing"
 
 	"github.com/alekseiapa/apple_store/util"
@@ -28,7 +29,7 @@ func createRandomProduct(t *testing.T) *Product {
 	return &product
 }
 
-func createRandomProductWithPriceAndInStock(t *testing.T, price float32, inStock int32) *Product {
+func createRandomProductWithPriceAndInStock(t *testing.T, price util.Money, inStock int32) *Product {
 	arg := CreateProductParams{
 		Description: util.RandomProductDescription(),
 		Price:       price,
@@ -80,12 +81,67 @@ func TestUpdateProduct(t *testing.T) {
 	require.Equal(t, product2.Description, arg.Description)
 	require.Equal(t, product2.Price, arg.Price)
 	require.Equal(t, product2.InStock, arg.InStock)
+	require.Equal(t, product1.Version+1, product2.Version)
 
 }
 
+func TestUpdateProductVersion(t *testing.T) {
+	product1 := createRandomProduct(t)
+	arg := UpdateProductParams{
+		Uuid:        product1.Uuid,
+		Description: util.RandomString(12),
+		Price:       product1.Price,
+		InStock:     product1.InStock,
+		Version:     sql.NullInt32{Int32: product1.Version, Valid: true},
+	}
+	product2, err := testQueries.UpdateProduct(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, product1.Version+1, product2.Version)
+
+	// the product was changed since the version was read
+	_, err = testQueries.UpdateProduct(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	r, err := testQueries.DeleteProduct(context.Background(), DeleteProductParams{Uuid: product1.Uuid, Version: arg.Version})
+	require.NoError(t, err)
+	require.Zero(t, r)
+	r, err = testQueries.DeleteProduct(context.Background(), DeleteProductParams{
+		Uuid:    product1.Uuid,
+		Version: sql.NullInt32{Int32: product2.Version, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), r)
+}
+
+func TestPatchProduct(t *testing.T) {
+	category := createRandomCategory(t)
+	product1 := createRandomProduct(t)
+	arg := PatchProductParams{
+		Uuid:         product1.Uuid,
+		Price:        sql.NullInt64{Int64: int64(product1.Price) + 100, Valid: true},
+		CategoryUuid: sql.NullInt64{Int64: category.Uuid, Valid: true},
+	}
+	product2, err := testQueries.PatchProduct(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, product1.Price+100, product2.Price)
+	require.Equal(t, arg.CategoryUuid, product2.CategoryUuid)
+	require.Equal(t, product1.Description, product2.Description)
+	require.Equal(t, product1.InStock, product2.InStock)
+	require.Equal(t, product1.Version+1, product2.Version)
+
+	// the category is only removed on purpose
+	product3, err := testQueries.PatchProduct(context.Background(), PatchProductParams{
+		Uuid:          product1.Uuid,
+		ClearCategory: true,
+	})
+	require.NoError(t, err)
+	require.False(t, product3.CategoryUuid.Valid)
+	require.Equal(t, product2.Price, product3.Price)
+}
+
 func TestDeleteProduct(t *testing.T) {
 	product1 := createRandomProduct(t)
-	_, err := testQueries.DeleteProduct(context.Background(), product1.Uuid)
+	_, err := testQueries.DeleteProduct(context.Background(), DeleteProductParams{Uuid: product1.Uuid})
 	require.NoError(t, err)
 
 	product2, err := testQueries.GetProduct(context.Background(), product1.Uuid)
@@ -115,3 +171,89 @@ func TestListProducts(t *testing.T) {
 	}
 
 }
+
+func TestListProductsByCursor(t *testing.T) {
+	product := createRandomProduct(t)
+	for i := 0; i < 5; i++ {
+		createRandomProduct(t)
+	}
+
+	products, err := testQueries.ListProductsAfter(context.Background(), ListProductsAfterParams{
+		AfterUuid: product.Uuid,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, products, 5)
+	for i, got := range products {
+		if i > 0 {
+			require.Greater(t, got.Uuid, products[i-1].Uuid)
+		}
+		require.Greater(t, got.Uuid, product.Uuid)
+	}
+
+	last := products[len(products)-1]
+	products, err = testQueries.ListProductsBefore(context.Background(), ListProductsBeforeParams{
+		BeforeUuid: last.Uuid,
+		Limit:      5,
+	})
+	require.NoError(t, err)
+	require.Len(t, products, 5)
+	require.Equal(t, product.Uuid, products[len(products)-1].Uuid)
+
+	total, err := testQueries.CountProducts(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, total, int64(6))
+}
+
+func TestSearchProducts(t *testing.T) {
+	// the random word only matches the products of the test
+	word := util.RandomString(12)
+	var products []Product
+	for i, price := range []util.Money{15_00, 10_00, 20_00} {
+		product, err := testQueries.CreateProduct(context.Background(), CreateProductParams{
+			Description: fmt.Sprintf("%s model %d", word, i),
+			Price:       price,
+			InStock:     int32(i),
+		})
+		require.NoError(t, err)
+		products = append(products, product)
+	}
+	filter := CountSearchProductsParams{
+		Search: sql.NullString{String: word, Valid: true},
+	}
+
+	arg := SearchProductsParams{
+		Search: filter.Search,
+		Sort:   "price_desc",
+		Limit:  2,
+	}
+	page, err := testQueries.SearchProducts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, products[2].Uuid, page[0].Uuid)
+	require.Equal(t, products[0].Uuid, page[1].Uuid)
+
+	// the next page starts right after the last product of the page
+	arg.AfterUuid = sql.NullInt64{Int64: page[1].Uuid, Valid: true}
+	arg.AfterPrice = sql.NullInt64{Int64: int64(page[1].Price), Valid: true}
+	page, err = testQueries.SearchProducts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, products[1].Uuid, page[0].Uuid)
+
+	arg = SearchProductsParams{
+		Search:   filter.Search,
+		MinPrice: sql.NullInt64{Int64: 12_00, Valid: true},
+		InStock:  true,
+		Sort:     "name",
+		Limit:    5,
+	}
+	page, err = testQueries.SearchProducts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, products[2].Uuid, page[0].Uuid)
+
+	total, err := testQueries.CountSearchProducts(context.Background(), filter)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+}
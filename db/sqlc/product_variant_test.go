@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomVariant(t *testing.T, product *Product) ProductVariant {
+	arg := CreateProductVariantParams{
+		ProductUuid: product.Uuid,
+		Model:       util.RandomString(6),
+		Color:       util.RandomString(5),
+		StorageGb:   int32(util.RandomInt(64, 1024)),
+	}
+	variant, err := testQueries.CreateProductVariant(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, variant.Uuid)
+	require.Equal(t, arg.ProductUuid, variant.ProductUuid)
+	require.Equal(t, arg.Model, variant.Model)
+	require.Equal(t, arg.Color, variant.Color)
+	require.Equal(t, arg.StorageGb, variant.StorageGb)
+	return variant
+}
+
+func TestCreateProductVariant(t *testing.T) {
+	product := createRandomProduct(t)
+	variant := createRandomVariant(t, product)
+
+	_, err := testQueries.CreateProductVariant(context.Background(), CreateProductVariantParams{
+		ProductUuid: product.Uuid,
+		Model:       variant.Model,
+		Color:       variant.Color,
+		StorageGb:   variant.StorageGb,
+	})
+	require.ErrorContains(t, err, "ProductVariant_Attributes_key")
+
+	_, err = testQueries.CreateProductVariant(context.Background(), CreateProductVariantParams{
+		ProductUuid: product.Uuid,
+		Model:       variant.Model,
+		StorageGb:   -1,
+	})
+	require.ErrorContains(t, err, "ProductVariant_StorageGb_check")
+}
+
+func TestListProductVariants(t *testing.T) {
+	product := createRandomProduct(t)
+	for i := 0; i < 3; i++ {
+		createRandomVariant(t, product)
+	}
+	createRandomVariant(t, createRandomProduct(t))
+
+	variants, err := testQueries.ListProductVariants(context.Background(), product.Uuid)
+	require.NoError(t, err)
+	require.Len(t, variants, 3)
+	for _, variant := range variants {
+		require.Equal(t, product.Uuid, variant.ProductUuid)
+	}
+}
+
+func TestUpdateProductVariant(t *testing.T) {
+	product := createRandomProduct(t)
+	variant := createRandomVariant(t, product)
+
+	arg := UpdateProductVariantParams{
+		Uuid:        variant.Uuid,
+		ProductUuid: product.Uuid,
+		Model:       util.RandomString(6),
+		Color:       "",
+		StorageGb:   0,
+	}
+	updated, err := testQueries.UpdateProductVariant(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Model, updated.Model)
+	require.Empty(t, updated.Color)
+	require.Zero(t, updated.StorageGb)
+
+	// the variant can't be reached through another product
+	arg.ProductUuid = createRandomProduct(t).Uuid
+	_, err = testQueries.UpdateProductVariant(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteProductVariant(t *testing.T) {
+	product := createRandomProduct(t)
+	variant := createRandomVariant(t, product)
+
+	r, err := testQueries.DeleteProductVariant(context.Background(), DeleteProductVariantParams{
+		Uuid:        variant.Uuid,
+		ProductUuid: product.Uuid + 1,
+	})
+	require.NoError(t, err)
+	require.Zero(t, r)
+
+	r, err = testQueries.DeleteProductVariant(context.Background(), DeleteProductVariantParams{
+		Uuid:        variant.Uuid,
+		ProductUuid: product.Uuid,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), r)
+}
@@ -6,38 +6,184 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	// Adding a product which is already in the cart increases its quantity
+	AddCartItem(ctx context.Context, arg AddCartItemParams) (CartItem, error)
+	AddProductInStock(ctx context.Context, arg AddProductInStockParams) (Product, error)
+	// The wallet is created the first time money is added in its currency.
+	// A negative amount takes money from the wallet, the check on "Balance" rejects overdrafts
+	AddWalletBalance(ctx context.Context, arg AddWalletBalanceParams) (Wallet, error)
+	BlockSession(ctx context.Context, uuid uuid.UUID) (Session, error)
+	// The refresh tokens of the user can't renew the access tokens anymore
+	BlockUserSessions(ctx context.Context, username string) error
+	ClearCart(ctx context.Context, cartUuid int64) error
+	CountOrdersByFilter(ctx context.Context, arg CountOrdersByFilterParams) (int64, error)
+	CountProducts(ctx context.Context) (int64, error)
+	CountSearchProducts(ctx context.Context, arg CountSearchProductsParams) (int64, error)
+	CountUsers(ctx context.Context) (int64, error)
+	CreateCategory(ctx context.Context, name string) (Category, error)
+	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFailedLogin(ctx context.Context, arg CreateFailedLoginParams) (FailedLogin, error)
+	// The key is only claimed when the user hasn't used it yet, its response has expired
+	// or the request that claimed it is still in progress since before stale_before, like when the server crashed,
+	// otherwise no row is returned
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
 	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
 	CreateOrderProduct(ctx context.Context, arg CreateOrderProductParams) (OrderProduct, error)
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error)
 	CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error)
+	CreateProductImage(ctx context.Context, arg CreateProductImageParams) (ProductImage, error)
+	CreateProductVariant(ctx context.Context, arg CreateProductVariantParams) (ProductVariant, error)
+	CreateRevokedToken(ctx context.Context, arg CreateRevokedTokenParams) error
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateUserToUser(ctx context.Context, arg CreateUserToUserParams) (UserToUser, error)
+	DeleteCartItem(ctx context.Context, arg DeleteCartItemParams) (int64, error)
+	DeleteCategory(ctx context.Context, uuid int64) (int64, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	// Revoked tokens are useless once they have expired since VerifyToken rejects them anyway
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+	// Nothing is deleted when the key was claimed again by another request in the meantime
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteLoginThrottle(ctx context.Context, key string) error
 	DeleteOrder(ctx context.Context, uuid int64) (int64, error)
-	DeleteProduct(ctx context.Context, uuid int64) (int64, error)
-	DeleteUser(ctx context.Context, uuid int64) (int64, error)
+	DeleteProduct(ctx context.Context, arg DeleteProductParams) (int64, error)
+	DeleteProductVariant(ctx context.Context, arg DeleteProductVariantParams) (int64, error)
+	DeleteReservation(ctx context.Context, uuid int64) (int64, error)
+	// The keys without a failure since reset_before would be counted from one again anyway, the locked out keys are kept
+	DeleteStaleLoginThrottles(ctx context.Context, resetBefore time.Time) (int64, error)
+	DeleteUser(ctx context.Context, arg DeleteUserParams) (int64, error)
+	// The reservation of the product is used up once the user buys it
+	DeleteUserReservation(ctx context.Context, arg DeleteUserReservationParams) error
+	DeleteUserToUser(ctx context.Context, arg DeleteUserToUserParams) (int64, error)
+	GetCartByUserUuid(ctx context.Context, userUuid int64) (Cart, error)
+	// The cart is locked before its items are read, so concurrent checkouts of the same cart run one after the other
+	GetCartByUserUuidForUpdate(ctx context.Context, userUuid int64) (Cart, error)
+	GetCategory(ctx context.Context, uuid int64) (Category, error)
+	GetExchangeRate(ctx context.Context, currency string) (ExchangeRate, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetLoginThrottleForUpdate(ctx context.Context, key string) (LoginThrottle, error)
+	// Every user has a single cart, so it is created the first time it is needed
+	GetOrCreateCart(ctx context.Context, userUuid int64) (Cart, error)
 	GetOrder(ctx context.Context, uuid int64) (Order, error)
+	GetOrderDetails(ctx context.Context, uuid int64) (GetOrderDetailsRow, error)
+	// This will allow us to block transactions till the end of commit
+	GetOrderForUpdate(ctx context.Context, uuid int64) (Order, error)
 	GetOrderProduct(ctx context.Context, arg GetOrderProductParams) (OrderProduct, error)
+	GetPrivacySetting(ctx context.Context, userUuid int64) (PrivacySetting, error)
 	GetProduct(ctx context.Context, uuid int64) (Product, error)
 	GetProductForUpdate(ctx context.Context, uuid int64) (Product, error)
+	GetReservation(ctx context.Context, uuid int64) (Reservation, error)
+	// The units the other users hold, they can't be bought or reserved by the user
+	GetReservedQuantity(ctx context.Context, arg GetReservedQuantityParams) (int32, error)
+	GetSession(ctx context.Context, uuid uuid.UUID) (Session, error)
+	GetTransfer(ctx context.Context, uuid int64) (Transfer, error)
 	GetUser(ctx context.Context, uuid int64) (User, error)
 	GetUserByUserName(ctx context.Context, username string) (User, error)
 	// This will allow us to block transactions till the end of commit
 	GetUserForUpdate(ctx context.Context, uuid int64) (User, error)
 	GetUserToUser(ctx context.Context, arg GetUserToUserParams) (UserToUser, error)
+	GetWallet(ctx context.Context, arg GetWalletParams) (Wallet, error)
+	// A token is revoked on its own by logging out,
+	// or together with all the tokens of its user issued before the user's tokens were revoked
+	IsTokenRevoked(ctx context.Context, arg IsTokenRevokedParams) (bool, error)
+	// Items are ordered by "ProductUuid" so that the checkout locks the products in a deterministic order
+	ListCartItems(ctx context.Context, cartUuid int64) ([]CartItem, error)
+	ListCategories(ctx context.Context) ([]Category, error)
+	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListExchangeRates(ctx context.Context) ([]ExchangeRate, error)
+	ListFailedLogins(ctx context.Context, arg ListFailedLoginsParams) ([]FailedLogin, error)
+	// The feed of a user shows the purchases of the users they follow, newest first.
+	// Users without privacy settings show their purchases to all their followers, "mutual" only to the
+	// followers they follow back and "private" to nobody. The page starts after the (created at, uuid) cursor
+	ListFeed(ctx context.Context, arg ListFeedParams) ([]ListFeedRow, error)
+	// Only what was bought is shown in the feed, the prices stay private
+	ListFeedLines(ctx context.Context, orderUuids []int64) ([]ListFeedLinesRow, error)
+	// The followers of a user are the users following them
+	ListFollowers(ctx context.Context, arg ListFollowersParams) ([]ListFollowersRow, error)
+	ListFollowing(ctx context.Context, arg ListFollowingParams) ([]ListFollowingRow, error)
+	ListLoginThrottles(ctx context.Context, keys []string) ([]LoginThrottle, error)
+	// Mutual follows are the users the user follows who follow the user back
+	ListMutualFollows(ctx context.Context, arg ListMutualFollowsParams) ([]ListMutualFollowsRow, error)
+	ListOrderLines(ctx context.Context, orderUuid int64) ([]ListOrderLinesRow, error)
 	ListOrderProducts(ctx context.Context, arg ListOrderProductsParams) ([]OrderProduct, error)
+	ListOrderProductsByOrder(ctx context.Context, orderUuid int64) ([]OrderProduct, error)
 	ListOrders(ctx context.Context, arg ListOrdersParams) ([]Order, error)
+	// The page starts right after the order with the uuid, 0 starts from the first order
+	ListOrdersAfter(ctx context.Context, arg ListOrdersAfterParams) ([]Order, error)
+	// The page ends right before the order with the uuid, so the orders come in the reverse order
+	ListOrdersBefore(ctx context.Context, arg ListOrdersBeforeParams) ([]Order, error)
+	ListOrdersByFilter(ctx context.Context, arg ListOrdersByFilterParams) ([]Order, error)
+	// The images of a page of products are read at once, in the order they were uploaded
+	ListProductImages(ctx context.Context, productUuids []int64) ([]ProductImage, error)
+	ListProductVariants(ctx context.Context, productUuid int64) ([]ProductVariant, error)
 	ListProducts(ctx context.Context, arg ListProductsParams) ([]Product, error)
+	// The page starts right after the product with the uuid, 0 starts from the first product
+	ListProductsAfter(ctx context.Context, arg ListProductsAfterParams) ([]Product, error)
+	// The page ends right before the product with the uuid, so the products come in the reverse order
+	ListProductsBefore(ctx context.Context, arg ListProductsBeforeParams) ([]Product, error)
+	ListReservations(ctx context.Context, userUuid int64) ([]Reservation, error)
+	ListReservedQuantities(ctx context.Context, productUuids []int64) ([]ListReservedQuantitiesRow, error)
 	ListUserToUser(ctx context.Context, arg ListUserToUserParams) ([]UserToUser, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	// The page starts right after the user with the uuid, 0 starts from the first user
+	ListUsersAfter(ctx context.Context, arg ListUsersAfterParams) ([]User, error)
+	// The page ends right before the user with the uuid, so the users come in the reverse order
+	ListUsersBefore(ctx context.Context, arg ListUsersBeforeParams) ([]User, error)
+	ListWallets(ctx context.Context, userUuid int64) ([]Wallet, error)
+	LockLoginThrottle(ctx context.Context, arg LockLoginThrottleParams) (LoginThrottle, error)
+	// Only the fields that aren't null are changed, the category is removed with clear_category
+	PatchProduct(ctx context.Context, arg PatchProductParams) (Product, error)
+	// Only the fields that aren't null are changed
+	PatchUser(ctx context.Context, arg PatchUserParams) (User, error)
+	// The balance of a wallet is always equal to the sum of the amounts of its entries,
+	// a "LedgerBalance" different from the "Balance" means the wallet was changed outside of the ledger
+	ReconcileWallets(ctx context.Context, userUuid int64) ([]ReconcileWalletsRow, error)
 	ReduceProductInStock(ctx context.Context, arg ReduceProductInStockParams) (Product, error)
-	ReduceUserBalance(ctx context.Context, arg ReduceUserBalanceParams) (User, error)
+	// The attempt of a successful login isn't a failure, the last failure before it is put back unless another attempt
+	// was counted in the meantime. The lock is only lifted when the attempt took it, a lock taken by another attempt stays
+	RefundLoginAttempt(ctx context.Context, arg RefundLoginAttemptParams) error
+	ReleaseExpiredReservations(ctx context.Context) (int64, error)
+	// The attempt is counted as a failure before the password is checked. The failures are counted again from one
+	// when the last one is older than reset_before. No row is returned while the key is locked out
+	ReserveLoginAttempt(ctx context.Context, arg ReserveLoginAttemptParams) (LoginThrottle, error)
+	// The tokens of the user issued before issued_before can't be used anymore, a later revocation is never moved back
+	RevokeUserTokens(ctx context.Context, arg RevokeUserTokensParams) error
+	// The products are sorted by the price (price_asc, price_desc), the description (name), from the newest or by the uuid.
+	// The page starts right after the product the after args point to, they are null on the first page
+	SearchProducts(ctx context.Context, arg SearchProductsParams) ([]Product, error)
+	UpdateCartItem(ctx context.Context, arg UpdateCartItemParams) (CartItem, error)
+	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
+	// Nothing is changed when the key was claimed again by another request in the meantime
+	UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) error
 	UpdateOrder(ctx context.Context, arg UpdateOrderParams) (Order, error)
 	UpdateOrderProduct(ctx context.Context, arg UpdateOrderProductParams) (OrderProduct, error)
+	// The status is only changed if nobody changed it since it was read
+	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error)
+	// The product is only updated when it still has the version, a null version updates any version
 	UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error)
+	// The variant is only found among the variants of its product
+	UpdateProductVariant(ctx context.Context, arg UpdateProductVariantParams) (ProductVariant, error)
+	// The user is only updated when it still has the version, a null version updates any version
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
 	UpdateUserToUser(ctx context.Context, arg UpdateUserToUserParams) (UserToUser, error)
+	// Setting the rate of a new currency makes it supported
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error)
+	UpsertPrivacySetting(ctx context.Context, arg UpsertPrivacySettingParams) (PrivacySetting, error)
+	// Reserving a product again replaces the quantity of the reservation and extends it
+	UpsertReservation(ctx context.Context, arg UpsertReservationParams) (Reservation, error)
+	// The token is marked as used at once, so two requests with the same token can't both reset the password.
+	// No row is returned for a token that is unknown, used or expired
+	UsePasswordResetToken(ctx context.Context, tokenHash string) (PasswordResetToken, error)
+	// The other tokens of the user can't be used once the password is reset
+	UseUserPasswordResetTokens(ctx context.Context, userUuid int64) error
 }
 
 var _ Querier = (*Queries)(nil)
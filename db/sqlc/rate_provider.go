@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+
+	"github.com/alekseiapa/apple_store/util"
+)
+
+// SQLRateProvider is a util.RateProvider reading the rates from the "ExchangeRate" table,
+// so the rates updated by an admin are used right away
+type SQLRateProvider struct {
+	q Querier
+}
+
+func NewRateProvider(q Querier) util.RateProvider {
+	return &SQLRateProvider{
+		q: q,
+	}
+}
+
+// Rate returns the current rate of the currency
+func (provider *SQLRateProvider) Rate(ctx context.Context, currency string) (*big.Rat, error) {
+	rate, err := provider.q.GetExchangeRate(ctx, currency)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", util.ErrUnsupportedCurrency, currency)
+		}
+		return nil, err
+	}
+	return util.ParseRate(rate.Rate)
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createReservation(t *testing.T, user *User, product *Product, quantity int32, expiresAt time.Time) Reservation {
+	arg := UpsertReservationParams{
+		UserUuid:    user.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    quantity,
+		ExpiresAt:   expiresAt,
+	}
+	reservation, err := testQueries.UpsertReservation(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, reservation.Uuid)
+	require.Equal(t, arg.UserUuid, reservation.UserUuid)
+	require.Equal(t, arg.ProductUuid, reservation.ProductUuid)
+	require.Equal(t, arg.Quantity, reservation.Quantity)
+	require.WithinDuration(t, arg.ExpiresAt, reservation.ExpiresAt, time.Second)
+	require.NotZero(t, reservation.CreatedAt)
+	return reservation
+}
+
+func TestUpsertReservation(t *testing.T) {
+	user := createRandomUser(t)
+	product := createRandomProduct(t)
+	reservation1 := createReservation(t, user, product, 1, time.Now().Add(time.Minute))
+
+	// the second reservation of the product replaces the quantity of the first one, but not the expiry
+	reservation2, err := testQueries.UpsertReservation(context.Background(), UpsertReservationParams{
+		UserUuid:    user.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    3,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, reservation1.Uuid, reservation2.Uuid)
+	require.Equal(t, int32(3), reservation2.Quantity)
+	require.WithinDuration(t, reservation1.ExpiresAt, reservation2.ExpiresAt, time.Millisecond)
+
+	reservation, err := testQueries.GetReservation(context.Background(), reservation1.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), reservation.Quantity)
+
+	// an expired reservation, which isn't released yet, starts again
+	other := createRandomProduct(t)
+	expired := createReservation(t, user, other, 1, time.Now().Add(-time.Minute))
+	renewed := createReservation(t, user, other, 2, time.Now().Add(time.Hour))
+	require.Equal(t, expired.Uuid, renewed.Uuid)
+}
+
+func TestReservedQuantity(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+	product := createRandomProduct(t)
+	createReservation(t, user1, product, 2, time.Now().Add(time.Minute))
+	createReservation(t, user2, product, 3, time.Now().Add(time.Minute))
+	createReservation(t, createRandomUser(t), product, 4, time.Now().Add(-time.Minute))
+
+	// the expired reservation and the reservation of the user don't count
+	reserved, err := testQueries.GetReservedQuantity(context.Background(), GetReservedQuantityParams{
+		ProductUuid:    product.Uuid,
+		ExceptUserUuid: user1.Uuid,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(3), reserved)
+
+	other := createRandomProduct(t)
+	quantities, err := testQueries.ListReservedQuantities(context.Background(), []int64{product.Uuid, other.Uuid})
+	require.NoError(t, err)
+	require.Equal(t, []ListReservedQuantitiesRow{{ProductUuid: product.Uuid, Reserved: 5}}, quantities)
+
+	reservations, err := testQueries.ListReservations(context.Background(), user2.Uuid)
+	require.NoError(t, err)
+	require.Len(t, reservations, 1)
+	require.Equal(t, product.Uuid, reservations[0].ProductUuid)
+}
+
+func TestReleaseExpiredReservations(t *testing.T) {
+	user := createRandomUser(t)
+	active := createReservation(t, user, createRandomProduct(t), 1, time.Now().Add(time.Minute))
+	expired := createReservation(t, user, createRandomProduct(t), 1, time.Now().Add(-time.Minute))
+
+	n, err := testQueries.ReleaseExpiredReservations(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, n, int64(1))
+
+	_, err = testQueries.GetReservation(context.Background(), expired.Uuid)
+	require.Error(t, err)
+	_, err = testQueries.GetReservation(context.Background(), active.Uuid)
+	require.NoError(t, err)
+
+	n, err = testQueries.DeleteReservation(context.Background(), active.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomRevokedToken(t *testing.T, expiresAt time.Time) CreateRevokedTokenParams {
+	user := createRandomUser(t)
+	arg := CreateRevokedTokenParams{
+		Uuid:      uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: expiresAt,
+	}
+	err := testQueries.CreateRevokedToken(context.Background(), arg)
+	require.NoError(t, err)
+
+	// revoking the same token twice must not fail
+	err = testQueries.CreateRevokedToken(context.Background(), arg)
+	require.NoError(t, err)
+	return arg
+}
+
+func tokenRevoked(t *testing.T, tokenID uuid.UUID, username string, issuedAt time.Time) bool {
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		Uuid:     tokenID,
+		Username: username,
+		IssuedAt: issuedAt,
+	})
+	require.NoError(t, err)
+	return revoked
+}
+
+func TestIsTokenRevoked(t *testing.T) {
+	revokedToken := createRandomRevokedToken(t, time.Now().Add(time.Hour))
+
+	require.True(t, tokenRevoked(t, revokedToken.Uuid, revokedToken.Username, time.Now()))
+	require.False(t, tokenRevoked(t, uuid.New(), revokedToken.Username, time.Now()))
+}
+
+func TestRevokeUserTokens(t *testing.T) {
+	user := createRandomUser(t)
+	revokedAt := time.Now()
+
+	err := testQueries.RevokeUserTokens(context.Background(), RevokeUserTokensParams{
+		Username:     user.Username,
+		IssuedBefore: revokedAt,
+	})
+	require.NoError(t, err)
+
+	// the tokens issued before the revocation are revoked, the later ones aren't
+	require.True(t, tokenRevoked(t, uuid.New(), user.Username, revokedAt.Add(-time.Minute)))
+	require.False(t, tokenRevoked(t, uuid.New(), user.Username, revokedAt.Add(time.Minute)))
+	require.False(t, tokenRevoked(t, uuid.New(), createRandomUser(t).Username, revokedAt.Add(-time.Minute)))
+
+	// an earlier revocation doesn't bring the revoked tokens back
+	err = testQueries.RevokeUserTokens(context.Background(), RevokeUserTokensParams{
+		Username:     user.Username,
+		IssuedBefore: revokedAt.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.True(t, tokenRevoked(t, uuid.New(), user.Username, revokedAt.Add(-time.Minute)))
+}
+
+func TestDeleteExpiredRevokedTokens(t *testing.T) {
+	expiredToken := createRandomRevokedToken(t, time.Now().Add(-time.Minute))
+	activeToken := createRandomRevokedToken(t, time.Now().Add(time.Hour))
+
+	n, err := testQueries.DeleteExpiredRevokedTokens(context.Background())
+	require.NoError(t, err)
+	require.NotZero(t, n)
+
+	require.False(t, tokenRevoked(t, expiredToken.Uuid, expiredToken.Username, time.Now()))
+	require.True(t, tokenRevoked(t, activeToken.Uuid, activeToken.Username, time.Now()))
+}
@@ -3,26 +3,66 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/alekseiapa/apple_store/util"
 )
 
 type Store interface {
 	Querier
 	BuyProductTx(ctx context.Context, arg BuyProductTxParams) (BuyProductTxResult, error)
+	CheckoutTx(ctx context.Context, arg CheckoutTxParams) (CheckoutTxResult, error)
+	CancelOrderTx(ctx context.Context, arg CancelOrderTxParams) (CancelOrderTxResult, error)
+	TopUpTx(ctx context.Context, arg TopUpTxParams) (TopUpTxResult, error)
+	WithdrawTx(ctx context.Context, arg WithdrawTxParams) (WithdrawTxResult, error)
+	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	ReserveTx(ctx context.Context, arg ReserveTxParams) (Reservation, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error)
+	ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error)
+	ReserveLoginAttemptTx(ctx context.Context, arg ReserveLoginAttemptTxParams) (ReserveLoginAttemptTxResult, error)
 }
 
+// ErrEmptyCart is returned by CheckoutTx when there is nothing to buy
+var ErrEmptyCart = errors.New("cart is empty")
+
+// ErrInvalidOrderTransition is returned when an order can't be moved to the requested status
+var ErrInvalidOrderTransition = errors.New("invalid order status transition")
+
+// ErrNotEnoughMoney is returned when none of the wallets of the user can pay for a purchase
+var ErrNotEnoughMoney = errors.New("sorry, you don't have enough money")
+
+// ErrNotEnoughStock is returned when the product has fewer pcs left than the user wants to buy or reserve
+var ErrNotEnoughStock = errors.New("sorry you can't buy since there is not enough pcs left")
+
+// ErrInvalidAmount is returned when the amount of money to move between wallets or the quantity to buy isn't positive
+var ErrInvalidAmount = errors.New("amount must be positive")
+
+// ErrTransferToSelf is returned by TransferTx when both sides of the transfer are the same user
+var ErrTransferToSelf = errors.New("can't transfer money to yourself")
+
+// ErrInvalidResetToken is returned by ResetPasswordTx when the reset token is unknown, was already used or has expired
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+// ErrLoginLocked is returned by ReserveLoginAttemptTx when the username or the client ip is locked out
+var ErrLoginLocked = errors.New("too many failed logins, try again later")
+
 // Store provide all functions to execute db queries and transactions
 // In order to make a support of transactions we should use the Composition here
 
 type SQLStore struct {
 	*Queries
 	db *sql.DB
+	// rates are the same rates the prices are shown with, so the users pay the price they saw
+	rates util.RateProvider
 }
 
-func NewStore(db *sql.DB) Store {
+func NewStore(db *sql.DB, rates util.RateProvider) Store {
 	return &SQLStore{
 		db:      db,
 		Queries: New(db),
+		rates:   rates,
 	}
 }
 
@@ -48,16 +88,19 @@ type BuyProductTxParams struct {
 	UserUuid    int64 `json:"UserUuid"`
 	ProductUuid int64 `json:"ProductUuid"`
 	Quantity    int32 `json:"Quantity"`
+	// Currency of the wallet to pay with. When it is empty, a wallet with enough money is chosen
+	Currency string `json:"Currency"`
 }
 
 // BuyProductTxResult is the result after a successful purchase of a product
 type BuyProductTxResult struct {
-	User    User    `json:"User"`
+	Wallet  Wallet  `json:"Wallet"`
+	Entry   Entry   `json:"Entry"`
 	Order   Order   `json:"Order"`
 	Product Product `json:"Product"`
 }
 
-// Creates on Order.Uuid record, updates User's balance, Add a record to OrderProduct Table
+// Creates on Order.Uuid record, takes the price from one of the User's wallets, Add a record to OrderProduct Table
 func (store *SQLStore) BuyProductTx(ctx context.Context, arg BuyProductTxParams) (BuyProductTxResult, error) {
 	var result BuyProductTxResult
 
@@ -68,21 +111,27 @@ func (store *SQLStore) BuyProductTx(ctx context.Context, arg BuyProductTxParams)
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
 
+		if arg.Quantity <= 0 {
+			return ErrInvalidAmount
+		}
 		product, err := q.GetProductForUpdate(ctx, arg.ProductUuid)
 		if err != nil {
 			return err
 		}
-		inStock := product.InStock - arg.Quantity
-		if inStock < 0 {
-			return fmt.Errorf("sorry you can't buy since there is not enough pcs left. product uuid: %v - %v -> %v pcs left", product.Uuid, product.Description, product.InStock)
+		if err = checkAvailable(ctx, q, product, arg.UserUuid, arg.Quantity); err != nil {
+			return err
 		}
+		// the user is locked, so the balances of the wallets can't change until the end of the transaction
 		user, err := q.GetUserForUpdate(ctx, arg.UserUuid)
 		if err != nil {
 			return err
 		}
-		userBalance := user.Balance - product.Price*float32(arg.Quantity)
-		if userBalance < 0 {
-			return fmt.Errorf("sorry, you don't have enough money to purchase %v pcs of product uuid: %v - %v", arg.Quantity, product.Uuid, product.Description)
+		pay, err := chooseWallet(ctx, q, store.rates, user.Uuid, arg.Currency, []util.Money{product.Price}, []int32{arg.Quantity})
+		if err != nil {
+			if err == ErrNotEnoughMoney {
+				return fmt.Errorf("%w to purchase %v pcs of product uuid: %v - %v", err, arg.Quantity, product.Uuid, product.Description)
+			}
+			return err
 		}
 		result.Product, err = q.ReduceProductInStock(ctx, ReduceProductInStockParams{
 			Amount: arg.Quantity,
@@ -91,31 +140,754 @@ func (store *SQLStore) BuyProductTx(ctx context.Context, arg BuyProductTxParams)
 		if err != nil {
 			return err
 		}
-		result.User, err = q.ReduceUserBalance(ctx, ReduceUserBalanceParams{
-			Uuid:   user.Uuid,
-			Amount: float32(arg.Quantity) * product.Price,
+		result.Order, err = q.CreateOrder(ctx, CreateOrderParams{
+			UserUuid: user.Uuid,
+			Quantity: int64(arg.Quantity),
 		})
 		if err != nil {
 			return err
 		}
-		result.Order, err = q.CreateOrder(ctx, CreateOrderParams{
-			UserUuid: user.Uuid,
-			Quantity: int64(arg.Quantity),
+		result.Wallet, result.Entry, err = addWalletEntry(ctx, q, CreateEntryParams{
+			UserUuid:  user.Uuid,
+			Currency:  pay.Wallet.Currency,
+			Amount:    -pay.Total,
+			Kind:      util.EntryPurchase,
+			OrderUuid: sql.NullInt64{Int64: result.Order.Uuid, Valid: true},
 		})
 		if err != nil {
 			return err
 		}
+		// the price paid is copied, so the order can be reconstructed even if the product's price
+		// or the exchange rates change later
 		_, err = q.CreateOrderProduct(ctx, CreateOrderProductParams{
 			OrderUuid:   result.Order.Uuid,
 			ProductUuid: result.Product.Uuid,
+			Quantity:    arg.Quantity,
+			UnitPrice:   pay.UnitPrices[0],
+			Currency:    pay.Wallet.Currency,
 		})
 		if err != nil {
 			return err
 		}
 
-		return nil
+		// the units the user held are bought now
+		return q.DeleteUserReservation(ctx, DeleteUserReservationParams{
+			UserUuid:    user.Uuid,
+			ProductUuid: product.Uuid,
+		})
+	})
+
+	return result, err
+}
+
+// CheckoutTxParams contains all the necessary parameters to buy everything in the user's cart
+type CheckoutTxParams struct {
+	UserUuid int64 `json:"UserUuid"`
+	// Currency of the wallet to pay with. When it is empty, a wallet with enough money is chosen
+	Currency string `json:"Currency"`
+}
+
+// CheckoutTxResult is the result after a successful checkout of the cart
+type CheckoutTxResult struct {
+	Wallet        Wallet         `json:"Wallet"`
+	Entry         Entry          `json:"Entry"`
+	Order         Order          `json:"Order"`
+	Products      []Product      `json:"Products"`
+	OrderProducts []OrderProduct `json:"OrderProducts"`
+}
+
+// Creates one Order with a record in OrderProduct table for every item of the cart,
+// reduces InStock of every product, debits one of the User's wallets once for the total and empties the cart
+func (store *SQLStore) CheckoutTx(ctx context.Context, arg CheckoutTxParams) (CheckoutTxResult, error) {
+	var result CheckoutTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// a concurrent checkout of the same cart waits here until the first one has emptied the cart,
+		// otherwise both would buy the same items
+		cart, err := q.GetCartByUserUuidForUpdate(ctx, arg.UserUuid)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrEmptyCart
+			}
+			return err
+		}
+		// the items are sorted by product uuid, so concurrent checkouts always lock the products
+		// in the same order and can't deadlock each other
+		items, err := q.ListCartItems(ctx, cart.Uuid)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return ErrEmptyCart
+		}
+
+		var quantity int64
+		products := make([]Product, len(items))
+		prices := make([]util.Money, len(items))
+		quantities := make([]int32, len(items))
+		for i, item := range items {
+			products[i], err = q.GetProductForUpdate(ctx, item.ProductUuid)
+			if err != nil {
+				return err
+			}
+			if err = checkAvailable(ctx, q, products[i], arg.UserUuid, item.Quantity); err != nil {
+				return err
+			}
+			prices[i] = products[i].Price
+			quantities[i] = item.Quantity
+			quantity += int64(item.Quantity)
+		}
+
+		user, err := q.GetUserForUpdate(ctx, arg.UserUuid)
+		if err != nil {
+			return err
+		}
+		pay, err := chooseWallet(ctx, q, store.rates, user.Uuid, arg.Currency, prices, quantities)
+		if err != nil {
+			if err == ErrNotEnoughMoney {
+				return fmt.Errorf("%w to purchase the cart of %v pcs", err, quantity)
+			}
+			return err
+		}
+
+		for i, item := range items {
+			products[i], err = q.ReduceProductInStock(ctx, ReduceProductInStockParams{
+				Amount: item.Quantity,
+				Uuid:   item.ProductUuid,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		result.Products = products
+
+		result.Order, err = q.CreateOrder(ctx, CreateOrderParams{
+			UserUuid: user.Uuid,
+			Quantity: quantity,
+		})
+		if err != nil {
+			return err
+		}
+		result.Wallet, result.Entry, err = addWalletEntry(ctx, q, CreateEntryParams{
+			UserUuid:  user.Uuid,
+			Currency:  pay.Wallet.Currency,
+			Amount:    -pay.Total,
+			Kind:      util.EntryPurchase,
+			OrderUuid: sql.NullInt64{Int64: result.Order.Uuid, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		result.OrderProducts = make([]OrderProduct, len(items))
+		for i, item := range items {
+			result.OrderProducts[i], err = q.CreateOrderProduct(ctx, CreateOrderProductParams{
+				OrderUuid:   result.Order.Uuid,
+				ProductUuid: item.ProductUuid,
+				Quantity:    item.Quantity,
+				UnitPrice:   pay.UnitPrices[i],
+				Currency:    pay.Wallet.Currency,
+			})
+			if err != nil {
+				return err
+			}
+			err = q.DeleteUserReservation(ctx, DeleteUserReservationParams{
+				UserUuid:    user.Uuid,
+				ProductUuid: item.ProductUuid,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return q.ClearCart(ctx, cart.Uuid)
+	})
+
+	return result, err
+}
+
+// CancelOrderTxParams contains all the necessary parameters to cancel or refund an order
+type CancelOrderTxParams struct {
+	OrderUuid int64 `json:"OrderUuid"`
+	// Status is either util.OrderCancelled or util.OrderRefunded
+	Status string `json:"Status"`
+}
+
+// CancelOrderTxResult is the result after a successful cancellation of an order
+type CancelOrderTxResult struct {
+	// Wallets that were paid back and their entries, there is none for a pending order
+	Wallets  []Wallet  `json:"Wallets"`
+	Entries  []Entry   `json:"Entries"`
+	Order    Order     `json:"Order"`
+	Products []Product `json:"Products"`
+}
+
+// Moves the order to the cancelled or refunded status, puts every product of the order back in stock
+// and pays back to the User's wallets what was paid for the order
+func (store *SQLStore) CancelOrderTx(ctx context.Context, arg CancelOrderTxParams) (CancelOrderTxResult, error) {
+	var result CancelOrderTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if arg.Status != util.OrderCancelled && arg.Status != util.OrderRefunded {
+			return fmt.Errorf("%w: %v is neither %v nor %v", ErrInvalidOrderTransition, arg.Status, util.OrderCancelled, util.OrderRefunded)
+		}
+		// the order is locked first, so it can't be cancelled twice by concurrent requests
+		order, err := q.GetOrderForUpdate(ctx, arg.OrderUuid)
+		if err != nil {
+			return err
+		}
+		if !util.CanTransitionOrder(order.Status, arg.Status) {
+			return fmt.Errorf("%w: %v -> %v", ErrInvalidOrderTransition, order.Status, arg.Status)
+		}
+		// the lines are sorted by product uuid, so the products are locked in the same order as in CheckoutTx
+		lines, err := q.ListOrderProductsByOrder(ctx, order.Uuid)
+		if err != nil {
+			return err
+		}
+
+		// the lines keep the currency they were paid in, so the money goes back to the same wallets
+		// and doesn't depend on the current exchange rates
+		var currencies []string
+		totals := make(map[string]util.Money)
+		result.Products = make([]Product, len(lines))
+		for i, line := range lines {
+			result.Products[i], err = q.AddProductInStock(ctx, AddProductInStockParams{
+				Amount: line.Quantity,
+				Uuid:   line.ProductUuid,
+			})
+			if err != nil {
+				return err
+			}
+			if _, ok := totals[line.Currency]; !ok {
+				currencies = append(currencies, line.Currency)
+			}
+			totals[line.Currency] += line.UnitPrice * util.Money(line.Quantity)
+		}
+
+		if _, err = q.GetUserForUpdate(ctx, order.UserUuid); err != nil {
+			return err
+		}
+		// a pending order hasn't been paid yet, so there is nothing to give back
+		if order.Status != util.OrderPending {
+			for _, currency := range currencies {
+				wallet, entry, err := addWalletEntry(ctx, q, CreateEntryParams{
+					UserUuid:  order.UserUuid,
+					Currency:  currency,
+					Amount:    totals[currency],
+					Kind:      util.EntryRefund,
+					OrderUuid: sql.NullInt64{Int64: order.Uuid, Valid: true},
+				})
+				if err != nil {
+					return err
+				}
+				result.Wallets = append(result.Wallets, wallet)
+				result.Entries = append(result.Entries, entry)
+			}
+		}
+
+		result.Order, err = q.UpdateOrderStatus(ctx, UpdateOrderStatusParams{
+			Status:     arg.Status,
+			Uuid:       order.Uuid,
+			FromStatus: order.Status,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// TopUpTxParams contains all the necessary parameters to add money to a wallet
+type TopUpTxParams struct {
+	UserUuid int64      `json:"UserUuid"`
+	Currency string     `json:"Currency"`
+	Amount   util.Money `json:"Amount"`
+}
+
+// TopUpTxResult is the result after a successful top up of a wallet
+type TopUpTxResult struct {
+	Wallet Wallet `json:"Wallet"`
+	Entry  Entry  `json:"Entry"`
+}
+
+// Adds money to the User's wallet of the currency, the wallet is created if the user has none in this currency
+func (store *SQLStore) TopUpTx(ctx context.Context, arg TopUpTxParams) (TopUpTxResult, error) {
+	var result TopUpTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if arg.Amount <= 0 {
+			return ErrInvalidAmount
+		}
+		user, err := q.GetUserForUpdate(ctx, arg.UserUuid)
+		if err != nil {
+			return err
+		}
+		if _, err = store.rates.Rate(ctx, arg.Currency); err != nil {
+			return err
+		}
+		result.Wallet, result.Entry, err = addWalletEntry(ctx, q, CreateEntryParams{
+			UserUuid: user.Uuid,
+			Currency: arg.Currency,
+			Amount:   arg.Amount,
+			Kind:     util.EntryTopUp,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// WithdrawTxParams contains all the necessary parameters to take money from a wallet
+type WithdrawTxParams struct {
+	UserUuid int64      `json:"UserUuid"`
+	Currency string     `json:"Currency"`
+	Amount   util.Money `json:"Amount"`
+}
+
+// WithdrawTxResult is the result after a successful withdrawal from a wallet
+type WithdrawTxResult struct {
+	Wallet Wallet `json:"Wallet"`
+	Entry  Entry  `json:"Entry"`
+}
+
+// Takes money from the User's wallet of the currency
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg WithdrawTxParams) (WithdrawTxResult, error) {
+	var result WithdrawTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if arg.Amount <= 0 {
+			return ErrInvalidAmount
+		}
+		user, err := q.GetUserForUpdate(ctx, arg.UserUuid)
+		if err != nil {
+			return err
+		}
+		if err = checkBalance(ctx, q, store.rates, user.Uuid, arg.Currency, arg.Amount); err != nil {
+			if err == ErrNotEnoughMoney {
+				return fmt.Errorf("%w to withdraw %v %v", err, arg.Amount, arg.Currency)
+			}
+			return err
+		}
+		result.Wallet, result.Entry, err = addWalletEntry(ctx, q, CreateEntryParams{
+			UserUuid: user.Uuid,
+			Currency: arg.Currency,
+			Amount:   -arg.Amount,
+			Kind:     util.EntryWithdrawal,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// TransferTxParams contains all the necessary parameters to move money from one user to another
+type TransferTxParams struct {
+	FromUserUuid int64      `json:"FromUserUuid"`
+	ToUserUuid   int64      `json:"ToUserUuid"`
+	Currency     string     `json:"Currency"`
+	Amount       util.Money `json:"Amount"`
+}
+
+// TransferTxResult is the result after a successful transfer
+type TransferTxResult struct {
+	Transfer   Transfer `json:"Transfer"`
+	FromWallet Wallet   `json:"FromWallet"`
+	ToWallet   Wallet   `json:"ToWallet"`
+	FromEntry  Entry    `json:"FromEntry"`
+	ToEntry    Entry    `json:"ToEntry"`
+}
+
+// Takes money from a wallet of one User and adds it to the wallet of the same currency of another User.
+// Both sides of the transfer are recorded in the ledger with entries pointing to the Transfer record
+func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if arg.Amount <= 0 {
+			return ErrInvalidAmount
+		}
+		if arg.FromUserUuid == arg.ToUserUuid {
+			return ErrTransferToSelf
+		}
+		// the users are always locked in the order of their uuid,
+		// so opposite transfers between the same users can't deadlock each other
+		first, second := arg.FromUserUuid, arg.ToUserUuid
+		if first > second {
+			first, second = second, first
+		}
+		if _, err := q.GetUserForUpdate(ctx, first); err != nil {
+			return err
+		}
+		if _, err := q.GetUserForUpdate(ctx, second); err != nil {
+			return err
+		}
+		if err := checkBalance(ctx, q, store.rates, arg.FromUserUuid, arg.Currency, arg.Amount); err != nil {
+			if err == ErrNotEnoughMoney {
+				return fmt.Errorf("%w to transfer %v %v", err, arg.Amount, arg.Currency)
+			}
+			return err
+		}
+
+		var err error
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromUserUuid: arg.FromUserUuid,
+			ToUserUuid:   arg.ToUserUuid,
+			Currency:     arg.Currency,
+			Amount:       arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+		transferUuid := sql.NullInt64{Int64: result.Transfer.Uuid, Valid: true}
+		result.FromWallet, result.FromEntry, err = addWalletEntry(ctx, q, CreateEntryParams{
+			UserUuid:     arg.FromUserUuid,
+			Currency:     arg.Currency,
+			Amount:       -arg.Amount,
+			Kind:         util.EntryTransfer,
+			TransferUuid: transferUuid,
+		})
+		if err != nil {
+			return err
+		}
+		result.ToWallet, result.ToEntry, err = addWalletEntry(ctx, q, CreateEntryParams{
+			UserUuid:     arg.ToUserUuid,
+			Currency:     arg.Currency,
+			Amount:       arg.Amount,
+			Kind:         util.EntryTransfer,
+			TransferUuid: transferUuid,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ReserveTxParams contains all the necessary parameters to hold the pcs of a product for a user
+type ReserveTxParams struct {
+	UserUuid    int64 `json:"UserUuid"`
+	ProductUuid int64 `json:"ProductUuid"`
+	Quantity    int32 `json:"Quantity"`
+	// Duration is how long the pcs are held, the reservation is released after it
+	Duration time.Duration `json:"Duration"`
+}
+
+// Holds the pcs of the product for the User, so nobody else can buy or reserve them until the reservation expires.
+// Reserving the same product again replaces the quantity but keeps the expiry, so the pcs are freed in time
+func (store *SQLStore) ReserveTx(ctx context.Context, arg ReserveTxParams) (Reservation, error) {
+	var result Reservation
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if arg.Quantity <= 0 {
+			return ErrInvalidAmount
+		}
+		// the product is locked as in the purchases, so the pcs can't be reserved and bought at the same time
+		product, err := q.GetProductForUpdate(ctx, arg.ProductUuid)
+		if err != nil {
+			return err
+		}
+		if err = checkAvailable(ctx, q, product, arg.UserUuid, arg.Quantity); err != nil {
+			return err
+		}
+		result, err = q.UpsertReservation(ctx, UpsertReservationParams{
+			UserUuid:    arg.UserUuid,
+			ProductUuid: product.Uuid,
+			Quantity:    arg.Quantity,
+			ExpiresAt:   time.Now().Add(arg.Duration),
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ResetPasswordTxParams contains all the necessary parameters to set a new password with a reset token
+type ResetPasswordTxParams struct {
+	TokenHash      string `json:"TokenHash"`
+	HashedPassword string `json:"HashedPassword"`
+}
+
+// Uses up the reset token and sets the new password of its User. The other reset tokens of the User are used up,
+// the sessions are blocked and the tokens are revoked too, since someone else could know the old password
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var result User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		token, err := q.UsePasswordResetToken(ctx, arg.TokenHash)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+		result, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			Uuid:           token.UserUuid,
+			HashedPassword: arg.HashedPassword,
+		})
+		if err != nil {
+			return err
+		}
+		if err = q.UseUserPasswordResetTokens(ctx, result.Uuid); err != nil {
+			return err
+		}
+		if err = q.BlockUserSessions(ctx, result.Username); err != nil {
+			return err
+		}
+		// the access tokens are still valid after the sessions are blocked, so they are revoked too
+		return q.RevokeUserTokens(ctx, RevokeUserTokensParams{
+			Username:     result.Username,
+			IssuedBefore: time.Now(),
+		})
+	})
+
+	return result, err
+}
+
+// ChangePasswordTxParams contains all the necessary parameters to change the password of a user
+type ChangePasswordTxParams struct {
+	Uuid           int64  `json:"Uuid"`
+	HashedPassword string `json:"HashedPassword"`
+}
+
+// Sets the new password of the User. The sessions are blocked and the tokens are revoked like on a reset,
+// since the password could be changed because someone else knows the old one
+func (store *SQLStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	var result User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			Uuid:           arg.Uuid,
+			HashedPassword: arg.HashedPassword,
+		})
+		if err != nil {
+			return err
+		}
+		if err = q.BlockUserSessions(ctx, result.Username); err != nil {
+			return err
+		}
+		return q.RevokeUserTokens(ctx, RevokeUserTokensParams{
+			Username:     result.Username,
+			IssuedBefore: time.Now(),
+		})
+	})
+
+	return result, err
+}
+
+// LoginThrottleLimit is the key of a throttle and the failures in a row after which the key is locked out.
+// No max failures disables the lockout of the key
+type LoginThrottleLimit struct {
+	Key         string `json:"Key"`
+	MaxFailures int32  `json:"MaxFailures"`
+}
+
+// ReserveLoginAttemptTxParams contains all the necessary parameters to reserve a login attempt
+type ReserveLoginAttemptTxParams struct {
+	Limits      []LoginThrottleLimit `json:"Limits"`
+	ResetBefore time.Time            `json:"ResetBefore"`
+	Lockout     time.Duration        `json:"Lockout"`
+	MaxLockout  time.Duration        `json:"MaxLockout"`
+}
+
+// ReservedLoginAttempt is the throttle of a key after the attempt was counted. The last failure before the attempt
+// is kept, so it can be put back when the attempt turns out not to be a failure
+type ReservedLoginAttempt struct {
+	Throttle          LoginThrottle `json:"Throttle"`
+	PreviousFailureAt sql.NullTime  `json:"PreviousFailureAt"`
+}
+
+// ReserveLoginAttemptTxResult is the result of the reservation of a login attempt
+type ReserveLoginAttemptTxResult struct {
+	Attempts []ReservedLoginAttempt `json:"Attempts"`
+	// LockedUntil is when the lockout ends when ErrLoginLocked is returned
+	LockedUntil time.Time `json:"LockedUntil"`
+}
+
+// Counts the login attempt as a failure of every key before the password is checked, so concurrent attempts
+// can't all get past the lockout. A key is locked out as soon as an attempt reaches its max failures,
+// the attempt itself still goes on. ErrLoginLocked is returned when a key is locked out, nothing is counted then
+func (store *SQLStore) ReserveLoginAttemptTx(ctx context.Context, arg ReserveLoginAttemptTxParams) (ReserveLoginAttemptTxResult, error) {
+	var result ReserveLoginAttemptTxResult
 
+	err := store.execTx(ctx, func(q *Queries) error {
+		keys := make([]string, len(arg.Limits))
+		for i, limit := range arg.Limits {
+			keys[i] = limit.Key
+		}
+		for _, limit := range arg.Limits {
+			// the row stays locked until the end of the transaction, so the last failure read is the one replaced
+			previous, err := q.GetLoginThrottleForUpdate(ctx, limit.Key)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			throttle, err := q.ReserveLoginAttempt(ctx, ReserveLoginAttemptParams{
+				Key:         limit.Key,
+				ResetBefore: arg.ResetBefore,
+			})
+			if err == sql.ErrNoRows {
+				// the rollback takes back the attempt counted for the other keys
+				result.LockedUntil, err = lockedUntil(ctx, q, keys)
+				if err != nil {
+					return err
+				}
+				return ErrLoginLocked
+			}
+			if err != nil {
+				return err
+			}
+			if limit.MaxFailures > 0 && throttle.Failures >= limit.MaxFailures {
+				lockout := loginLockout(throttle.Failures, limit.MaxFailures, arg.Lockout, arg.MaxLockout)
+				throttle, err = q.LockLoginThrottle(ctx, LockLoginThrottleParams{
+					Key:         limit.Key,
+					LockedUntil: sql.NullTime{Time: throttle.UpdatedAt.Add(lockout), Valid: true},
+				})
+				if err != nil {
+					return err
+				}
+			}
+			result.Attempts = append(result.Attempts, ReservedLoginAttempt{
+				Throttle:          throttle,
+				PreviousFailureAt: previous.LastFailureAt,
+			})
+		}
+		return nil
 	})
 
 	return result, err
 }
+
+// loginLockout returns how long a key is locked out after the failures. The key is locked out once it has
+// maxFailures failures in a row and every failure after that doubles the lockout, up to maxLockout
+func loginLockout(failures int32, maxFailures int32, lockout time.Duration, maxLockout time.Duration) time.Duration {
+	for i := maxFailures; i < failures && lockout < maxLockout; i++ {
+		lockout *= 2
+	}
+	if maxLockout > 0 && lockout > maxLockout {
+		lockout = maxLockout
+	}
+	return lockout
+}
+
+// lockedUntil returns when the last lockout of the keys ends
+func lockedUntil(ctx context.Context, q *Queries, keys []string) (time.Time, error) {
+	throttles, err := q.ListLoginThrottles(ctx, keys)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var until time.Time
+	for _, throttle := range throttles {
+		if throttle.LockedUntil.Valid && throttle.LockedUntil.Time.After(until) {
+			until = throttle.LockedUntil.Time
+		}
+	}
+	return until, nil
+}
+
+// checkAvailable returns ErrNotEnoughStock when the product has fewer pcs left for the user than the quantity.
+// The pcs the other users hold are not available, the user's own reservation is.
+// The product must be locked, so the stock and the reservations can't change until the end of the transaction
+func checkAvailable(ctx context.Context, q *Queries, product Product, userUuid int64, quantity int32) error {
+	reserved, err := q.GetReservedQuantity(ctx, GetReservedQuantityParams{
+		ProductUuid:    product.Uuid,
+		ExceptUserUuid: userUuid,
+	})
+	if err != nil {
+		return err
+	}
+	if available := product.InStock - reserved; available < quantity {
+		return fmt.Errorf("%w. product uuid: %v - %v -> %v pcs left", ErrNotEnoughStock, product.Uuid, product.Description, available)
+	}
+	return nil
+}
+
+// addWalletEntry changes the balance of a wallet and records the change in the ledger.
+// Every change of a balance must go through it, so the balance always matches the sum of the entries
+func addWalletEntry(ctx context.Context, q *Queries, arg CreateEntryParams) (Wallet, Entry, error) {
+	wallet, err := q.AddWalletBalance(ctx, AddWalletBalanceParams{
+		UserUuid: arg.UserUuid,
+		Currency: arg.Currency,
+		Amount:   arg.Amount,
+	})
+	if err != nil {
+		return wallet, Entry{}, err
+	}
+	entry, err := q.CreateEntry(ctx, arg)
+	return wallet, entry, err
+}
+
+// checkBalance returns ErrNotEnoughMoney when the wallet of the user doesn't hold the amount.
+// The user must be locked, so the balance can't change until the end of the transaction
+func checkBalance(ctx context.Context, q *Queries, rates util.RateProvider, userUuid int64, currency string, amount util.Money) error {
+	if _, err := rates.Rate(ctx, currency); err != nil {
+		return err
+	}
+	wallet, err := q.GetWallet(ctx, GetWalletParams{
+		UserUuid: userUuid,
+		Currency: currency,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotEnoughMoney
+		}
+		return err
+	}
+	if wallet.Balance < amount {
+		return ErrNotEnoughMoney
+	}
+	return nil
+}
+
+// payment is what a user pays with one of the wallets for some products
+type payment struct {
+	Wallet Wallet
+	// UnitPrices are the prices of the products converted to the currency of the wallet
+	UnitPrices []util.Money
+	Total      util.Money
+}
+
+// chooseWallet finds the wallet of the user to pay for the products with. The prices are in the base currency
+// and are converted to the currency of the wallet. When currency is empty, the wallet of the base currency
+// is tried first and then the other wallets in the order of their currency.
+// The user must be locked, so the balances of the wallets can't change until the end of the transaction
+func chooseWallet(ctx context.Context, q *Queries, rates util.RateProvider, userUuid int64, currency string, prices []util.Money, quantities []int32) (payment, error) {
+	if currency != "" {
+		if _, err := rates.Rate(ctx, currency); err != nil {
+			return payment{}, err
+		}
+	}
+	wallets, err := q.ListWallets(ctx, userUuid)
+	if err != nil {
+		return payment{}, err
+	}
+
+	candidates := make([]Wallet, 0, len(wallets))
+	for _, wallet := range wallets {
+		switch {
+		case currency != "":
+			if wallet.Currency == currency {
+				candidates = append(candidates, wallet)
+			}
+		case wallet.Currency == util.BaseCurrency:
+			candidates = append([]Wallet{wallet}, candidates...)
+		default:
+			candidates = append(candidates, wallet)
+		}
+	}
+
+	for _, wallet := range candidates {
+		rate, err := util.CrossRate(ctx, rates, util.BaseCurrency, wallet.Currency)
+		if err != nil {
+			return payment{}, err
+		}
+		pay := payment{
+			Wallet:     wallet,
+			UnitPrices: make([]util.Money, len(prices)),
+		}
+		for i, price := range prices {
+			pay.UnitPrices[i] = price.MulRat(rate)
+			pay.Total += pay.UnitPrices[i] * util.Money(quantities[i])
+		}
+		if wallet.Balance >= pay.Total {
+			return pay, nil
+		}
+	}
+	return payment{}, ErrNotEnoughMoney
+}
@@ -2,14 +2,20 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
 func TestBuyTx(t *testing.T) {
 
-	store := NewStore(testDB)
+	store := NewStore(testDB, NewRateProvider(testQueries))
 
 	user := createRandomUserWithBalance(t, 1000)
 	product := createRandomProductWithPriceAndInStock(t, 100, 6)
@@ -27,7 +33,7 @@ func TestBuyTx(t *testing.T) {
 	results := make(chan BuyProductTxResult)
 
 	var (
-		finalBalance  float32
+		finalBalance  util.Money
 		finalInStock  int32
 		toBuyPcs      int32
 		totalToBuyPcs int32
@@ -58,37 +64,41 @@ func TestBuyTx(t *testing.T) {
 		result := <-results
 		require.NotEmpty(t, result)
 
-		// check User
-		userDB := result.User
-		require.NotEmpty(t, userDB)
-		require.Equal(t, user.Uuid, userDB.Uuid)
-		require.Equal(t, user.FirstName, userDB.FirstName)
-		require.Equal(t, user.MiddleName, userDB.MiddleName)
-		require.Equal(t, user.LastName, userDB.LastName)
-		require.Equal(t, user.Gender, userDB.Gender)
-		require.Equal(t, user.Age, userDB.Age)
+		// check Wallet
+		walletDB := result.Wallet
+		require.NotEmpty(t, walletDB)
+		require.Equal(t, user.Uuid, walletDB.UserUuid)
+		require.Equal(t, util.BaseCurrency, walletDB.Currency)
 
-		require.NotZero(t, userDB.Balance)
+		require.NotZero(t, walletDB.Balance)
 
 		// check Order
 		orderDB := result.Order
 		require.NotEmpty(t, orderDB)
 
+		orderProducts, err := store.ListOrderProductsByOrder(context.Background(), orderDB.Uuid)
+		require.NoError(t, err)
+		require.Len(t, orderProducts, 1)
+		require.Equal(t, product.Uuid, orderProducts[0].ProductUuid)
+		require.Equal(t, toBuyPcs, orderProducts[0].Quantity)
+		require.Equal(t, product.Price, orderProducts[0].UnitPrice)
+
 		// check Product
 		productDB := result.Product
 		require.NotEmpty(t, productDB)
 		finalInStock = result.Product.InStock
-		finalBalance = result.User.Balance
+		finalBalance = result.Wallet.Balance
 
 	}
 	require.Equal(t, product.InStock-totalToBuyPcs, finalInStock)
-	require.Equal(t, user.Balance-float32(totalToBuyPcs)*product.Price, finalBalance)
+	require.Equal(t, util.Money(1000)-util.Money(totalToBuyPcs)*product.Price, finalBalance)
+	requireReconciled(t, user)
 
 }
 
 func TestBuyNotEnoughInStockTx(t *testing.T) {
 
-	store := NewStore(testDB)
+	store := NewStore(testDB, NewRateProvider(testQueries))
 
 	user := createRandomUserWithBalance(t, 1000)
 	product := createRandomProductWithPriceAndInStock(t, 100, 1)
@@ -121,9 +131,259 @@ func TestBuyNotEnoughInStockTx(t *testing.T) {
 
 }
 
+func TestBuyInvalidQuantityTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	user := createRandomUserWithBalance(t, 1000)
+	product := createRandomProductWithPriceAndInStock(t, 100, 10)
+
+	for _, quantity := range []int32{0, -1} {
+		_, err := store.BuyProductTx(context.Background(), BuyProductTxParams{
+			UserUuid:    user.Uuid,
+			ProductUuid: product.Uuid,
+			Quantity:    quantity,
+		})
+		require.ErrorIs(t, err, ErrInvalidAmount)
+	}
+
+	product2, err := testQueries.GetProduct(context.Background(), product.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, product.InStock, product2.InStock)
+}
+
+func TestReserveTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	user1 := createRandomUserWithBalance(t, 1000)
+	user2 := createRandomUserWithBalance(t, 1000)
+	product := createRandomProductWithPriceAndInStock(t, 100, 5)
+
+	reservation, err := store.ReserveTx(context.Background(), ReserveTxParams{
+		UserUuid:    user1.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    3,
+		Duration:    time.Minute,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(3), reservation.Quantity)
+	require.WithinDuration(t, time.Now().Add(time.Minute), reservation.ExpiresAt, time.Second)
+
+	// only 2 pcs are left for the other users
+	_, err = store.ReserveTx(context.Background(), ReserveTxParams{
+		UserUuid:    user2.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    3,
+		Duration:    time.Minute,
+	})
+	require.ErrorIs(t, err, ErrNotEnoughStock)
+	_, err = store.BuyProductTx(context.Background(), BuyProductTxParams{
+		UserUuid:    user2.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    3,
+	})
+	require.ErrorIs(t, err, ErrNotEnoughStock)
+
+	// the user who holds the pcs can buy them and the reservation is used up
+	_, err = store.BuyProductTx(context.Background(), BuyProductTxParams{
+		UserUuid:    user1.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    3,
+	})
+	require.NoError(t, err)
+	_, err = store.GetReservation(context.Background(), reservation.Uuid)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.BuyProductTx(context.Background(), BuyProductTxParams{
+		UserUuid:    user2.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    2,
+	})
+	require.NoError(t, err)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	session := createRandomSession(t)
+	user, err := store.GetUserByUserName(context.Background(), session.Username)
+	require.NoError(t, err)
+	token := createRandomPasswordResetToken(t, &user, time.Now().Add(time.Hour))
+	otherToken := createRandomPasswordResetToken(t, &user, time.Now().Add(time.Hour))
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+	arg := ResetPasswordTxParams{
+		TokenHash:      token.TokenHash,
+		HashedPassword: hashedPassword,
+	}
+	updated, err := store.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, user.Uuid, updated.Uuid)
+	require.Equal(t, hashedPassword, updated.HashedPassword)
+	require.Equal(t, user.Version+1, updated.Version)
+
+	// the old sessions can't renew the access tokens anymore
+	blocked, err := store.GetSession(context.Background(), session.Uuid)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+	// and neither can the access tokens issued before the reset
+	require.True(t, tokenRevoked(t, uuid.New(), user.Username, time.Now().Add(-time.Second)))
+
+	// neither the used token nor the other tokens of the user work again
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInvalidResetToken)
+	arg.TokenHash = otherToken.TokenHash
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInvalidResetToken)
+}
+
+func TestChangePasswordTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	session := createRandomSession(t)
+	user, err := store.GetUserByUserName(context.Background(), session.Username)
+	require.NoError(t, err)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+	updated, err := store.ChangePasswordTx(context.Background(), ChangePasswordTxParams{
+		Uuid:           user.Uuid,
+		HashedPassword: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Uuid, updated.Uuid)
+	require.Equal(t, hashedPassword, updated.HashedPassword)
+	require.Equal(t, user.Version+1, updated.Version)
+
+	// the old sessions and access tokens stop working, the ones issued after the change still work
+	blocked, err := store.GetSession(context.Background(), session.Uuid)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+	require.True(t, tokenRevoked(t, uuid.New(), user.Username, time.Now().Add(-time.Second)))
+	require.False(t, tokenRevoked(t, uuid.New(), user.Username, time.Now()))
+
+	_, err = store.ChangePasswordTx(context.Background(), ChangePasswordTxParams{
+		Uuid:           -1,
+		HashedPassword: hashedPassword,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestReserveLoginAttemptTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	usernameKey := "username:" + util.RandomString(8)
+	ipKey := "ip:" + util.RandomString(8)
+	arg := ReserveLoginAttemptTxParams{
+		Limits: []LoginThrottleLimit{
+			{Key: usernameKey, MaxFailures: 3},
+			{Key: ipKey, MaxFailures: 10},
+		},
+		ResetBefore: time.Now().Add(-time.Hour),
+		Lockout:     time.Minute,
+		MaxLockout:  time.Hour,
+	}
+	for i := 1; i <= 3; i++ {
+		result, err := store.ReserveLoginAttemptTx(context.Background(), arg)
+		require.NoError(t, err)
+		require.Len(t, result.Attempts, 2)
+		require.Equal(t, int32(i), result.Attempts[0].Throttle.Failures)
+		require.Equal(t, int32(i), result.Attempts[1].Throttle.Failures)
+		require.Equal(t, i > 1, result.Attempts[0].PreviousFailureAt.Valid)
+		// the attempt reaching the max failures takes the lock
+		require.Equal(t, i == 3, result.Attempts[0].Throttle.LockedUntil.Valid)
+		require.False(t, result.Attempts[1].Throttle.LockedUntil.Valid)
+	}
+
+	// the username is locked out, the attempt counted for the client ip is rolled back
+	result, err := store.ReserveLoginAttemptTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrLoginLocked)
+	require.WithinDuration(t, time.Now().Add(time.Minute), result.LockedUntil, time.Second)
+
+	throttles, err := store.ListLoginThrottles(context.Background(), []string{usernameKey, ipKey})
+	require.NoError(t, err)
+	require.Len(t, throttles, 2)
+	for _, throttle := range throttles {
+		require.Equal(t, int32(3), throttle.Failures)
+	}
+}
+
+// the successful logins don't keep the failures of a client ip from being counted from one again
+func TestRefundReservedLoginAttemptTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	arg := ReserveLoginAttemptTxParams{
+		Limits:      []LoginThrottleLimit{{Key: "ip:" + util.RandomString(8), MaxFailures: 10}},
+		ResetBefore: time.Now().Add(-time.Hour),
+		Lockout:     time.Minute,
+		MaxLockout:  time.Hour,
+	}
+	failed, err := store.ReserveLoginAttemptTx(context.Background(), arg)
+	require.NoError(t, err)
+	failure := failed.Attempts[0].Throttle
+
+	succeeded, err := store.ReserveLoginAttemptTx(context.Background(), arg)
+	require.NoError(t, err)
+	attempt := succeeded.Attempts[0]
+	require.Equal(t, int32(2), attempt.Throttle.Failures)
+	require.Equal(t, failure.LastFailureAt, attempt.PreviousFailureAt)
+
+	err = store.RefundLoginAttempt(context.Background(), RefundLoginAttemptParams{
+		Key:               attempt.Throttle.Key,
+		FailureAt:         attempt.Throttle.LastFailureAt,
+		PreviousFailureAt: attempt.PreviousFailureAt,
+		LockedUntil:       attempt.Throttle.LockedUntil,
+	})
+	require.NoError(t, err)
+	throttles, err := store.ListLoginThrottles(context.Background(), []string{failure.Key})
+	require.NoError(t, err)
+	require.Len(t, throttles, 1)
+	require.Equal(t, int32(1), throttles[0].Failures)
+	require.Equal(t, failure.LastFailureAt, throttles[0].LastFailureAt)
+
+	// the last failure is before the window although the successful attempt came after it
+	arg.ResetBefore = time.Now().Add(time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	result, err := store.ReserveLoginAttemptTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), result.Attempts[0].Throttle.Failures)
+}
+
+// concurrent attempts can't get more than the max failures past the lockout
+func TestConcurrentReserveLoginAttemptTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	arg := ReserveLoginAttemptTxParams{
+		Limits:      []LoginThrottleLimit{{Key: "username:" + util.RandomString(8), MaxFailures: 3}},
+		ResetBefore: time.Now().Add(-time.Hour),
+		Lockout:     time.Minute,
+		MaxLockout:  time.Hour,
+	}
+
+	n := 10
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.ReserveLoginAttemptTx(context.Background(), arg)
+			errs <- err
+		}()
+	}
+
+	reserved := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			reserved++
+			continue
+		}
+		require.ErrorIs(t, err, ErrLoginLocked)
+	}
+	require.Equal(t, 3, reserved)
+}
+
 func TestBuyNotEnoughMoneyTx(t *testing.T) {
 
-	store := NewStore(testDB)
+	store := NewStore(testDB, NewRateProvider(testQueries))
 
 	user := createRandomUserWithBalance(t, 100)
 	product := createRandomProductWithPriceAndInStock(t, 100, 10)
@@ -155,3 +415,473 @@ func TestBuyNotEnoughMoneyTx(t *testing.T) {
 	}
 
 }
+
+func TestBuyWithOtherCurrencyTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	// the price is 100 USD cents, which are 95 EUR cents at the seeded rate
+	product := createRandomProductWithPriceAndInStock(t, 100, 10)
+	user := createRandomUser(t)
+	createWallet(t, user, util.BaseCurrency, 150)
+	createWallet(t, user, "EUR", 1000)
+
+	// the USD wallet doesn't have enough money, so the EUR wallet is chosen
+	result, err := store.BuyProductTx(context.Background(), BuyProductTxParams{
+		UserUuid:    user.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "EUR", result.Wallet.Currency)
+	require.Equal(t, util.Money(1000-2*95), result.Wallet.Balance)
+
+	orderProduct, err := store.GetOrderProduct(context.Background(), GetOrderProductParams{
+		OrderUuid:   result.Order.Uuid,
+		ProductUuid: product.Uuid,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.Money(95), orderProduct.UnitPrice)
+	require.Equal(t, "EUR", orderProduct.Currency)
+
+	// the wallet can also be chosen explicitly
+	result, err = store.BuyProductTx(context.Background(), BuyProductTxParams{
+		UserUuid:    user.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    1,
+		Currency:    util.BaseCurrency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.BaseCurrency, result.Wallet.Currency)
+	require.Equal(t, util.Money(50), result.Wallet.Balance)
+
+	_, err = store.BuyProductTx(context.Background(), BuyProductTxParams{
+		UserUuid:    user.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    1,
+		Currency:    "RUB",
+	})
+	require.ErrorIs(t, err, ErrNotEnoughMoney)
+
+	_, err = store.BuyProductTx(context.Background(), BuyProductTxParams{
+		UserUuid:    user.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    1,
+		Currency:    "???",
+	})
+	require.ErrorIs(t, err, util.ErrUnsupportedCurrency)
+}
+
+func TestBuyWithInjectedRatesTx(t *testing.T) {
+	// the rates of the store are used instead of the seeded ones, so the price charged is the price shown
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`{"USD": "1", "EUR": "0.5"}`), 0600)
+	require.NoError(t, err)
+	rates, err := util.NewFileRateProvider(path)
+	require.NoError(t, err)
+	store := NewStore(testDB, rates)
+
+	product := createRandomProductWithPriceAndInStock(t, 100, 10)
+	user := createRandomUser(t)
+	createWallet(t, user, "EUR", 1000)
+
+	result, err := store.BuyProductTx(context.Background(), BuyProductTxParams{
+		UserUuid:    user.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    2,
+		Currency:    "EUR",
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.Money(1000-2*50), result.Wallet.Balance)
+}
+
+func TestCheckoutTx(t *testing.T) {
+
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	product1 := createRandomProductWithPriceAndInStock(t, 100, 10)
+	product2 := createRandomProductWithPriceAndInStock(t, 50, 10)
+
+	// Every user puts the same products in the cart, but in a different order.
+	// Since the checkout locks the products sorted by their uuid, none of them can deadlock
+	n := 5
+	users := make([]*User, n)
+	for i := 0; i < n; i++ {
+		users[i] = createRandomUserWithBalance(t, 1000)
+		cart, err := store.GetOrCreateCart(context.Background(), users[i].Uuid)
+		require.NoError(t, err)
+
+		products := []*Product{product1, product2}
+		if i%2 == 1 {
+			products = []*Product{product2, product1}
+		}
+		for _, product := range products {
+			_, err = store.AddCartItem(context.Background(), AddCartItemParams{
+				CartUuid:    cart.Uuid,
+				ProductUuid: product.Uuid,
+				Quantity:    1,
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	errs := make(chan error)
+	results := make(chan CheckoutTxResult)
+
+	for i := 0; i < n; i++ {
+		user := users[i]
+		go func() {
+			result, err := store.CheckoutTx(context.Background(), CheckoutTxParams{
+				UserUuid: user.Uuid,
+			})
+
+			errs <- err
+			results <- result
+		}()
+	}
+
+	total := product1.Price + product2.Price
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+
+		result := <-results
+		require.NotEmpty(t, result)
+
+		require.Equal(t, int64(2), result.Order.Quantity)
+		require.Len(t, result.Products, 2)
+		require.Len(t, result.OrderProducts, 2)
+		for _, orderProduct := range result.OrderProducts {
+			require.Equal(t, result.Order.Uuid, orderProduct.OrderUuid)
+			require.Equal(t, int32(1), orderProduct.Quantity)
+		}
+		require.Equal(t, util.Money(1000)-total, result.Wallet.Balance)
+		require.Equal(t, util.EntryPurchase, result.Entry.Kind)
+		require.Equal(t, result.Order.Uuid, result.Entry.OrderUuid.Int64)
+		require.Equal(t, -total, result.Entry.Amount)
+
+		// the cart must be empty after the checkout
+		cart, err := store.GetCartByUserUuid(context.Background(), result.Wallet.UserUuid)
+		require.NoError(t, err)
+		items, err := store.ListCartItems(context.Background(), cart.Uuid)
+		require.NoError(t, err)
+		require.Empty(t, items)
+	}
+
+	updatedProduct1, err := store.GetProduct(context.Background(), product1.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, product1.InStock-int32(n), updatedProduct1.InStock)
+
+	updatedProduct2, err := store.GetProduct(context.Background(), product2.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, product2.InStock-int32(n), updatedProduct2.InStock)
+}
+
+func TestConcurrentCheckoutTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	product := createRandomProductWithPriceAndInStock(t, 100, 10)
+	user := createRandomUserWithBalance(t, 1000)
+	cart, err := store.GetOrCreateCart(context.Background(), user.Uuid)
+	require.NoError(t, err)
+	_, err = store.AddCartItem(context.Background(), AddCartItemParams{
+		CartUuid:    cart.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    2,
+	})
+	require.NoError(t, err)
+
+	// the same cart is checked out n times at once, only the first checkout buys the items
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.CheckoutTx(context.Background(), CheckoutTxParams{
+				UserUuid: user.Uuid,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrEmptyCart)
+	}
+	require.Equal(t, 1, succeeded)
+
+	wallet, err := store.GetWallet(context.Background(), GetWalletParams{
+		UserUuid: user.Uuid,
+		Currency: util.BaseCurrency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.Money(1000)-2*product.Price, wallet.Balance)
+
+	updatedProduct, err := store.GetProduct(context.Background(), product.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, product.InStock-2, updatedProduct.InStock)
+}
+
+func TestCheckoutEmptyCartTx(t *testing.T) {
+
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	user := createRandomUserWithBalance(t, 1000)
+
+	_, err := store.CheckoutTx(context.Background(), CheckoutTxParams{
+		UserUuid: user.Uuid,
+	})
+	require.ErrorIs(t, err, ErrEmptyCart)
+}
+
+func TestCancelOrderTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	product := createRandomProductWithPriceAndInStock(t, 100, 10)
+	user := createRandomUserWithBalance(t, 1000)
+
+	bought, err := store.BuyProductTx(context.Background(), BuyProductTxParams{
+		UserUuid:    user.Uuid,
+		ProductUuid: product.Uuid,
+		Quantity:    2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.OrderPaid, bought.Order.Status)
+
+	// the order can only be cancelled once, the other concurrent cancellations must fail
+	n := 3
+	errs := make(chan error)
+	results := make(chan CancelOrderTxResult)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := store.CancelOrderTx(context.Background(), CancelOrderTxParams{
+				OrderUuid: bought.Order.Uuid,
+				Status:    util.OrderCancelled,
+			})
+
+			errs <- err
+			results <- result
+		}()
+	}
+
+	cancelled := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		result := <-results
+		if err != nil {
+			require.ErrorIs(t, err, ErrInvalidOrderTransition)
+			continue
+		}
+		cancelled++
+		require.Equal(t, util.OrderCancelled, result.Order.Status)
+		require.Len(t, result.Products, 1)
+		require.Len(t, result.Wallets, 1)
+		require.Equal(t, util.Money(1000), result.Wallets[0].Balance)
+		require.Len(t, result.Entries, 1)
+		require.Equal(t, util.EntryRefund, result.Entries[0].Kind)
+		require.Equal(t, bought.Order.Uuid, result.Entries[0].OrderUuid.Int64)
+		require.Equal(t, -bought.Entry.Amount, result.Entries[0].Amount)
+	}
+	require.Equal(t, 1, cancelled)
+
+	updatedProduct, err := store.GetProduct(context.Background(), product.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, product.InStock, updatedProduct.InStock)
+
+	wallet, err := store.GetWallet(context.Background(), GetWalletParams{
+		UserUuid: user.Uuid,
+		Currency: util.BaseCurrency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.Money(1000), wallet.Balance)
+	requireReconciled(t, user)
+
+	_, err = store.CancelOrderTx(context.Background(), CancelOrderTxParams{
+		OrderUuid: bought.Order.Uuid,
+		Status:    util.OrderRefunded,
+	})
+	require.ErrorIs(t, err, ErrInvalidOrderTransition)
+}
+
+// requireReconciled checks that the balance of every wallet of the user matches the sum of its entries
+func requireReconciled(t *testing.T, user *User) {
+	rows, err := testQueries.ReconcileWallets(context.Background(), user.Uuid)
+	require.NoError(t, err)
+	for _, row := range rows {
+		require.Equal(t, int64(row.Balance), row.LedgerBalance, "wallet %v of user %v", row.Currency, row.UserUuid)
+	}
+}
+
+func TestTopUpTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	user := createRandomUser(t)
+
+	// the wallet is created by the first top up
+	result, err := store.TopUpTx(context.Background(), TopUpTxParams{
+		UserUuid: user.Uuid,
+		Currency: "EUR",
+		Amount:   500,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.Money(500), result.Wallet.Balance)
+	require.Equal(t, util.EntryTopUp, result.Entry.Kind)
+	require.Equal(t, util.Money(500), result.Entry.Amount)
+
+	result, err = store.TopUpTx(context.Background(), TopUpTxParams{
+		UserUuid: user.Uuid,
+		Currency: "EUR",
+		Amount:   250,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.Money(750), result.Wallet.Balance)
+	requireReconciled(t, user)
+
+	_, err = store.TopUpTx(context.Background(), TopUpTxParams{
+		UserUuid: user.Uuid,
+		Currency: "EUR",
+		Amount:   0,
+	})
+	require.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = store.TopUpTx(context.Background(), TopUpTxParams{
+		UserUuid: user.Uuid,
+		Currency: "???",
+		Amount:   100,
+	})
+	require.ErrorIs(t, err, util.ErrUnsupportedCurrency)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	user := createRandomUserWithBalance(t, 1000)
+
+	result, err := store.WithdrawTx(context.Background(), WithdrawTxParams{
+		UserUuid: user.Uuid,
+		Currency: util.BaseCurrency,
+		Amount:   400,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.Money(600), result.Wallet.Balance)
+	require.Equal(t, util.EntryWithdrawal, result.Entry.Kind)
+	require.Equal(t, util.Money(-400), result.Entry.Amount)
+
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		UserUuid: user.Uuid,
+		Currency: util.BaseCurrency,
+		Amount:   601,
+	})
+	require.ErrorIs(t, err, ErrNotEnoughMoney)
+
+	// the user has no wallet in this currency
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		UserUuid: user.Uuid,
+		Currency: "EUR",
+		Amount:   1,
+	})
+	require.ErrorIs(t, err, ErrNotEnoughMoney)
+	requireReconciled(t, user)
+}
+
+func TestTransferTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	user1 := createRandomUserWithBalance(t, 1000)
+	user2 := createRandomUser(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromUserUuid: user1.Uuid,
+		ToUserUuid:   user2.Uuid,
+		Currency:     util.BaseCurrency,
+		Amount:       300,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.Money(700), result.FromWallet.Balance)
+	require.Equal(t, util.Money(300), result.ToWallet.Balance)
+	require.Equal(t, util.EntryTransfer, result.FromEntry.Kind)
+	require.Equal(t, util.Money(-300), result.FromEntry.Amount)
+	require.Equal(t, util.EntryTransfer, result.ToEntry.Kind)
+	require.Equal(t, util.Money(300), result.ToEntry.Amount)
+	require.Equal(t, user1.Uuid, result.Transfer.FromUserUuid)
+	require.Equal(t, user2.Uuid, result.Transfer.ToUserUuid)
+	require.Equal(t, util.Money(300), result.Transfer.Amount)
+	require.Equal(t, result.Transfer.Uuid, result.FromEntry.TransferUuid.Int64)
+	require.Equal(t, result.Transfer.Uuid, result.ToEntry.TransferUuid.Int64)
+	requireReconciled(t, user1)
+	requireReconciled(t, user2)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromUserUuid: user1.Uuid,
+		ToUserUuid:   user2.Uuid,
+		Currency:     util.BaseCurrency,
+		Amount:       701,
+	})
+	require.ErrorIs(t, err, ErrNotEnoughMoney)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromUserUuid: user1.Uuid,
+		ToUserUuid:   user1.Uuid,
+		Currency:     util.BaseCurrency,
+		Amount:       1,
+	})
+	require.ErrorIs(t, err, ErrTransferToSelf)
+}
+
+func TestTransferOpposingTx(t *testing.T) {
+	store := NewStore(testDB, NewRateProvider(testQueries))
+
+	user1 := createRandomUserWithBalance(t, 1000)
+	user2 := createRandomUserWithBalance(t, 1000)
+
+	// Half of the transfers go from user1 to user2 and the other half the other way round.
+	// Since both users are always locked in the order of their uuid, none of them can deadlock
+	n := 20
+	amount := util.Money(10)
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		fromUser, toUser := user1, user2
+		if i%2 == 1 {
+			fromUser, toUser = user2, user1
+		}
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromUserUuid: fromUser.Uuid,
+				ToUserUuid:   toUser.Uuid,
+				Currency:     util.BaseCurrency,
+				Amount:       amount,
+			})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+	}
+
+	// every user sent as much as they received
+	for _, user := range []*User{user1, user2} {
+		wallet, err := store.GetWallet(context.Background(), GetWalletParams{
+			UserUuid: user.Uuid,
+			Currency: util.BaseCurrency,
+		})
+		require.NoError(t, err)
+		require.Equal(t, util.Money(1000), wallet.Balance)
+		requireReconciled(t, user)
+
+		entries, err := store.ListEntries(context.Background(), ListEntriesParams{
+			UserUuid: user.Uuid,
+			Limit:    int32(n + 1),
+			Offset:   0,
+		})
+		require.NoError(t, err)
+		// the opening entry and one entry for every transfer
+		require.Len(t, entries, n+1)
+	}
+}
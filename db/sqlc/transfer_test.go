@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTransfer(t *testing.T, fromUser, toUser *User) Transfer {
+	arg := CreateTransferParams{
+		FromUserUuid: fromUser.Uuid,
+		ToUserUuid:   toUser.Uuid,
+		Currency:     util.BaseCurrency,
+		Amount:       util.RandomMoney(1, 1000),
+	}
+	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, transfer.Uuid)
+	require.Equal(t, arg.FromUserUuid, transfer.FromUserUuid)
+	require.Equal(t, arg.ToUserUuid, transfer.ToUserUuid)
+	require.Equal(t, arg.Currency, transfer.Currency)
+	require.Equal(t, arg.Amount, transfer.Amount)
+	require.NotZero(t, transfer.CreatedAt)
+	return transfer
+}
+
+func TestCreateTransfer(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomTransfer(t, user, createRandomUser(t))
+
+	// a user can't transfer money to themselves
+	_, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+		FromUserUuid: user.Uuid,
+		ToUserUuid:   user.Uuid,
+		Currency:     util.BaseCurrency,
+		Amount:       100,
+	})
+	require.ErrorContains(t, err, "Transfer_Users_check")
+}
+
+func TestGetTransfer(t *testing.T) {
+	transfer1 := createRandomTransfer(t, createRandomUser(t), createRandomUser(t))
+
+	transfer2, err := testQueries.GetTransfer(context.Background(), transfer1.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, transfer1, transfer2)
+}
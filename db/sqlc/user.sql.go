@@ -7,8 +7,20 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
+const countUsers = `-- name: CountUsers :one
+SELECT count(*) FROM "User"
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO "User" (
 	"FirstName", 
@@ -16,24 +28,22 @@ INSERT INTO "User" (
 	"LastName", 
 	"Gender", 
 	"Age",
-  "Balance",
   "Username",
   "HashedPassword") 
 VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7
 )
-RETURNING "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Balance", "Username", "HashedPassword"
+RETURNING "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Username", "HashedPassword", "Role", "Version"
 `
 
 type CreateUserParams struct {
-	FirstName      string  `json:"FirstName"`
-	MiddleName     string  `json:"MiddleName"`
-	LastName       string  `json:"LastName"`
-	Gender         string  `json:"Gender"`
-	Age            int16   `json:"Age"`
-	Balance        float32 `json:"Balance"`
-	Username       string  `json:"Username"`
-	HashedPassword string  `json:"HashedPassword"`
+	FirstName      string `json:"FirstName"`
+	MiddleName     string `json:"MiddleName"`
+	LastName       string `json:"LastName"`
+	Gender         string `json:"Gender"`
+	Age            int16  `json:"Age"`
+	Username       string `json:"Username"`
+	HashedPassword string `json:"HashedPassword"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -43,7 +53,6 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.LastName,
 		arg.Gender,
 		arg.Age,
-		arg.Balance,
 		arg.Username,
 		arg.HashedPassword,
 	)
@@ -56,20 +65,26 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.FullName,
 		&i.Gender,
 		&i.Age,
-		&i.Balance,
 		&i.Username,
 		&i.HashedPassword,
+		&i.Role,
+		&i.Version,
 	)
 	return i, err
 }
 
 const deleteUser = `-- name: DeleteUser :execrows
 DELETE FROM "User"
-WHERE "Uuid" = $1
+WHERE "Uuid" = $1 AND "Version" = COALESCE($2::integer, "Version")
 `
 
-func (q *Queries) DeleteUser(ctx context.Context, uuid int64) (int64, error) {
-	result, err := q.db.ExecContext(ctx, deleteUser, uuid)
+type DeleteUserParams struct {
+	Uuid    int64         `json:"uuid"`
+	Version sql.NullInt32 `json:"version"`
+}
+
+func (q *Queries) DeleteUser(ctx context.Context, arg DeleteUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUser, arg.Uuid, arg.Version)
 	if err != nil {
 		return 0, err
 	}
@@ -77,7 +92,7 @@ func (q *Queries) DeleteUser(ctx context.Context, uuid int64) (int64, error) {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Balance", "Username", "HashedPassword" FROM "User"
+SELECT "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Username", "HashedPassword", "Role", "Version" FROM "User"
 WHERE "Uuid" = $1 LIMIT 1
 `
 
@@ -92,15 +107,16 @@ func (q *Queries) GetUser(ctx context.Context, uuid int64) (User, error) {
 		&i.FullName,
 		&i.Gender,
 		&i.Age,
-		&i.Balance,
 		&i.Username,
 		&i.HashedPassword,
+		&i.Role,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getUserByUserName = `-- name: GetUserByUserName :one
-SELECT "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Balance", "Username", "HashedPassword" FROM "User"
+SELECT "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Username", "HashedPassword", "Role", "Version" FROM "User"
 WHERE "Username" = $1 LIMIT 1
 `
 
@@ -115,15 +131,16 @@ func (q *Queries) GetUserByUserName(ctx context.Context, username string) (User,
 		&i.FullName,
 		&i.Gender,
 		&i.Age,
-		&i.Balance,
 		&i.Username,
 		&i.HashedPassword,
+		&i.Role,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getUserForUpdate = `-- name: GetUserForUpdate :one
-SELECT "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Balance", "Username", "HashedPassword" FROM "User"
+SELECT "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Username", "HashedPassword", "Role", "Version" FROM "User"
 WHERE "Uuid" = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -140,15 +157,16 @@ func (q *Queries) GetUserForUpdate(ctx context.Context, uuid int64) (User, error
 		&i.FullName,
 		&i.Gender,
 		&i.Age,
-		&i.Balance,
 		&i.Username,
 		&i.HashedPassword,
+		&i.Role,
+		&i.Version,
 	)
 	return i, err
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Balance", "Username", "HashedPassword" FROM "User"
+SELECT "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Username", "HashedPassword", "Role", "Version" FROM "User"
 ORDER BY "Uuid" ASC
 LIMIT $1
 OFFSET $2
@@ -176,9 +194,106 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.FullName,
 			&i.Gender,
 			&i.Age,
-			&i.Balance,
 			&i.Username,
 			&i.HashedPassword,
+			&i.Role,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersAfter = `-- name: ListUsersAfter :many
+SELECT "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Username", "HashedPassword", "Role", "Version" FROM "User"
+WHERE "Uuid" > $1
+ORDER BY "Uuid" ASC
+LIMIT $2
+`
+
+type ListUsersAfterParams struct {
+	AfterUuid int64 `json:"after_uuid"`
+	Limit     int32 `json:"limit"`
+}
+
+// The page starts right after the user with the uuid, 0 starts from the first user
+func (q *Queries) ListUsersAfter(ctx context.Context, arg ListUsersAfterParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersAfter, arg.AfterUuid, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.FirstName,
+			&i.MiddleName,
+			&i.LastName,
+			&i.FullName,
+			&i.Gender,
+			&i.Age,
+			&i.Username,
+			&i.HashedPassword,
+			&i.Role,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersBefore = `-- name: ListUsersBefore :many
+SELECT "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Username", "HashedPassword", "Role", "Version" FROM "User"
+WHERE "Uuid" < $1
+ORDER BY "Uuid" DESC
+LIMIT $2
+`
+
+type ListUsersBeforeParams struct {
+	BeforeUuid int64 `json:"before_uuid"`
+	Limit      int32 `json:"limit"`
+}
+
+// The page ends right before the user with the uuid, so the users come in the reverse order
+func (q *Queries) ListUsersBefore(ctx context.Context, arg ListUsersBeforeParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersBefore, arg.BeforeUuid, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.FirstName,
+			&i.MiddleName,
+			&i.LastName,
+			&i.FullName,
+			&i.Gender,
+			&i.Age,
+			&i.Username,
+			&i.HashedPassword,
+			&i.Role,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -193,20 +308,39 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
-const reduceUserBalance = `-- name: ReduceUserBalance :one
+const patchUser = `-- name: PatchUser :one
 UPDATE "User"
-  set "Balance" = "Balance" - $1 
-WHERE "Uuid" = $2
-RETURNING "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Balance", "Username", "HashedPassword"
+  set "FirstName" = COALESCE($1::varchar, "FirstName"),
+      "MiddleName" = COALESCE($2::varchar, "MiddleName"),
+      "LastName" = COALESCE($3::varchar, "LastName"),
+      "Gender" = COALESCE($4::varchar, "Gender"),
+      "Age" = COALESCE($5::smallint, "Age"),
+      "Version" = "Version" + 1
+WHERE "Uuid" = $6 AND "Version" = COALESCE($7::integer, "Version")
+RETURNING "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Username", "HashedPassword", "Role", "Version"
 `
 
-type ReduceUserBalanceParams struct {
-	Amount float32 `json:"amount"`
-	Uuid   int64   `json:"uuid"`
+type PatchUserParams struct {
+	FirstName  sql.NullString `json:"first_name"`
+	MiddleName sql.NullString `json:"middle_name"`
+	LastName   sql.NullString `json:"last_name"`
+	Gender     sql.NullString `json:"gender"`
+	Age        sql.NullInt16  `json:"age"`
+	Uuid       int64          `json:"uuid"`
+	Version    sql.NullInt32  `json:"version"`
 }
 
-func (q *Queries) ReduceUserBalance(ctx context.Context, arg ReduceUserBalanceParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, reduceUserBalance, arg.Amount, arg.Uuid)
+// Only the fields that aren't null are changed
+func (q *Queries) PatchUser(ctx context.Context, arg PatchUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, patchUser,
+		arg.FirstName,
+		arg.MiddleName,
+		arg.LastName,
+		arg.Gender,
+		arg.Age,
+		arg.Uuid,
+		arg.Version,
+	)
 	var i User
 	err := row.Scan(
 		&i.Uuid,
@@ -216,47 +350,46 @@ func (q *Queries) ReduceUserBalance(ctx context.Context, arg ReduceUserBalancePa
 		&i.FullName,
 		&i.Gender,
 		&i.Age,
-		&i.Balance,
 		&i.Username,
 		&i.HashedPassword,
+		&i.Role,
+		&i.Version,
 	)
 	return i, err
 }
 
 const updateUser = `-- name: UpdateUser :one
 UPDATE "User"
-  set "FirstName" = $2,
-      "MiddleName" = $3,
-      "LastName" = $4,
-      "Gender" = $5,
-      "Age" = $6,
-      "Balance" = $7,
-      "HashedPassword" = $8
-WHERE "Uuid" = $1
-RETURNING "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Balance", "Username", "HashedPassword"
+  set "FirstName" = $1,
+      "MiddleName" = $2,
+      "LastName" = $3,
+      "Gender" = $4,
+      "Age" = $5,
+      "Version" = "Version" + 1
+WHERE "Uuid" = $6 AND "Version" = COALESCE($7::integer, "Version")
+RETURNING "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Username", "HashedPassword", "Role", "Version"
 `
 
 type UpdateUserParams struct {
-	Uuid           int64   `json:"Uuid"`
-	FirstName      string  `json:"FirstName"`
-	MiddleName     string  `json:"MiddleName"`
-	LastName       string  `json:"LastName"`
-	Gender         string  `json:"Gender"`
-	Age            int16   `json:"Age"`
-	Balance        float32 `json:"Balance"`
-	HashedPassword string  `json:"HashedPassword"`
+	FirstName  string        `json:"first_name"`
+	MiddleName string        `json:"middle_name"`
+	LastName   string        `json:"last_name"`
+	Gender     string        `json:"gender"`
+	Age        int16         `json:"age"`
+	Uuid       int64         `json:"uuid"`
+	Version    sql.NullInt32 `json:"version"`
 }
 
+// The user is only updated when it still has the version, a null version updates any version
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
 	row := q.db.QueryRowContext(ctx, updateUser,
-		arg.Uuid,
 		arg.FirstName,
 		arg.MiddleName,
 		arg.LastName,
 		arg.Gender,
 		arg.Age,
-		arg.Balance,
-		arg.HashedPassword,
+		arg.Uuid,
+		arg.Version,
 	)
 	var i User
 	err := row.Scan(
@@ -267,9 +400,42 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.FullName,
 		&i.Gender,
 		&i.Age,
-		&i.Balance,
 		&i.Username,
 		&i.HashedPassword,
+		&i.Role,
+		&i.Version,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE "User"
+  set "HashedPassword" = $2,
+      "Version" = "Version" + 1
+WHERE "Uuid" = $1
+RETURNING "Uuid", "FirstName", "MiddleName", "LastName", "FullName", "Gender", "Age", "Username", "HashedPassword", "Role", "Version"
+`
+
+type UpdateUserPasswordParams struct {
+	Uuid           int64  `json:"Uuid"`
+	HashedPassword string `json:"HashedPassword"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.Uuid, arg.HashedPassword)
+	var i User
+	err := row.Scan(
+		&i.Uuid,
+		&i.FirstName,
+		&i.MiddleName,
+		&i.LastName,
+		&i.FullName,
+		&i.Gender,
+		&i.Age,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Role,
+		&i.Version,
 	)
 	return i, err
 }
@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createUserToUser = `-- name: CreateUserToUser :one
@@ -17,7 +18,7 @@ VALUES (
     $1,
     $2
 )
-RETURNING "FirstUserUuid", "SecondUserUuid"
+RETURNING "FirstUserUuid", "SecondUserUuid", "CreatedAt"
 `
 
 type CreateUserToUserParams struct {
@@ -28,12 +29,31 @@ type CreateUserToUserParams struct {
 func (q *Queries) CreateUserToUser(ctx context.Context, arg CreateUserToUserParams) (UserToUser, error) {
 	row := q.db.QueryRowContext(ctx, createUserToUser, arg.FirstUserUuid, arg.SecondUserUuid)
 	var i UserToUser
-	err := row.Scan(&i.FirstUserUuid, &i.SecondUserUuid)
+	err := row.Scan(&i.FirstUserUuid, &i.SecondUserUuid, &i.CreatedAt)
 	return i, err
 }
 
+const deleteUserToUser = `-- name: DeleteUserToUser :execrows
+DELETE FROM "UserToUser"
+WHERE "FirstUserUuid" = $1
+    AND "SecondUserUuid" = $2
+`
+
+type DeleteUserToUserParams struct {
+	FirstUserUuid  int64 `json:"FirstUserUuid"`
+	SecondUserUuid int64 `json:"SecondUserUuid"`
+}
+
+func (q *Queries) DeleteUserToUser(ctx context.Context, arg DeleteUserToUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUserToUser, arg.FirstUserUuid, arg.SecondUserUuid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getUserToUser = `-- name: GetUserToUser :one
-SELECT "FirstUserUuid", "SecondUserUuid" FROM "UserToUser"
+SELECT "FirstUserUuid", "SecondUserUuid", "CreatedAt" FROM "UserToUser"
 WHERE "FirstUserUuid" = $1 
     AND "SecondUserUuid" = $2 
 LIMIT 1
@@ -47,12 +67,181 @@ type GetUserToUserParams struct {
 func (q *Queries) GetUserToUser(ctx context.Context, arg GetUserToUserParams) (UserToUser, error) {
 	row := q.db.QueryRowContext(ctx, getUserToUser, arg.FirstUserUuid, arg.SecondUserUuid)
 	var i UserToUser
-	err := row.Scan(&i.FirstUserUuid, &i.SecondUserUuid)
+	err := row.Scan(&i.FirstUserUuid, &i.SecondUserUuid, &i.CreatedAt)
 	return i, err
 }
 
+const listFollowers = `-- name: ListFollowers :many
+SELECT
+"User"."Uuid",
+"User"."Username",
+"User"."FullName",
+"UserToUser"."CreatedAt" AS "FollowedAt"
+FROM "UserToUser"
+INNER JOIN "User" ON "User"."Uuid" = "UserToUser"."FirstUserUuid"
+WHERE "UserToUser"."SecondUserUuid" = $1
+ORDER BY "User"."Uuid"
+LIMIT $2
+OFFSET $3
+`
+
+type ListFollowersParams struct {
+	UserUuid int64 `json:"user_uuid"`
+	Limit    int32 `json:"limit"`
+	Offset   int32 `json:"offset"`
+}
+
+type ListFollowersRow struct {
+	Uuid       int64     `json:"Uuid"`
+	Username   string    `json:"Username"`
+	FullName   string    `json:"FullName"`
+	FollowedAt time.Time `json:"FollowedAt"`
+}
+
+// The followers of a user are the users following them
+func (q *Queries) ListFollowers(ctx context.Context, arg ListFollowersParams) ([]ListFollowersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFollowers, arg.UserUuid, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListFollowersRow{}
+	for rows.Next() {
+		var i ListFollowersRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Username,
+			&i.FullName,
+			&i.FollowedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFollowing = `-- name: ListFollowing :many
+SELECT
+"User"."Uuid",
+"User"."Username",
+"User"."FullName",
+"UserToUser"."CreatedAt" AS "FollowedAt"
+FROM "UserToUser"
+INNER JOIN "User" ON "User"."Uuid" = "UserToUser"."SecondUserUuid"
+WHERE "UserToUser"."FirstUserUuid" = $1
+ORDER BY "User"."Uuid"
+LIMIT $2
+OFFSET $3
+`
+
+type ListFollowingParams struct {
+	UserUuid int64 `json:"user_uuid"`
+	Limit    int32 `json:"limit"`
+	Offset   int32 `json:"offset"`
+}
+
+type ListFollowingRow struct {
+	Uuid       int64     `json:"Uuid"`
+	Username   string    `json:"Username"`
+	FullName   string    `json:"FullName"`
+	FollowedAt time.Time `json:"FollowedAt"`
+}
+
+func (q *Queries) ListFollowing(ctx context.Context, arg ListFollowingParams) ([]ListFollowingRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFollowing, arg.UserUuid, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListFollowingRow{}
+	for rows.Next() {
+		var i ListFollowingRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Username,
+			&i.FullName,
+			&i.FollowedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMutualFollows = `-- name: ListMutualFollows :many
+SELECT
+"User"."Uuid",
+"User"."Username",
+"User"."FullName",
+"Following"."CreatedAt" AS "FollowedAt"
+FROM "UserToUser" AS "Following"
+INNER JOIN "UserToUser" AS "Follower" ON "Follower"."FirstUserUuid" = "Following"."SecondUserUuid"
+    AND "Follower"."SecondUserUuid" = "Following"."FirstUserUuid"
+INNER JOIN "User" ON "User"."Uuid" = "Following"."SecondUserUuid"
+WHERE "Following"."FirstUserUuid" = $1
+ORDER BY "User"."Uuid"
+LIMIT $2
+OFFSET $3
+`
+
+type ListMutualFollowsParams struct {
+	UserUuid int64 `json:"user_uuid"`
+	Limit    int32 `json:"limit"`
+	Offset   int32 `json:"offset"`
+}
+
+type ListMutualFollowsRow struct {
+	Uuid       int64     `json:"Uuid"`
+	Username   string    `json:"Username"`
+	FullName   string    `json:"FullName"`
+	FollowedAt time.Time `json:"FollowedAt"`
+}
+
+// Mutual follows are the users the user follows who follow the user back
+func (q *Queries) ListMutualFollows(ctx context.Context, arg ListMutualFollowsParams) ([]ListMutualFollowsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMutualFollows, arg.UserUuid, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListMutualFollowsRow{}
+	for rows.Next() {
+		var i ListMutualFollowsRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.Username,
+			&i.FullName,
+			&i.FollowedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUserToUser = `-- name: ListUserToUser :many
-SELECT "FirstUserUuid", "SecondUserUuid" FROM "UserToUser"
+SELECT "FirstUserUuid", "SecondUserUuid", "CreatedAt" FROM "UserToUser"
 ORDER BY "FirstUserUuid"
 LIMIT $1
 OFFSET $2
@@ -72,7 +261,7 @@ func (q *Queries) ListUserToUser(ctx context.Context, arg ListUserToUserParams)
 	items := []UserToUser{}
 	for rows.Next() {
 		var i UserToUser
-		if err := rows.Scan(&i.FirstUserUuid, &i.SecondUserUuid); err != nil {
+		if err := rows.Scan(&i.FirstUserUuid, &i.SecondUserUuid, &i.CreatedAt); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -91,7 +280,7 @@ UPDATE "UserToUser"
   set "SecondUserUuid" = $3
 WHERE "FirstUserUuid" = $1 
     AND "SecondUserUuid" = $2
-RETURNING "FirstUserUuid", "SecondUserUuid"
+RETURNING "FirstUserUuid", "SecondUserUuid", "CreatedAt"
 `
 
 type UpdateUserToUserParams struct {
@@ -103,6 +292,6 @@ type UpdateUserToUserParams struct {
 func (q *Queries) UpdateUserToUser(ctx context.Context, arg UpdateUserToUserParams) (UserToUser, error) {
 	row := q.db.QueryRowContext(ctx, updateUserToUser, arg.FirstUserUuid, arg.SecondUserUuid, arg.SecondUserUuid_2)
 	var i UserToUser
-	err := row.Scan(&i.FirstUserUuid, &i.SecondUserUuid)
+	err := row.Scan(&i.FirstUserUuid, &i.SecondUserUuid, &i.CreatedAt)
 	return i, err
 }
@@ -21,17 +21,43 @@ func createRandomUserToUser(t *testing.T) UserToUser {
 
 	require.Equal(t, arg.FirstUserUuid, userToUser.FirstUserUuid)
 	require.Equal(t, arg.SecondUserUuid, userToUser.SecondUserUuid)
+	require.NotZero(t, userToUser.CreatedAt)
 
 	return userToUser
 }
 
+func followUser(t *testing.T, follower, followee *User) {
+	_, err := testQueries.CreateUserToUser(context.Background(), CreateUserToUserParams{
+		FirstUserUuid:  follower.Uuid,
+		SecondUserUuid: followee.Uuid,
+	})
+	require.NoError(t, err)
+}
+
 func TestCreateUserToUser(t *testing.T) {
-	createRandomUserToUser(t)
+	userToUser := createRandomUserToUser(t)
+
+	// a user can follow another user only once
+	_, err := testQueries.CreateUserToUser(context.Background(), CreateUserToUserParams{
+		FirstUserUuid:  userToUser.FirstUserUuid,
+		SecondUserUuid: userToUser.SecondUserUuid,
+	})
+	require.ErrorContains(t, err, "UserToUser_pkey")
+
+	// and can't follow themselves
+	_, err = testQueries.CreateUserToUser(context.Background(), CreateUserToUserParams{
+		FirstUserUuid:  userToUser.FirstUserUuid,
+		SecondUserUuid: userToUser.FirstUserUuid,
+	})
+	require.ErrorContains(t, err, "UserToUser_Self_check")
 }
 
 func TestGetUserToUser(t *testing.T) {
 	userToUser1 := createRandomUserToUser(t)
-	arg := GetUserToUserParams(userToUser1)
+	arg := GetUserToUserParams{
+		FirstUserUuid:  userToUser1.FirstUserUuid,
+		SecondUserUuid: userToUser1.SecondUserUuid,
+	}
 	userToUser2, err := testQueries.GetUserToUser(context.Background(), arg)
 	require.NoError(t, err)
 	require.NotEmpty(t, userToUser2)
@@ -62,11 +88,14 @@ func TestUpdateUserToUser(t *testing.T) {
 func TestDeleteUserToUser(t *testing.T) {
 	userToUser1 := createRandomUserToUser(t)
 
-	_, err := testQueries.DeleteUser(context.Background(), userToUser1.FirstUserUuid)
+	_, err := testQueries.DeleteUser(context.Background(), DeleteUserParams{Uuid: userToUser1.FirstUserUuid})
 
 	require.NoError(t, err)
 
-	getUserToUserArg := GetUserToUserParams(userToUser1)
+	getUserToUserArg := GetUserToUserParams{
+		FirstUserUuid:  userToUser1.FirstUserUuid,
+		SecondUserUuid: userToUser1.SecondUserUuid,
+	}
 	userToUser2, err := testQueries.GetUserToUser(context.Background(), getUserToUserArg)
 	require.Error(t, err)
 	require.EqualError(t, err, sql.ErrNoRows.Error())
@@ -94,3 +123,63 @@ func TestListUserToUser(t *testing.T) {
 	}
 
 }
+
+func TestUnfollowUserToUser(t *testing.T) {
+	userToUser := createRandomUserToUser(t)
+	arg := DeleteUserToUserParams{
+		FirstUserUuid:  userToUser.FirstUserUuid,
+		SecondUserUuid: userToUser.SecondUserUuid,
+	}
+
+	rows, err := testQueries.DeleteUserToUser(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	rows, err = testQueries.DeleteUserToUser(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func TestListFollows(t *testing.T) {
+	user := createRandomUser(t)
+	friend := createRandomUser(t)
+	fan := createRandomUser(t)
+	idol := createRandomUser(t)
+
+	// the user and the friend follow each other, the fan follows the user and the user follows the idol
+	followUser(t, user, friend)
+	followUser(t, friend, user)
+	followUser(t, fan, user)
+	followUser(t, user, idol)
+
+	followers, err := testQueries.ListFollowers(context.Background(), ListFollowersParams{
+		UserUuid: user.Uuid,
+		Limit:    10,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, followers, 2)
+	require.Equal(t, friend.Uuid, followers[0].Uuid)
+	require.Equal(t, fan.Uuid, followers[1].Uuid)
+	require.Equal(t, fan.Username, followers[1].Username)
+	require.NotZero(t, followers[1].FollowedAt)
+
+	following, err := testQueries.ListFollowing(context.Background(), ListFollowingParams{
+		UserUuid: user.Uuid,
+		Limit:    10,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, following, 2)
+	require.Equal(t, friend.Uuid, following[0].Uuid)
+	require.Equal(t, idol.Uuid, following[1].Uuid)
+
+	mutual, err := testQueries.ListMutualFollows(context.Background(), ListMutualFollowsParams{
+		UserUuid: user.Uuid,
+		Limit:    10,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, mutual, 1)
+	require.Equal(t, friend.Uuid, mutual[0].Uuid)
+}
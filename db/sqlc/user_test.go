@@ -24,7 +24,6 @@ func createRandomUser(t *testing.T) *User {
 		LastName:       util.RandomUserLastName(),
 		Gender:         util.RandomUserGender(),
 		Age:            int16(util.RandomUserAge()),
-		Balance:        util.RandomUserBalance(),
 		Username:       util.RandomString(12),
 		HashedPassword: hashedPassword,
 	}
@@ -37,38 +36,19 @@ func createRandomUser(t *testing.T) *User {
 	require.Equal(t, arg.LastName, user.LastName)
 	require.Equal(t, arg.Gender, user.Gender)
 	require.Equal(t, arg.Age, user.Age)
-	require.Equal(t, arg.Balance, user.Balance)
 	require.Equal(t, arg.Username, user.Username)
 	require.Equal(t, arg.HashedPassword, user.HashedPassword)
+	require.Equal(t, util.CustomerRole, user.Role)
 
 	require.NotZero(t, user.Uuid)
 	return &user
 }
 
-func createRandomUserWithBalance(t *testing.T, balance float32) *User {
-	arg := CreateUserParams{
-		FirstName:  util.RandomUserFirstName(),
-		MiddleName: util.RandomUserMiddleName(),
-		LastName:   util.RandomUserLastName(),
-		Gender:     util.RandomUserGender(),
-		Age:        int16(util.RandomUserAge()),
-		Balance:    balance,
-		Username:   util.RandomString(6),
-	}
-	user, err := testQueries.CreateUser(context.Background(), arg)
-	require.NoError(t, err)
-	require.NotEmpty(t, user)
-
-	require.Equal(t, arg.FirstName, user.FirstName)
-	require.Equal(t, arg.MiddleName, user.MiddleName)
-	require.Equal(t, arg.LastName, user.LastName)
-	require.Equal(t, arg.Gender, user.Gender)
-	require.Equal(t, arg.Age, user.Age)
-	require.Equal(t, arg.Balance, user.Balance)
-	require.Equal(t, arg.Username, user.Username)
-
-	require.NotZero(t, user.Uuid)
-	return &user
+// createRandomUserWithBalance creates a user with a wallet of the base currency holding the balance
+func createRandomUserWithBalance(t *testing.T, balance util.Money) *User {
+	user := createRandomUser(t)
+	createWallet(t, user, util.BaseCurrency, balance)
+	return user
 }
 
 func TestCreateUser(t *testing.T) {
@@ -87,26 +67,18 @@ func TestGetUser(t *testing.T) {
 	require.Equal(t, user1.LastName, user2.LastName)
 	require.Equal(t, user1.Gender, user2.Gender)
 	require.Equal(t, user1.Age, user2.Age)
-	require.Equal(t, user1.Balance, user2.Balance)
 
 }
 
 func TestUpdateUser(t *testing.T) {
 	user1 := createRandomUser(t)
-	balance := util.RandomFloat(10.00, 20.00)
-	hashedPassword, err := util.HashPassword(util.RandomString(6))
-	if err != nil {
-		log.Fatal(err)
-	}
 	arg := UpdateUserParams{
-		Uuid:           user1.Uuid,
-		FirstName:      util.RandomString(12),
-		MiddleName:     util.RandomString(12),
-		LastName:       util.RandomString(12),
-		Gender:         util.RandomString(1),
-		Age:            int16(util.RandomInt(10, 20)),
-		Balance:        balance,
-		HashedPassword: hashedPassword,
+		Uuid:       user1.Uuid,
+		FirstName:  util.RandomString(12),
+		MiddleName: util.RandomString(12),
+		LastName:   util.RandomString(12),
+		Gender:     util.RandomString(1),
+		Age:        int16(util.RandomInt(10, 20)),
 	}
 	user2, err := testQueries.UpdateUser(context.Background(), arg)
 	require.NoError(t, err)
@@ -116,16 +88,47 @@ func TestUpdateUser(t *testing.T) {
 	require.Equal(t, user2.MiddleName, arg.MiddleName)
 	require.Equal(t, user2.LastName, arg.LastName)
 	require.Equal(t, user2.Gender, arg.Gender)
-	require.Equal(t, user2.Balance, balance)
 	require.Equal(t, user2.FullName, fmt.Sprintf("%s %s %s", arg.LastName, arg.FirstName, arg.MiddleName))
 	require.Equal(t, user2.Age, arg.Age)
-	require.Equal(t, user2.HashedPassword, arg.HashedPassword)
+	// the password is only changed by UpdateUserPassword
+	require.Equal(t, user1.HashedPassword, user2.HashedPassword)
+	require.Equal(t, user1.Version+1, user2.Version)
+
+	// the user was changed since the version was read
+	arg.Version = sql.NullInt32{Int32: user1.Version, Valid: true}
+	_, err = testQueries.UpdateUser(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	arg.Version = sql.NullInt32{Int32: user2.Version, Valid: true}
+	user3, err := testQueries.UpdateUser(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, user2.Version+1, user3.Version)
 
 }
 
+func TestPatchUser(t *testing.T) {
+	user1 := createRandomUser(t)
+	arg := PatchUserParams{
+		Uuid:     user1.Uuid,
+		LastName: sql.NullString{String: util.RandomString(12), Valid: true},
+		Age:      sql.NullInt16{Int16: user1.Age + 1, Valid: true},
+	}
+	user2, err := testQueries.PatchUser(context.Background(), arg)
+	require.NoError(t, err)
+
+	// only the fields of the patch are changed
+	require.Equal(t, arg.LastName.String, user2.LastName)
+	require.Equal(t, arg.Age.Int16, user2.Age)
+	require.Equal(t, user1.FirstName, user2.FirstName)
+	require.Equal(t, user1.MiddleName, user2.MiddleName)
+	require.Equal(t, user1.Gender, user2.Gender)
+	require.Equal(t, user1.HashedPassword, user2.HashedPassword)
+	require.Equal(t, user1.Version+1, user2.Version)
+}
+
 func TestDeleteUser(t *testing.T) {
 	user1 := createRandomUser(t)
-	_, err := testQueries.DeleteUser(context.Background(), user1.Uuid)
+	_, err := testQueries.DeleteUser(context.Background(), DeleteUserParams{Uuid: user1.Uuid})
 	require.NoError(t, err)
 
 	user2, err := testQueries.GetUser(context.Background(), user1.Uuid)
@@ -155,3 +158,41 @@ func TestListUsers(t *testing.T) {
 	}
 
 }
+
+func TestListUsersByCursor(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 5; i++ {
+		createRandomUser(t)
+	}
+
+	users, err := testQueries.ListUsersAfter(context.Background(), ListUsersAfterParams{
+		AfterUuid: user.Uuid,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 5)
+	for i, got := range users {
+		if i > 0 {
+			require.Greater(t, got.Uuid, users[i-1].Uuid)
+		}
+		require.Greater(t, got.Uuid, user.Uuid)
+	}
+
+	last := users[len(users)-1]
+	users, err = testQueries.ListUsersBefore(context.Background(), ListUsersBeforeParams{
+		BeforeUuid: last.Uuid,
+		Limit:      5,
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 5)
+	require.Equal(t, user.Uuid, users[len(users)-1].Uuid)
+	for i, got := range users {
+		if i > 0 {
+			require.Less(t, got.Uuid, users[i-1].Uuid)
+		}
+	}
+
+	total, err := testQueries.CountUsers(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, total, int64(6))
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alekseiapa/apple_store/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createWallet(t *testing.T, user *User, currency string, balance util.Money) Wallet {
+	arg := AddWalletBalanceParams{
+		UserUuid: user.Uuid,
+		Currency: currency,
+		Amount:   balance,
+	}
+	wallet, err := testQueries.AddWalletBalance(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.UserUuid, wallet.UserUuid)
+	require.Equal(t, arg.Currency, wallet.Currency)
+	require.Equal(t, arg.Amount, wallet.Balance)
+	require.NotZero(t, wallet.CreatedAt)
+
+	// the money of the wallet is recorded in the ledger, so the wallet can be reconciled
+	createLedgerEntry(t, user, currency, balance, util.EntryOpening)
+	return wallet
+}
+
+func TestAddWalletBalance(t *testing.T) {
+	user := createRandomUser(t)
+	wallet1 := createWallet(t, user, "EUR", 1000)
+
+	wallet2, err := testQueries.AddWalletBalance(context.Background(), AddWalletBalanceParams{
+		UserUuid: user.Uuid,
+		Currency: "EUR",
+		Amount:   -400,
+	})
+	require.NoError(t, err)
+	require.Equal(t, wallet1.Balance-400, wallet2.Balance)
+	require.Equal(t, wallet1.CreatedAt, wallet2.CreatedAt)
+
+	// the balance of a wallet can't become negative
+	_, err = testQueries.AddWalletBalance(context.Background(), AddWalletBalanceParams{
+		UserUuid: user.Uuid,
+		Currency: "EUR",
+		Amount:   -601,
+	})
+	require.ErrorContains(t, err, "Wallet_Balance_check")
+
+	// wallets can only hold the currencies with an exchange rate
+	_, err = testQueries.AddWalletBalance(context.Background(), AddWalletBalanceParams{
+		UserUuid: user.Uuid,
+		Currency: "???",
+		Amount:   100,
+	})
+	require.Error(t, err)
+}
+
+func TestGetWallet(t *testing.T) {
+	user := createRandomUser(t)
+	wallet1 := createWallet(t, user, "RUB", util.RandomUserBalance())
+
+	wallet2, err := testQueries.GetWallet(context.Background(), GetWalletParams{
+		UserUuid: user.Uuid,
+		Currency: "RUB",
+	})
+	require.NoError(t, err)
+	require.Equal(t, wallet1, wallet2)
+}
+
+func TestListWallets(t *testing.T) {
+	user := createRandomUser(t)
+	createWallet(t, user, "USD", util.RandomUserBalance())
+	createWallet(t, user, "EUR", util.RandomUserBalance())
+
+	wallets, err := testQueries.ListWallets(context.Background(), user.Uuid)
+	require.NoError(t, err)
+	require.Len(t, wallets, 2)
+	require.Equal(t, "EUR", wallets[0].Currency)
+	require.Equal(t, "USD", wallets[1].Currency)
+}
@@ -0,0 +1,35 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThumbnail(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			// the left half is black and the right half is white
+			if x >= 200 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	thumb := Thumbnail(img, 100)
+	require.Equal(t, image.Rect(0, 0, 100, 50), thumb.Bounds())
+	require.Equal(t, color.RGBA{A: 0xff}, thumb.RGBAAt(0, 0))
+	require.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, thumb.RGBAAt(99, 49))
+
+	tall := Thumbnail(image.NewRGBA(image.Rect(0, 0, 10, 1000)), 100)
+	require.Equal(t, image.Rect(0, 0, 1, 100), tall.Bounds())
+
+	// the images that already fit keep their size
+	small := Thumbnail(image.NewRGBA(image.Rect(10, 10, 60, 40)), 100)
+	require.Equal(t, image.Rect(0, 0, 50, 30), small.Bounds())
+}
@@ -3,9 +3,12 @@ package main
 import (
 	"database/sql"
 	"log"
+	"os"
 
 	"github.com/alekseiapa/apple_store/api"
 	db "github.com/alekseiapa/apple_store/db/sqlc"
+	"github.com/alekseiapa/apple_store/notify"
+	"github.com/alekseiapa/apple_store/storage"
 	"github.com/alekseiapa/apple_store/util"
 
 	// DONT remove! postgres driver for Go's database/sql package
@@ -21,8 +24,22 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
+	// the store charges with the same rates the server shows the prices with
+	rates := db.NewRateProvider(db.New(conn))
+	store := db.NewStore(conn, rates)
+	files, err := storage.NewLocalStorage(config.StorageDir, config.StorageURL)
+	if err != nil {
+		log.Fatal("cannot create storage", err)
+	}
+	// there is no mail service yet, the messages for the users are written to the log or to a file
+	notifier := notify.NewLogNotifier(os.Stderr)
+	if config.NotificationFile != "" {
+		notifier, err = notify.NewFileNotifier(config.NotificationFile)
+		if err != nil {
+			log.Fatal("cannot create notifier", err)
+		}
+	}
+	server, err := api.NewServer(config, store, rates, files, notifier)
 	if err != nil {
 		log.Fatal("cannot create server")
 	}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogNotifier writes the messages to a writer instead of delivering them.
+// It is meant for running the server locally, where the messages can be read from the log or the file
+type LogNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewLogNotifier(w io.Writer) Notifier {
+	return &LogNotifier{w: w}
+}
+
+// NewFileNotifier appends the messages to the file, the file is created when it doesn't exist
+func NewFileNotifier(path string) (Notifier, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open notification file: %w", err)
+	}
+	return NewLogNotifier(file), nil
+}
+
+func (notifier *LogNotifier) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+
+	_, err := fmt.Fprintf(notifier.w, "%s to=%s subject=%q\n%s\n\n", time.Now().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+	return err
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogNotifier(t *testing.T) {
+	var out strings.Builder
+	notifier := NewLogNotifier(&out)
+
+	err := notifier.Send(context.Background(), Message{To: "alice", Subject: "Reset your password", Body: "token"})
+	require.NoError(t, err)
+	require.Contains(t, out.String(), `to=alice subject="Reset your password"`)
+	require.Contains(t, out.String(), "\ntoken\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = notifier.Send(ctx, Message{To: "bob"})
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotContains(t, out.String(), "bob")
+}
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	notifier, err := NewFileNotifier(path)
+	require.NoError(t, err)
+
+	err = notifier.Send(context.Background(), Message{To: "alice", Subject: "first", Body: "one"})
+	require.NoError(t, err)
+
+	// the messages are appended to the existing file
+	notifier, err = NewFileNotifier(path)
+	require.NoError(t, err)
+	err = notifier.Send(context.Background(), Message{To: "bob", Subject: "second", Body: "two"})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "to=alice")
+	require.Contains(t, string(content), "to=bob")
+
+	_, err = NewFileNotifier(filepath.Join(t.TempDir(), "missing", "notifications.log"))
+	require.Error(t, err)
+}
@@ -0,0 +1,18 @@
+package notify
+
+import "context"
+
+// Message is a notification for a user, like the token to reset the password
+type Message struct {
+	// To is the username of the user the message is for
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier is the interface for delivering the messages to the users.
+// The implementations could send emails, sms or just write the messages somewhere for the local development
+type Notifier interface {
+	// Send delivers the message, the message is lost when an error is returned
+	Send(ctx context.Context, msg Message) error
+}
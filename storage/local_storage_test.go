@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := NewLocalStorage(dir, "/media/")
+	require.NoError(t, err)
+
+	key := "products/1/image.jpg"
+	err = storage.Put(context.Background(), key, strings.NewReader("first"))
+	require.NoError(t, err)
+	err = storage.Put(context.Background(), key, strings.NewReader("second"))
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, "products", "1", "image.jpg"))
+	require.NoError(t, err)
+	require.Equal(t, "second", string(content))
+	require.Equal(t, "/media/products/1/image.jpg", storage.URL(key))
+
+	err = storage.Delete(context.Background(), key)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "products", "1", "image.jpg"))
+	require.True(t, os.IsNotExist(err))
+
+	// the file is already deleted
+	err = storage.Delete(context.Background(), key)
+	require.NoError(t, err)
+}
+
+func TestLocalStorageInvalidKey(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir(), "/media")
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "/etc/passwd", "../secret", "products/../../secret", "products//image.jpg"} {
+		err = storage.Put(context.Background(), key, strings.NewReader("content"))
+		require.ErrorIs(t, err, ErrInvalidKey, key)
+
+		err = storage.Delete(context.Background(), key)
+		require.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrInvalidKey is returned for the keys that would point outside of the storage
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage is the interface for keeping the uploaded files, like the images of the products.
+// The files are put under keys like "products/1/image.jpg" and served from their urls
+type Storage interface {
+	// Put stores the content under the key, replacing the file that was there
+	Put(ctx context.Context, key string, content io.Reader) error
+
+	// Delete removes the file with the key, deleting a missing file isn't an error
+	Delete(ctx context.Context, key string) error
+
+	// URL returns the address the file with the key is served from
+	URL(key string) string
+}
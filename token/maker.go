@@ -4,9 +4,9 @@ import "time"
 
 // THis is the interface for the managing tokens
 type Maker interface {
-	// CreateToken creates a new token for a specific username and valid duration
-	CreateToken(username string, duration time.Duration) (string, error)
+	// CreateToken creates a new token for a specific username, role, type and valid duration. It also returns the payload of the token
+	CreateToken(username string, role string, tokenType TokenType, duration time.Duration) (string, *Payload, error)
 
-	// Check if the input token is valid or not. If it is valid, the method will return the payload data stored inside the body of the token.
-	VerifyToken(token string) (*Payload, error)
+	// Check if the input token is valid and of the expected type or not. If it is valid, the method will return the payload data stored inside the body of the token.
+	VerifyToken(token string, tokenType TokenType) (*Payload, error)
 }
@@ -12,17 +12,28 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// TokenType tells the short-lived access tokens apart from the refresh tokens,
+// so a refresh token can't be used to access the api and an access token can't renew itself
+type TokenType string
+
+const (
+	TokenTypeAccessToken  TokenType = "access"
+	TokenTypeRefreshToken TokenType = "refresh"
+)
+
 // This struct will contain the payload data of the token.
 type Payload struct {
 	// if we want to have a mechanism to invalidate some specific tokens in case they are leaked, o we need to add an ID field to uniquely identify each token. The type is defined in the google/uuid package
 	ID        uuid.UUID
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	TokenType TokenType `json:"token_type"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-// Creates a new token payload with a specific username and duration
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+// Creates a new token payload with a specific username, role, type and duration
+func NewPayload(username string, role string, tokenType TokenType, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -30,6 +41,8 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        tokenID,
 		Username:  username,
+		Role:      role,
+		TokenType: tokenType,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
@@ -8,11 +8,30 @@ import (
 
 // this struct will hold all the config from env file
 type Config struct {
-	DBDriver            string        `mapstructure:"DB_DRIVER"`
-	DBsource            string        `mapstructure:"DB_SOURCE"`
-	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	DBDriver                    string        `mapstructure:"DB_DRIVER"`
+	DBsource                    string        `mapstructure:"DB_SOURCE"`
+	ServerAddress               string        `mapstructure:"SERVER_ADDRESS"`
+	TokenSymmetricKey           string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration         time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration        time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	StorageDir                  string        `mapstructure:"STORAGE_DIR"`
+	StorageURL                  string        `mapstructure:"STORAGE_URL"`
+	ReservationDuration         time.Duration `mapstructure:"RESERVATION_DURATION"`
+	ReservationSweepInterval    time.Duration `mapstructure:"RESERVATION_SWEEP_INTERVAL"`
+	IdempotencyKeyDuration      time.Duration `mapstructure:"IDEMPOTENCY_KEY_DURATION"`
+	IdempotencyKeyTimeout       time.Duration `mapstructure:"IDEMPOTENCY_KEY_TIMEOUT"`
+	IdempotencyKeySweepInterval time.Duration `mapstructure:"IDEMPOTENCY_KEY_SWEEP_INTERVAL"`
+	PasswordResetTokenDuration  time.Duration `mapstructure:"PASSWORD_RESET_TOKEN_DURATION"`
+	PasswordResetMaxRequests    int32         `mapstructure:"PASSWORD_RESET_MAX_REQUESTS"`
+	PasswordResetMaxIPRequests  int32         `mapstructure:"PASSWORD_RESET_MAX_IP_REQUESTS"`
+	NotificationFile            string        `mapstructure:"NOTIFICATION_FILE"`
+	TrustedProxies              []string      `mapstructure:"TRUSTED_PROXIES"`
+	LoginMaxFailures            int32         `mapstructure:"LOGIN_MAX_FAILURES"`
+	LoginMaxIPFailures          int32         `mapstructure:"LOGIN_MAX_IP_FAILURES"`
+	LoginLockoutDuration        time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	LoginMaxLockoutDuration     time.Duration `mapstructure:"LOGIN_MAX_LOCKOUT_DURATION"`
+	LoginFailureWindow          time.Duration `mapstructure:"LOGIN_FAILURE_WINDOW"`
+	LoginThrottleSweepInterval  time.Duration `mapstructure:"LOGIN_THROTTLE_SWEEP_INTERVAL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -21,6 +40,10 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigType("env")
 
 	viper.AutomaticEnv()
+	// the proxies depend on where the server runs, so they can be set in the environment even when app.env lacks them
+	if err = viper.BindEnv("TRUSTED_PROXIES"); err != nil {
+		return
+	}
 
 	err = viper.ReadInConfig()
 	if err != nil {
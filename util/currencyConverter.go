@@ -1,24 +1,76 @@
 package util
 
-func ConvertCur(fromCur string, toCur string, amount float32) float32 {
-	var amountConverted float32
-	if fromCur == "USD" && fromCur == toCur {
-		return amount
-	}
-	if fromCur == "USD" {
-		switch toCur {
-		case "RUB":
-			amountConverted = amount * 62.5
-		case "EUR":
-			amountConverted = amount * 0.95
-		}
-	} else {
-		switch fromCur {
-		case "RUB":
-			amountConverted = amount * 0.016
-		case "EUR":
-			amountConverted = amount * 1.05
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Prices and balances are stored in the base currency, other currencies are converted on the fly
+const BaseCurrency = "USD"
+
+// ErrUnsupportedCurrency is returned when there is no exchange rate for a currency
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// RateProvider is the interface for getting the exchange rates of the supported currencies
+type RateProvider interface {
+	// Rate returns how many units of the currency are worth one unit of the base currency.
+	// It returns ErrUnsupportedCurrency if the currency has no rate
+	Rate(ctx context.Context, currency string) (*big.Rat, error)
+}
+
+// ParseRate parses an exchange rate like "0.95", which must be greater than zero.
+// Only plain decimals are accepted, not fractions like "1/3" or exponents like "1e3"
+func ParseRate(s string) (*big.Rat, error) {
+	units, fraction, hasFraction := strings.Cut(s, ".")
+	if !isDigits(units) || (hasFraction && !isDigits(fraction)) {
+		return nil, fmt.Errorf("invalid exchange rate: %q", s)
+	}
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate: %q", s)
+	}
+	return rate, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
 		}
 	}
-	return amountConverted
+	return true
+}
+
+// ConvertCur converts the amount between any two supported currencies.
+// The cross rate is calculated through the base currency, so converting back and forth is symmetric
+// and the only rounding happens once to the nearest minor unit
+func ConvertCur(ctx context.Context, rates RateProvider, fromCur string, toCur string, amount Money) (Money, error) {
+	rate, err := CrossRate(ctx, rates, fromCur, toCur)
+	if err != nil {
+		return 0, err
+	}
+	return amount.MulRat(rate), nil
+}
+
+// CrossRate returns how many units of toCur are worth one unit of fromCur.
+// Many amounts can be converted with amount.MulRat, reading the rates only once
+func CrossRate(ctx context.Context, rates RateProvider, fromCur string, toCur string) (*big.Rat, error) {
+	fromRate, err := rates.Rate(ctx, fromCur)
+	if err != nil {
+		return nil, err
+	}
+	if fromCur == toCur {
+		return big.NewRat(1, 1), nil
+	}
+	toRate, err := rates.Rate(ctx, toCur)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).Quo(toRate, fromRate), nil
 }
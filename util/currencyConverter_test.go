@@ -0,0 +1,68 @@
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertCur(t *testing.T) {
+	rates, err := NewFileRateProvider("testdata/rates.json")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	testCases := []struct {
+		from   string
+		to     string
+		amount Money
+		want   Money
+	}{
+		{"USD", "USD", 1099, 1099},
+		{"USD", "EUR", 1000, 950},
+		{"EUR", "USD", 950, 1000},
+		{"USD", "RUB", 1000, 62500},
+		{"RUB", "USD", 62500, 1000},
+		// cross rate: 1 EUR = 62.5 / 0.95 RUB
+		{"EUR", "RUB", 1000, 65789},
+		{"RUB", "EUR", 65789, 1000},
+	}
+	for _, tc := range testCases {
+		got, err := ConvertCur(ctx, rates, tc.from, tc.to, tc.amount)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, got, "%s -> %s", tc.from, tc.to)
+	}
+
+	_, err = ConvertCur(ctx, rates, "USD", "GBP", 1000)
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+
+	_, err = ConvertCur(ctx, rates, "GBP", "GBP", 1000)
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+}
+
+func TestParseRate(t *testing.T) {
+	for _, input := range []string{"1", "0.95", "62.5", "0.8000000000"} {
+		rate, err := ParseRate(input)
+		require.NoError(t, err, input)
+		require.Equal(t, 1, rate.Sign())
+	}
+
+	for _, input := range []string{"", "0", "0.0", "-1", "+1", "1/3", "1e3", ".5", "1.", "abc"} {
+		_, err := ParseRate(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestNewFileRateProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`{"USD": "1", "EUR": "-0.95"}`), 0600)
+	require.NoError(t, err)
+
+	_, err = NewFileRateProvider(path)
+	require.Error(t, err)
+
+	_, err = NewFileRateProvider(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
@@ -0,0 +1,11 @@
+package util
+
+// Constants for all kinds of ledger entries
+const (
+	EntryOpening    = "opening"
+	EntryTopUp      = "top_up"
+	EntryWithdrawal = "withdrawal"
+	EntryTransfer   = "transfer"
+	EntryPurchase   = "purchase"
+	EntryRefund     = "refund"
+)
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// FileRateProvider is a RateProvider reading the rates once from a JSON file like {"USD": "1", "EUR": "0.95"}.
+// It is meant for tests and local development, where the rates don't change
+type FileRateProvider struct {
+	rates map[string]*big.Rat
+}
+
+func NewFileRateProvider(path string) (RateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rates file: %w", err)
+	}
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("cannot parse rates file: %w", err)
+	}
+
+	provider := &FileRateProvider{
+		rates: make(map[string]*big.Rat, len(raw)),
+	}
+	for currency, s := range raw {
+		rate, err := ParseRate(s)
+		if err != nil {
+			return nil, fmt.Errorf("rate of %s: %w", currency, err)
+		}
+		provider.rates[currency] = rate
+	}
+	return provider, nil
+}
+
+// Rate returns the rate of the currency read from the file
+func (provider *FileRateProvider) Rate(ctx context.Context, currency string) (*big.Rat, error) {
+	rate, ok := provider.rates[currency]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	return rate, nil
+}
@@ -0,0 +1,86 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount of money in minor units (cents), so adding and multiplying amounts is exact.
+// It is stored as bigint in the db and encoded in JSON as a decimal number with up to two fraction digits
+type Money int64
+
+// minorUnits is the number of minor units in a major unit of every supported currency
+const minorUnits = 100
+
+// ParseMoney parses a decimal amount like "10", "10.5" or "-10.99".
+// Amounts with more than two fraction digits are rejected instead of being rounded
+func ParseMoney(s string) (Money, error) {
+	neg := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(s, "-")
+	units, cents, hasCents := strings.Cut(digits, ".")
+	if units == "" || (hasCents && (cents == "" || len(cents) > 2)) || strings.ContainsAny(digits, "+-") {
+		return 0, fmt.Errorf("invalid amount of money: %q", s)
+	}
+	for len(cents) < 2 {
+		cents += "0"
+	}
+	amount, err := strconv.ParseInt(units+cents, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount of money: %q", s)
+	}
+	if neg {
+		amount = -amount
+	}
+	return Money(amount), nil
+}
+
+// String returns the amount in major units with two fraction digits, e.g. "10.99"
+func (m Money) String() string {
+	sign := ""
+	amount := int64(m)
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/minorUnits, amount%minorUnits)
+}
+
+// MulRatio multiplies the amount by num/denom, rounding half away from zero
+func (m Money) MulRatio(num, denom int64) Money {
+	return m.MulRat(big.NewRat(num, denom))
+}
+
+// MulRat multiplies the amount by r, rounding half away from zero
+func (m Money) MulRat(r *big.Rat) Money {
+	return roundRat(new(big.Rat).Mul(new(big.Rat).SetInt64(int64(m)), r))
+}
+
+// roundRat rounds r to the nearest integer, rounding half away from zero
+func roundRat(r *big.Rat) Money {
+	num := new(big.Int).Abs(r.Num())
+	// (2*|num| + denom) / (2*denom) is |r| rounded half up
+	num.Mul(num, big.NewInt(2)).Add(num, r.Denom())
+	num.Quo(num, new(big.Int).Mul(r.Denom(), big.NewInt(2)))
+	if r.Sign() < 0 {
+		num.Neg(num)
+	}
+	return Money(num.Int64())
+}
+
+// MarshalJSON encodes the amount as a JSON number in major units
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or string in major units
+func (m *Money) UnmarshalJSON(data []byte) error {
+	amount, err := ParseMoney(string(bytes.Trim(data, `"`)))
+	if err != nil {
+		return err
+	}
+	*m = amount
+	return nil
+}
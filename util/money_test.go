@@ -0,0 +1,56 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		input  string
+		want   Money
+		output string
+	}{
+		{"10", 1000, "10.00"},
+		{"10.5", 1050, "10.50"},
+		{"10.99", 1099, "10.99"},
+		{"0.01", 1, "0.01"},
+		{"-3.07", -307, "-3.07"},
+	}
+	for _, tc := range testCases {
+		got, err := ParseMoney(tc.input)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, got)
+		require.Equal(t, tc.output, got.String())
+	}
+
+	for _, input := range []string{"", ".5", "10.", "10.999", "1e3", "--1", "abc"} {
+		_, err := ParseMoney(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestMoneyMulRatio(t *testing.T) {
+	require.Equal(t, Money(1044), Money(1099).MulRatio(95, 100))
+	require.Equal(t, Money(-1044), Money(-1099).MulRatio(95, 100))
+	require.Equal(t, Money(3), Money(5).MulRatio(1, 2))
+	require.Equal(t, Money(62500), Money(1000).MulRatio(625, 10))
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var got struct {
+		Price Money `json:"price"`
+	}
+	err := json.Unmarshal([]byte(`{"price": 19.99}`), &got)
+	require.NoError(t, err)
+	require.Equal(t, Money(1999), got.Price)
+
+	data, err := json.Marshal(got)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"price": 19.99}`, string(data))
+
+	err = json.Unmarshal([]byte(`{"price": 0.001}`), &got)
+	require.Error(t, err)
+}
@@ -0,0 +1,19 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransitionOrder(t *testing.T) {
+	require.True(t, CanTransitionOrder(OrderPending, OrderPaid))
+	require.True(t, CanTransitionOrder(OrderPaid, OrderCancelled))
+	require.True(t, CanTransitionOrder(OrderDelivered, OrderRefunded))
+
+	require.False(t, CanTransitionOrder(OrderShipped, OrderCancelled))
+	require.False(t, CanTransitionOrder(OrderPaid, OrderRefunded))
+	require.False(t, CanTransitionOrder(OrderCancelled, OrderPaid))
+	require.False(t, CanTransitionOrder(OrderPaid, OrderPaid))
+	require.False(t, CanTransitionOrder("unknown", OrderPaid))
+}
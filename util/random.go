@@ -17,9 +17,9 @@ func RandomInt(min, max int) int {
 	return min + rand.Intn(max-min+1) // min + 0->max-min
 }
 
-// random float between min and max
-func RandomFloat(min, max float32) float32 {
-	return min + rand.Float32()*(max-min) // min + 0->max-min
+// random amount of money between min and max minor units
+func RandomMoney(min, max int64) Money {
+	return Money(min + rand.Int63n(max-min+1))
 }
 
 // random string of n length
@@ -60,8 +60,8 @@ func RandomUserAge() int {
 	return RandomInt(1, 20)
 }
 
-func RandomUserBalance() float32 {
-	return RandomFloat(200.00, 1000.00)
+func RandomUserBalance() Money {
+	return RandomMoney(200_00, 1000_00)
 }
 
 // Product Table
@@ -69,8 +69,8 @@ func RandomProductDescription() string {
 	return RandomString(6)
 }
 
-func RandomProductPrice() float32 {
-	return RandomFloat(10.00, 20.00)
+func RandomProductPrice() Money {
+	return RandomMoney(10_00, 20_00)
 
 }
 
@@ -0,0 +1,7 @@
+package util
+
+// Constants for all supported user roles
+const (
+	CustomerRole = "customer"
+	AdminRole    = "admin"
+)
@@ -0,0 +1,17 @@
+package util
+
+// Constants for all supported visibilities of the purchases of a user in the feed of the users following them
+const (
+	VisibilityFollowers = "followers"
+	VisibilityMutual    = "mutual"
+	VisibilityPrivate   = "private"
+)
+
+// IsSupportedVisibility returns true if the visibility is supported
+func IsSupportedVisibility(visibility string) bool {
+	switch visibility {
+	case VisibilityFollowers, VisibilityMutual, VisibilityPrivate:
+		return true
+	}
+	return false
+}
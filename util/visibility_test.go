@@ -0,0 +1,16 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSupportedVisibility(t *testing.T) {
+	require.True(t, IsSupportedVisibility(VisibilityFollowers))
+	require.True(t, IsSupportedVisibility(VisibilityMutual))
+	require.True(t, IsSupportedVisibility(VisibilityPrivate))
+
+	require.False(t, IsSupportedVisibility(""))
+	require.False(t, IsSupportedVisibility("everyone"))
+}